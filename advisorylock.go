@@ -0,0 +1,46 @@
+package goldilocks
+
+import (
+	"context"
+)
+
+// WithAdvisoryLock acquires a connection, waits until it obtains the session level advisory lock key, and calls f. The
+// lock is released when f returns or panics. The connection holding the lock is not available to f or other users of
+// p until then. If the lock cannot be released the connection is closed, which releases it.
+func (p *Pool) WithAdvisoryLock(ctx context.Context, key int64, f func() error) error {
+	return p.Acquire(ctx, func(conn *Conn) error {
+		_, err := conn.Exec(ctx, "select pg_advisory_lock($1)", key)
+		if err != nil {
+			return err
+		}
+		defer conn.advisoryUnlock(key)
+
+		return f()
+	})
+}
+
+// TryAdvisoryLock is like WithAdvisoryLock but does not wait for the lock. If the lock is held by another session f
+// is not called and TryAdvisoryLock returns false. This suits leader election and jobs that must not run concurrently.
+func (p *Pool) TryAdvisoryLock(ctx context.Context, key int64, f func() error) (bool, error) {
+	var locked bool
+	err := p.Acquire(ctx, func(conn *Conn) error {
+		_, err := conn.Query(ctx, "select pg_try_advisory_lock($1)", []interface{}{key}, []interface{}{&locked}, func() error { return nil })
+		if err != nil || !locked {
+			return err
+		}
+		defer conn.advisoryUnlock(key)
+
+		return f()
+	})
+	return locked, err
+}
+
+// advisoryUnlock releases the session level advisory lock key. It does not use the context of the caller so the lock
+// is released even if that context is canceled. If the lock cannot be released the connection is closed.
+func (c *Conn) advisoryUnlock(key int64) {
+	var unlocked bool
+	_, err := c.Query(context.Background(), "select pg_advisory_unlock($1)", []interface{}{key}, []interface{}{&unlocked}, func() error { return nil })
+	if err != nil || !unlocked {
+		c.pgconn.Close(context.Background())
+	}
+}
@@ -0,0 +1,46 @@
+package goldilocks_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolWithAdvisoryLock(t *testing.T) {
+	t.Parallel()
+
+	db, err := goldilocks.NewPool(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	const key = 4589001
+
+	err = db.WithAdvisoryLock(context.Background(), key, func() error {
+		locked, err := db.TryAdvisoryLock(context.Background(), key, func() error {
+			t.Fatal("f called without the lock")
+			return nil
+		})
+		require.NoError(t, err)
+		require.False(t, locked)
+		return nil
+	})
+	require.NoError(t, err)
+
+	fErr := errors.New("f failed")
+	locked, err := db.TryAdvisoryLock(context.Background(), key, func() error { return fErr })
+	require.True(t, locked)
+	require.Equal(t, fErr, err)
+
+	// The lock is released when f panics.
+	require.Panics(t, func() {
+		db.WithAdvisoryLock(context.Background(), key, func() error { panic("boom") })
+	})
+
+	locked, err = db.TryAdvisoryLock(context.Background(), key, func() error { return nil })
+	require.NoError(t, err)
+	require.True(t, locked)
+}
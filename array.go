@@ -0,0 +1,403 @@
+package goldilocks
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgio"
+)
+
+// ArrayDimension is the length and lower bound of one dimension of an Array.
+type ArrayDimension struct {
+	Length     int32
+	LowerBound int32
+}
+
+// Array is a PostgreSQL array with any number of dimensions. Elements holds all elements in row-major order as one of
+// []int16, []int32, []int64, []float32, []float64, []bool, []string, []Date, or []time.Time. A NULL array has nil
+// Elements or a nil slice. NULL elements are not supported.
+//
+// When encoding, the product of the dimension lengths must equal the number of elements. If Dimensions is empty the
+// elements are encoded as a one-dimensional array with a lower bound of 1. When decoding, Elements is set to the slice
+// type that corresponds to the element type of the array.
+type Array struct {
+	Dimensions []ArrayDimension
+	Elements   interface{}
+}
+
+func (a Array) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	elemOID, arrayOID, n, writeElem, err := arrayElements(a.Elements)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if writeElem == nil {
+		return nil, arrayOID, binaryFormat, nil
+	}
+
+	if len(a.Dimensions) > 0 {
+		count := 1
+		for _, d := range a.Dimensions {
+			if d.Length < 0 {
+				return nil, 0, 0, fmt.Errorf("Array dimension length cannot be negative, got %d", d.Length)
+			}
+			count *= int(d.Length)
+		}
+		if count != n {
+			return nil, 0, 0, fmt.Errorf("Array dimensions require %d elements, got %d", count, n)
+		}
+	}
+
+	buf, err = writeArray(buf, elemOID, a.Dimensions, n, writeElem)
+	if err != nil {
+		return nil, arrayOID, 0, err
+	}
+	return buf, arrayOID, binaryFormat, nil
+}
+
+func (*Array) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (a *Array) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*a = Array{}
+		return nil
+	}
+
+	dims, elemOID, n, buf, err := readArrayHeader(buf, "Array")
+	if err != nil {
+		return err
+	}
+
+	// Types such as varchar and citext have the same binary format as text.
+	if textIsBinaryFormat(elemOID) {
+		elemOID = textOID
+	}
+
+	var elems interface{}
+	switch elemOID {
+	case int2OID:
+		s := make([]int16, n)
+		err = readArrayElements(buf, n, "Array", func(i int, buf []byte) error { return readNotNullInt16(buf, &s[i]) })
+		elems = s
+	case int4OID:
+		s := make([]int32, n)
+		err = readArrayElements(buf, n, "Array", func(i int, buf []byte) error { return readNotNullInt32(buf, &s[i]) })
+		elems = s
+	case int8OID:
+		s := make([]int64, n)
+		err = readArrayElements(buf, n, "Array", func(i int, buf []byte) error { return readNotNullInt64(buf, &s[i]) })
+		elems = s
+	case float4OID:
+		s := make([]float32, n)
+		err = readArrayElements(buf, n, "Array", func(i int, buf []byte) error { return readNotNullFloat32(buf, &s[i]) })
+		elems = s
+	case float8OID:
+		s := make([]float64, n)
+		err = readArrayElements(buf, n, "Array", func(i int, buf []byte) error { return readNotNullFloat64(buf, &s[i]) })
+		elems = s
+	case boolOID:
+		s := make([]bool, n)
+		err = readArrayElements(buf, n, "Array", func(i int, buf []byte) error { return readNotNullBool(buf, &s[i]) })
+		elems = s
+	case textOID:
+		s := make([]string, n)
+		err = readArrayElements(buf, n, "Array", func(i int, buf []byte) error { return readNotNullString(buf, &s[i]) })
+		elems = s
+	case dateOID:
+		s := make([]Date, n)
+		err = readArrayElements(buf, n, "Array", func(i int, buf []byte) error { return readNotNullDate(buf, (*time.Time)(&s[i])) })
+		elems = s
+	case timestamptzOID:
+		s := make([]time.Time, n)
+		err = readArrayElements(buf, n, "Array", func(i int, buf []byte) error { return readNotNullTime(buf, &s[i]) })
+		elems = s
+	default:
+		return fmt.Errorf("Array does not support element type oid %d", elemOID)
+	}
+	if err != nil {
+		return err
+	}
+
+	*a = Array{Dimensions: dims, Elements: elems}
+	return nil
+}
+
+// arrayElements returns the element type oid, the array type oid, the number of elements, and a function that appends
+// each element for elements, which must be a slice type supported by Array. writeElem is nil if elements is nil or a
+// nil slice.
+func arrayElements(elements interface{}) (elemOID, arrayOID uint32, n int, writeElem func(buf []byte, i int) ([]byte, error), err error) {
+	switch elems := elements.(type) {
+	case nil:
+		return 0, 0, 0, nil, nil
+	case []int16:
+		elemOID, arrayOID, n = int2OID, int2ArrayOID, len(elems)
+		if elems != nil {
+			writeElem = func(buf []byte, i int) ([]byte, error) { return pgio.AppendInt16(buf, elems[i]), nil }
+		}
+	case []int32:
+		elemOID, arrayOID, n = int4OID, int4ArrayOID, len(elems)
+		if elems != nil {
+			writeElem = func(buf []byte, i int) ([]byte, error) { return pgio.AppendInt32(buf, elems[i]), nil }
+		}
+	case []int64:
+		elemOID, arrayOID, n = int8OID, int8ArrayOID, len(elems)
+		if elems != nil {
+			writeElem = func(buf []byte, i int) ([]byte, error) { return pgio.AppendInt64(buf, elems[i]), nil }
+		}
+	case []float32:
+		elemOID, arrayOID, n = float4OID, float4ArrayOID, len(elems)
+		if elems != nil {
+			writeElem = func(buf []byte, i int) ([]byte, error) {
+				return pgio.AppendUint32(buf, math.Float32bits(elems[i])), nil
+			}
+		}
+	case []float64:
+		elemOID, arrayOID, n = float8OID, float8ArrayOID, len(elems)
+		if elems != nil {
+			writeElem = func(buf []byte, i int) ([]byte, error) {
+				return pgio.AppendUint64(buf, math.Float64bits(elems[i])), nil
+			}
+		}
+	case []bool:
+		elemOID, arrayOID, n = boolOID, boolArrayOID, len(elems)
+		if elems != nil {
+			writeElem = func(buf []byte, i int) ([]byte, error) {
+				buf, _, _, err := writeBool(buf, elems[i])
+				return buf, err
+			}
+		}
+	case []string:
+		elemOID, arrayOID, n = textOID, textArrayOID, len(elems)
+		if elems != nil {
+			writeElem = func(buf []byte, i int) ([]byte, error) { return append(buf, elems[i]...), nil }
+		}
+	case []Date:
+		elemOID, arrayOID, n = dateOID, dateArrayOID, len(elems)
+		if elems != nil {
+			writeElem = func(buf []byte, i int) ([]byte, error) {
+				buf, _, _, err := writeDate(buf, time.Time(elems[i]))
+				return buf, err
+			}
+		}
+	case []time.Time:
+		elemOID, arrayOID, n = timestamptzOID, timestamptzArrayOID, len(elems)
+		if elems != nil {
+			writeElem = func(buf []byte, i int) ([]byte, error) {
+				buf, _, _, err := writeTime(buf, elems[i])
+				return buf, err
+			}
+		}
+	default:
+		return 0, 0, 0, nil, fmt.Errorf("Array does not support elements of type %T", elements)
+	}
+
+	return elemOID, arrayOID, n, writeElem, nil
+}
+
+// writeSliceArray encodes src, which must be a slice type supported by Array, as a one-dimensional array. A nil slice
+// is encoded as NULL.
+func writeSliceArray(buf []byte, src interface{}) ([]byte, uint32, int16, error) {
+	elemOID, arrayOID, n, writeElem, err := arrayElements(src)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if writeElem == nil {
+		return nil, arrayOID, binaryFormat, nil
+	}
+
+	buf, err = writeArray(buf, elemOID, nil, n, writeElem)
+	if err != nil {
+		return nil, arrayOID, 0, err
+	}
+	return buf, arrayOID, binaryFormat, nil
+}
+
+// readArrayHeader reads the header of an array in the binary format. typeName is used in error messages. It returns the
+// dimensions, the element type oid, the total number of elements, and the remainder of buf which contains the elements.
+func readArrayHeader(buf []byte, typeName string) ([]ArrayDimension, uint32, int, []byte, error) {
+	// The header is the number of dimensions, a has null flag, and the element type oid. It is followed by the length and
+	// lower bound of each dimension.
+	if len(buf) < 12 {
+		return nil, 0, 0, nil, fmt.Errorf("%s requires array header length of at least 12, got %d", typeName, len(buf))
+	}
+	ndim := int(int32(binary.BigEndian.Uint32(buf)))
+	elemOID := binary.BigEndian.Uint32(buf[8:])
+	buf = buf[12:]
+
+	if ndim < 0 || len(buf) < ndim*8 {
+		return nil, 0, 0, nil, fmt.Errorf("%s array with %d dimensions requires data length of %d, got %d", typeName, ndim, ndim*8, len(buf))
+	}
+
+	var dims []ArrayDimension
+	n := 0
+	if ndim > 0 {
+		dims = make([]ArrayDimension, ndim)
+		n = 1
+	}
+	for i := range dims {
+		dims[i].Length = int32(binary.BigEndian.Uint32(buf))
+		dims[i].LowerBound = int32(binary.BigEndian.Uint32(buf[4:]))
+		buf = buf[8:]
+
+		// Each element requires at least its 4 byte length. Checking this before allocating prevents a corrupt length from
+		// causing a huge allocation.
+		if dims[i].Length < 0 || n*int(dims[i].Length) > len(buf)/4 {
+			return nil, 0, 0, nil, fmt.Errorf("%s array length exceeds data length of %d", typeName, len(buf))
+		}
+		n *= int(dims[i].Length)
+	}
+
+	return dims, elemOID, n, buf, nil
+}
+
+// readArrayElements calls readElem for each of the n elements in buf in order. readElem is never called with a NULL
+// element.
+func readArrayElements(buf []byte, n int, typeName string, readElem func(i int, buf []byte) error) error {
+	for i := 0; i < n; i++ {
+		if len(buf) < 4 {
+			return fmt.Errorf("%s array element %d is missing", typeName, i)
+		}
+		elemLen := int(int32(binary.BigEndian.Uint32(buf)))
+		buf = buf[4:]
+
+		if elemLen < 0 {
+			return fmt.Errorf("NULL array element %d cannot be converted to %s", i, typeName)
+		}
+		if len(buf) < elemLen {
+			return fmt.Errorf("%s array element %d requires data length of %d, got %d", typeName, i, elemLen, len(buf))
+		}
+
+		err := readElem(i, buf[:elemLen])
+		if err != nil {
+			return err
+		}
+		buf = buf[elemLen:]
+	}
+
+	return nil
+}
+
+// readArray decodes a one-dimensional array in the binary format. typeName is used in error messages. elemOID is the
+// required element type. alloc is called with the number of elements before readElem is called for each element in
+// order. readElem is never called with a NULL element.
+func readArray(buf []byte, typeName string, elemOID uint32, alloc func(n int), readElem func(i int, buf []byte) error) error {
+	dims, oid, n, buf, err := readArrayHeader(buf, typeName)
+	if err != nil {
+		return err
+	}
+
+	if oid != elemOID {
+		return fmt.Errorf("%s requires array element type oid %d, got %d", typeName, elemOID, oid)
+	}
+	if len(dims) > 1 {
+		return fmt.Errorf("%s requires a one-dimensional array, got %d dimensions", typeName, len(dims))
+	}
+
+	alloc(n)
+	return readArrayElements(buf, n, typeName, readElem)
+}
+
+// writeArray appends an array of n elements of type elemOID in the binary format to buf. If dims is empty the array is
+// one-dimensional with a lower bound of 1. writeElem is called to append each element in order. If writeElem returns an
+// error it is returned with the index of the element.
+func writeArray(buf []byte, elemOID uint32, dims []ArrayDimension, n int, writeElem func(buf []byte, i int) ([]byte, error)) ([]byte, error) {
+	if len(dims) == 0 && n > 0 {
+		buf = pgio.AppendInt32(buf, 1) // number of dimensions
+		buf = pgio.AppendInt32(buf, 0) // has null flag
+		buf = pgio.AppendUint32(buf, elemOID)
+		buf = pgio.AppendInt32(buf, int32(n))
+		buf = pgio.AppendInt32(buf, 1) // lower bound
+	} else {
+		buf = pgio.AppendInt32(buf, int32(len(dims)))
+		buf = pgio.AppendInt32(buf, 0)
+		buf = pgio.AppendUint32(buf, elemOID)
+		for _, d := range dims {
+			buf = pgio.AppendInt32(buf, d.Length)
+			buf = pgio.AppendInt32(buf, d.LowerBound)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		sp := len(buf)
+		buf = pgio.AppendInt32(buf, -1)
+		var err error
+		buf, err = writeElem(buf, i)
+		if err != nil {
+			return nil, fmt.Errorf("array element %d: %w", i, err)
+		}
+		pgio.SetInt32(buf[sp:], int32(len(buf[sp:])-4))
+	}
+
+	return buf, nil
+}
+
+// int32Array decodes an int4[]. A NULL is decoded as a nil slice.
+type int32Array []int32
+
+func (*int32Array) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (dst *int32Array) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*dst = nil
+		return nil
+	}
+
+	return readArray(buf, "[]int32", int4OID,
+		func(n int) { *dst = make(int32Array, n) },
+		func(i int, buf []byte) error { return readNotNullInt32(buf, &(*dst)[i]) },
+	)
+}
+
+// stringArray decodes a text[] or an array of another type registered with RegisterTextType. A NULL is decoded as a
+// nil slice.
+type stringArray []string
+
+func (*stringArray) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (dst *stringArray) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*dst = nil
+		return nil
+	}
+
+	dims, elemOID, n, buf, err := readArrayHeader(buf, "[]string")
+	if err != nil {
+		return err
+	}
+
+	if !textIsBinaryFormat(elemOID) {
+		return fmt.Errorf("[]string requires array element type oid %d, got %d", textOID, elemOID)
+	}
+	if len(dims) > 1 {
+		return fmt.Errorf("[]string requires a one-dimensional array, got %d dimensions", len(dims))
+	}
+
+	*dst = make(stringArray, n)
+	return readArrayElements(buf, n, "[]string", func(i int, buf []byte) error { return readNotNullString(buf, &(*dst)[i]) })
+}
+
+// float64Array decodes a float8[]. A NULL is decoded as a nil slice.
+type float64Array []float64
+
+func (*float64Array) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (dst *float64Array) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*dst = nil
+		return nil
+	}
+
+	return readArray(buf, "[]float64", float8OID,
+		func(n int) { *dst = make(float64Array, n) },
+		func(i int, buf []byte) error { return readNotNullFloat64(buf, &(*dst)[i]) },
+	)
+}
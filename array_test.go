@@ -0,0 +1,247 @@
+package goldilocks_test
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInt32Array(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var a, empty, null []int32
+	_, err = db.Query(
+		context.Background(),
+		"select '{1,-2,2147483647}'::int4[], '{}'::int4[], null::int4[]",
+		nil,
+		[]interface{}{&a, &empty, &null},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, []int32{1, -2, 2147483647}, a)
+	require.NotNil(t, empty)
+	require.Len(t, empty, 0)
+	require.Nil(t, null)
+
+	_, err = db.Query(context.Background(), "select '{1,null}'::int4[]", nil, []interface{}{&a}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "int4"): NULL array element 1 cannot be converted to []int32`)
+
+	_, err = db.Query(context.Background(), "select '{1,2}'::int8[]", nil, []interface{}{&a}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "int8"): []int32 requires array element type oid 23, got 20`)
+
+	_, err = db.Query(context.Background(), "select '{{1},{2}}'::int4[]", nil, []interface{}{&a}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "int4"): []int32 requires a one-dimensional array, got 2 dimensions`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestStringArray(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	in := []string{"foo", "", "NULL", `"quoted", {braced}`, `back\slash`, "日本語"}
+	var out, empty, null []string
+	var isNull bool
+	var text string
+	_, err = db.Query(
+		context.Background(),
+		"select $1::text[], $1::text[]::text, $2::text[], $3::text[], $3 is null",
+		[]interface{}{in, []string{}, []string(nil)},
+		[]interface{}{&out, &text, &empty, &null, &isNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, in, out)
+	require.Equal(t, `{foo,"","NULL","\"quoted\", {braced}","back\\slash",日本語}`, text)
+	require.NotNil(t, empty)
+	require.Len(t, empty, 0)
+	require.Nil(t, null)
+	require.True(t, isNull)
+
+	_, err = db.Query(context.Background(), "select '{a,null}'::text[]", nil, []interface{}{&out}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "text"): NULL array element 1 cannot be converted to []string`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestFloat64Array(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	in := []float64{0, -1.5, math.MaxFloat64, math.SmallestNonzeroFloat64, math.Inf(1), math.Inf(-1)}
+	var out, empty, null []float64
+	var sum float64
+	var isNull bool
+	_, err = db.Query(
+		context.Background(),
+		"select $1::float8[], (select sum(x) from unnest($2::float8[]) x), $3::float8[], $4::float8[], $4 is null",
+		[]interface{}{in, []float64{1.25, 2.5}, []float64{}, []float64(nil)},
+		[]interface{}{&out, &sum, &empty, &null, &isNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, in, out)
+	require.Equal(t, 3.75, sum)
+	require.NotNil(t, empty)
+	require.Len(t, empty, 0)
+	require.Nil(t, null)
+	require.True(t, isNull)
+
+	var nan []float64
+	_, err = db.Query(context.Background(), "select '{NaN}'::float8[]", nil, []interface{}{&nan}, func() error { return nil })
+	require.NoError(t, err)
+	require.Len(t, nan, 1)
+	require.True(t, math.IsNaN(nan[0]))
+
+	_, err = db.Query(context.Background(), "select '{1.5,null}'::float8[]", nil, []interface{}{&out}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "float8"): NULL array element 1 cannot be converted to []float64`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestArrayEncodeDecodeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for i, a := range []goldilocks.Array{
+		{Dimensions: []goldilocks.ArrayDimension{{Length: 2, LowerBound: 1}, {Length: 3, LowerBound: 1}}, Elements: []int32{1, 2, 3, 4, 5, 6}},
+		{Dimensions: []goldilocks.ArrayDimension{{Length: 1, LowerBound: 0}, {Length: 2, LowerBound: -1}, {Length: 2, LowerBound: 5}}, Elements: []string{"a", "", "c", "d"}},
+		{Dimensions: []goldilocks.ArrayDimension{{Length: 2, LowerBound: 1}}, Elements: []int16{-1, 1}},
+		{Dimensions: []goldilocks.ArrayDimension{{Length: 1, LowerBound: 1}}, Elements: []int64{math.MaxInt64}},
+		{Dimensions: []goldilocks.ArrayDimension{{Length: 2, LowerBound: 1}}, Elements: []float32{1.5, -2}},
+		{Dimensions: []goldilocks.ArrayDimension{{Length: 1, LowerBound: 1}}, Elements: []float64{math.Pi}},
+		{Dimensions: []goldilocks.ArrayDimension{{Length: 2, LowerBound: 1}}, Elements: []bool{true, false}},
+		{Elements: []int32{}},
+	} {
+		buf, _, _, err := a.EncodeParam(nil)
+		require.NoErrorf(t, err, "%d", i)
+
+		var decoded goldilocks.Array
+		err = decoded.DecodeResult(buf)
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, a, decoded, "%d", i)
+	}
+
+	buf, _, _, err := goldilocks.Array{Elements: []int32{1, 2}}.EncodeParam(nil)
+	require.NoError(t, err)
+	var decoded goldilocks.Array
+	err = decoded.DecodeResult(buf)
+	require.NoError(t, err)
+	require.Equal(t, []goldilocks.ArrayDimension{{Length: 2, LowerBound: 1}}, decoded.Dimensions)
+
+	_, _, _, err = goldilocks.Array{Dimensions: []goldilocks.ArrayDimension{{Length: 2, LowerBound: 1}}, Elements: []int32{1}}.EncodeParam(nil)
+	require.EqualError(t, err, "Array dimensions require 2 elements, got 1")
+
+	_, _, _, err = goldilocks.Array{Elements: []uint8{1}}.EncodeParam(nil)
+	require.EqualError(t, err, "Array does not support elements of type []uint8")
+}
+
+func TestArray(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	in := goldilocks.Array{
+		Dimensions: []goldilocks.ArrayDimension{{Length: 2, LowerBound: 1}, {Length: 2, LowerBound: 1}},
+		Elements:   []int64{1, 2, 3, 4},
+	}
+	var out, varchars, lowerBound, null goldilocks.Array
+	var text string
+	var isNull bool
+	_, err = db.Query(
+		context.Background(),
+		"select $1::int8[], $1::int8[]::text, '{{a,b,c}}'::varchar[], '[0:1]={1.5,2.5}'::float8[], $2::int4[], $2 is null",
+		[]interface{}{in, goldilocks.Array{}},
+		[]interface{}{&out, &text, &varchars, &lowerBound, &null, &isNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, in, out)
+	require.Equal(t, "{{1,2},{3,4}}", text)
+	require.Equal(t, goldilocks.Array{
+		Dimensions: []goldilocks.ArrayDimension{{Length: 1, LowerBound: 1}, {Length: 3, LowerBound: 1}},
+		Elements:   []string{"a", "b", "c"},
+	}, varchars)
+	require.Equal(t, goldilocks.Array{
+		Dimensions: []goldilocks.ArrayDimension{{Length: 2, LowerBound: 0}},
+		Elements:   []float64{1.5, 2.5},
+	}, lowerBound)
+	require.Nil(t, null.Elements)
+	require.True(t, isNull)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestSliceParams(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	tm := time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC)
+	date := goldilocks.Date(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	for i, tt := range []struct {
+		param    interface{}
+		sql      string
+		expected string
+	}{
+		{param: []int16{1, -2}, sql: "select $1::text", expected: "{1,-2}"},
+		{param: []int32{1, -2}, sql: "select $1::text", expected: "{1,-2}"},
+		{param: []int64{1, -2}, sql: "select $1::text", expected: "{1,-2}"},
+		{param: []float32{1.5, -2}, sql: "select $1::text", expected: "{1.5,-2}"},
+		{param: []float64{1.5, -2}, sql: "select $1::text", expected: "{1.5,-2}"},
+		{param: []bool{true, false}, sql: "select $1::text", expected: "{t,f}"},
+		{param: []string{"a", "b c"}, sql: "select $1::text", expected: `{a,"b c"}`},
+		{param: []goldilocks.Date{date}, sql: "select $1::text", expected: "{2020-01-02}"},
+		{param: []time.Time{tm}, sql: "select ($1::timestamptz[])[1] at time zone 'UTC'", expected: "2020-01-02 03:04:05.000006"},
+		{param: []int64{}, sql: "select $1::text", expected: "{}"},
+		{param: []int64(nil), sql: "select coalesce($1::text, 'null')", expected: "null"},
+	} {
+		var s string
+		_, err := db.Query(context.Background(), tt.sql, []interface{}{tt.param}, []interface{}{&s}, func() error { return nil })
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, tt.expected, s, "%d", i)
+	}
+
+	var found bool
+	_, err = db.Query(context.Background(), "select 3 = any($1)", []interface{}{[]int64{1, 2, 3}}, []interface{}{&found}, func() error { return nil })
+	require.NoError(t, err)
+	require.True(t, found)
+
+	var dates goldilocks.Array
+	_, err = db.Query(context.Background(), "select $1::date[]", []interface{}{[]goldilocks.Date{date}}, []interface{}{&dates}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, []goldilocks.Date{date}, dates.Elements)
+
+	outOfRange := goldilocks.Date(time.Date(-5000, 1, 1, 0, 0, 0, 0, time.UTC))
+	_, err = db.Exec(context.Background(), "select $1::date[]", []goldilocks.Date{date, outOfRange})
+	require.EqualError(t, err, "cannot encode args[0] ([]goldilocks.Date as type oid 1182): array element 1: date -5000-01-01 is out of range")
+	_, err = db.Exec(context.Background(), "select $1::date[]", goldilocks.Array{Elements: []goldilocks.Date{outOfRange}})
+	require.EqualError(t, err, "cannot encode args[0] (goldilocks.Array as type oid 1182): array element 0: date -5000-01-01 is out of range")
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,56 @@
+package goldilocks
+
+import (
+	"strings"
+
+	"github.com/jackc/pgconn"
+)
+
+// Backend is a server implementation that speaks the PostgreSQL protocol. Some behaviors of goldilocks are adjusted
+// for servers that differ from PostgreSQL.
+type Backend int
+
+const (
+	// BackendAuto detects the backend from the parameters the server reports when the connection is established.
+	BackendAuto Backend = iota
+	BackendPostgreSQL
+	BackendCockroachDB
+	BackendYugabyteDB
+)
+
+func (b Backend) String() string {
+	switch b {
+	case BackendAuto:
+		return "auto"
+	case BackendPostgreSQL:
+		return "PostgreSQL"
+	case BackendCockroachDB:
+		return "CockroachDB"
+	case BackendYugabyteDB:
+		return "YugabyteDB"
+	default:
+		return "unknown"
+	}
+}
+
+// Backend returns the backend c is connected to. It is ConnConfig.Backend unless that is BackendAuto. Otherwise the
+// backend is detected from the server parameters. A server that is not recognized, such as PostgreSQL itself or a
+// server that reports itself as PostgreSQL, is BackendPostgreSQL.
+func (c *Conn) Backend() Backend {
+	if c.config.Backend != BackendAuto {
+		return c.config.Backend
+	}
+	return detectBackend(c.pgconn)
+}
+
+// detectBackend detects the backend of pgConn from the parameters the server reported.
+func detectBackend(pgConn *pgconn.PgConn) Backend {
+	if pgConn.ParameterStatus("crdb_version") != "" {
+		return BackendCockroachDB
+	}
+	// YugabyteDB reports a server_version such as 11.2-YB-2.18.0.0-b0.
+	if strings.Contains(pgConn.ParameterStatus("server_version"), "-YB-") {
+		return BackendYugabyteDB
+	}
+	return BackendPostgreSQL
+}
@@ -0,0 +1,40 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnBackend(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+
+	expected := goldilocks.BackendPostgreSQL
+	if pgConn.ParameterStatus("crdb_version") != "" {
+		expected = goldilocks.BackendCockroachDB
+	}
+	require.Equal(t, expected, goldilocks.NewConn(pgConn).Backend())
+
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{Backend: goldilocks.BackendYugabyteDB})
+	require.Equal(t, goldilocks.BackendYugabyteDB, db.Backend())
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestBackendString(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "auto", goldilocks.BackendAuto.String())
+	require.Equal(t, "PostgreSQL", goldilocks.BackendPostgreSQL.String())
+	require.Equal(t, "CockroachDB", goldilocks.BackendCockroachDB.String())
+	require.Equal(t, "YugabyteDB", goldilocks.BackendYugabyteDB.String())
+	require.Equal(t, "unknown", goldilocks.Backend(100).String())
+}
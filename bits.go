@@ -0,0 +1,157 @@
+package goldilocks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgio"
+)
+
+// PostgreSQL oid for varbit. bit uses the same binary format.
+const varbitOID = 1562
+
+type NullBits struct {
+	Value Bits
+	Valid bool
+}
+
+func (n NullBits) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeBits(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullBits) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullBits) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullBits{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullBits(buf, &n.Value)
+}
+
+// Bits represents a PostgreSQL bit or varbit. Bytes holds the bits starting with the most significant bit of the first
+// byte. Len is the number of bits. Bits past Len in the last byte must be zero. It is encoded as a varbit.
+type Bits struct {
+	Bytes []byte
+	Len   int32
+}
+
+// BitsFromUint64 returns the n least significant bits of v as Bits. n must be between 0 and 64.
+func BitsFromUint64(v uint64, n int32) Bits {
+	if n < 0 || n > 64 {
+		panic("n must be between 0 and 64")
+	}
+
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, v<<(64-uint(n)))
+	return Bits{Bytes: buf[:(n+7)/8], Len: n}
+}
+
+// Uint64 returns b as a big-endian unsigned integer. It returns an error if b has more than 64 bits.
+func (b Bits) Uint64() (uint64, error) {
+	if b.Len > 64 {
+		return 0, fmt.Errorf("%d bits cannot be converted to uint64", b.Len)
+	}
+
+	var v uint64
+	for _, byt := range b.Bytes {
+		v = v<<8 | uint64(byt)
+	}
+	return v >> (uint(len(b.Bytes))*8 - uint(b.Len)), nil
+}
+
+// Bit returns bit i of b where bit 0 is the first bit.
+func (b Bits) Bit(i int32) bool {
+	if i < 0 || i >= b.Len {
+		panic("bit index out of range")
+	}
+	return b.Bytes[i/8]&(0x80>>uint(i%8)) != 0
+}
+
+// Compare compares b and other as PostgreSQL does. The bits are compared in order and if one is a prefix of the other
+// the shorter one is less. The result is 0 if b == other, -1 if b < other, and +1 if b > other.
+func (b Bits) Compare(other Bits) int {
+	n := len(b.Bytes)
+	if len(other.Bytes) < n {
+		n = len(other.Bytes)
+	}
+
+	if c := bytes.Compare(b.Bytes[:n], other.Bytes[:n]); c != 0 {
+		return c
+	}
+
+	switch {
+	case b.Len < other.Len:
+		return -1
+	case b.Len > other.Len:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Equal returns true if b and other have the same bits.
+func (b Bits) Equal(other Bits) bool {
+	return b.Compare(other) == 0
+}
+
+// String returns b as a string of 0s and 1s like the PostgreSQL text format.
+func (b Bits) String() string {
+	buf := make([]byte, b.Len)
+	for i := range buf {
+		if b.Bit(int32(i)) {
+			buf[i] = '1'
+		} else {
+			buf[i] = '0'
+		}
+	}
+	return string(buf)
+}
+
+func (nn Bits) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writeBits(buf, nn)
+}
+
+func (*Bits) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *Bits) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to Bits")
+	}
+	return readNotNullBits(buf, nn)
+}
+
+func readNotNullBits(buf []byte, dst *Bits) error {
+	if len(buf) < 4 {
+		return fmt.Errorf("bit requires data length of at least 4, got %d", len(buf))
+	}
+
+	bitLen := int32(binary.BigEndian.Uint32(buf))
+	if bitLen < 0 || len(buf)-4 != int((bitLen+7)/8) {
+		return fmt.Errorf("bit with %d bits requires data length of %d, got %d", bitLen, 4+(bitLen+7)/8, len(buf))
+	}
+
+	*dst = Bits{Bytes: append([]byte{}, buf[4:]...), Len: bitLen}
+	return nil
+}
+
+func writeBits(buf []byte, src Bits) ([]byte, uint32, int16, error) {
+	if src.Len < 0 || len(src.Bytes) != int((src.Len+7)/8) {
+		return nil, varbitOID, 0, fmt.Errorf("Bits with %d bits requires %d bytes, got %d", src.Len, (src.Len+7)/8, len(src.Bytes))
+	}
+
+	buf = pgio.AppendInt32(buf, src.Len)
+	buf = append(buf, src.Bytes...)
+	return buf, varbitOID, binaryFormat, nil
+}
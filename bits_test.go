@@ -0,0 +1,113 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitsUint64(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		v    uint64
+		n    int32
+		bits goldilocks.Bits
+		s    string
+	}{
+		{0, 0, goldilocks.Bits{Bytes: []byte{}, Len: 0}, ""},
+		{1, 1, goldilocks.Bits{Bytes: []byte{0x80}, Len: 1}, "1"},
+		{5, 3, goldilocks.Bits{Bytes: []byte{0xa0}, Len: 3}, "101"},
+		{0x1ff, 9, goldilocks.Bits{Bytes: []byte{0xff, 0x80}, Len: 9}, "111111111"},
+		{0xdeadbeef, 32, goldilocks.Bits{Bytes: []byte{0xde, 0xad, 0xbe, 0xef}, Len: 32}, "11011110101011011011111011101111"},
+		{1 << 63, 64, goldilocks.Bits{Bytes: []byte{0x80, 0, 0, 0, 0, 0, 0, 0}, Len: 64}, "1" + strings.Repeat("0", 63)},
+	}
+
+	for i, tt := range tests {
+		bits := goldilocks.BitsFromUint64(tt.v, tt.n)
+		require.Equalf(t, tt.bits, bits, "%d", i)
+		require.Equalf(t, tt.s, bits.String(), "%d", i)
+
+		v, err := bits.Uint64()
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, tt.v, v, "%d", i)
+	}
+
+	_, err := goldilocks.Bits{Bytes: make([]byte, 9), Len: 65}.Uint64()
+	require.EqualError(t, err, "65 bits cannot be converted to uint64")
+}
+
+func TestBitsCompare(t *testing.T) {
+	t.Parallel()
+
+	one := goldilocks.BitsFromUint64(1, 1)
+	oneZero := goldilocks.BitsFromUint64(2, 2)
+	zeroOne := goldilocks.BitsFromUint64(1, 2)
+
+	require.Equal(t, 0, one.Compare(goldilocks.BitsFromUint64(1, 1)))
+	require.True(t, one.Equal(goldilocks.BitsFromUint64(1, 1)))
+	require.Equal(t, -1, one.Compare(oneZero))
+	require.Equal(t, 1, oneZero.Compare(one))
+	require.Equal(t, 1, one.Compare(zeroOne))
+	require.Equal(t, -1, zeroOne.Compare(oneZero))
+	require.False(t, one.Equal(oneZero))
+}
+
+func TestBits(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	for _, s := range []string{"", "0", "1", "101", "11111111", "111111111", "0000000000000001"} {
+		var bits goldilocks.Bits
+		_, err := db.Query(
+			context.Background(),
+			"select $1::varbit",
+			[]interface{}{s},
+			[]interface{}{&bits},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equal(t, s, bits.String())
+
+		var roundTrip string
+		var fixed goldilocks.Bits
+		_, err = db.Query(
+			context.Background(),
+			"select $1::text, $1::bit("+strconv.Itoa(len(s)+1)+")",
+			[]interface{}{bits},
+			[]interface{}{&roundTrip, &fixed},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equal(t, s, roundTrip)
+		require.EqualValues(t, len(s)+1, fixed.Len)
+	}
+
+	var null goldilocks.NullBits
+	var notNull goldilocks.NullBits
+	_, err = db.Query(
+		context.Background(),
+		"select $1::varbit, $2::varbit",
+		[]interface{}{goldilocks.NullBits{}, goldilocks.NullBits{Value: goldilocks.BitsFromUint64(5, 3), Valid: true}},
+		[]interface{}{&null, &notNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.NullBits{}, null)
+	require.Equal(t, goldilocks.NullBits{Value: goldilocks.BitsFromUint64(5, 3), Valid: true}, notNull)
+
+	_, err = db.Exec(context.Background(), "select $1", goldilocks.Bits{Bytes: []byte{0xff}, Len: 9})
+	require.EqualError(t, err, "cannot encode args[0] (goldilocks.Bits as type oid 1562): Bits with 9 bits requires 2 bytes, got 1")
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,88 @@
+package goldilocks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+)
+
+// cancelGracePeriod is how long to wait for the server to end a query after a cancel request was sent. If the query
+// has not ended by then the connection is closed.
+const cancelGracePeriod = 5 * time.Second
+
+// detachedContext is a context with the values of ctx that is never done.
+type detachedContext struct {
+	ctx context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)          { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}                { return nil }
+func (detachedContext) Err() error                           { return nil }
+func (dc detachedContext) Value(key interface{}) interface{} { return dc.ctx.Value(key) }
+
+// withCancelRequest calls f to execute a query with a context that is not done when ctx is done. Instead, a cancel
+// request is sent to the server and the query ends with an error the usual way. This keeps the connection usable,
+// whereas interrupting a query closes the connection. If the query has not ended within cancelGracePeriod of the cancel
+// request, or the cancel request could not be sent, the query is interrupted anyway.
+//
+// If ctx is done before f is called f is not called. If the query failed because ctx was done ctx.Err() is returned.
+//
+// There is no way to know whether the server received a cancel request before a query ended on its own. The cancel
+// request has been delivered when withCancelRequest returns so it cannot affect later queries.
+func (c *Conn) withCancelRequest(ctx context.Context, f func(ctx context.Context) error) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	if ctx.Done() == nil {
+		return f(ctx)
+	}
+
+	queryCtx, interrupt := context.WithCancel(detachedContext{ctx: ctx})
+	defer interrupt()
+
+	queryDone := make(chan struct{})
+	watchDone := make(chan bool)
+	go func() {
+		canceled := false
+		select {
+		case <-queryDone:
+		case <-ctx.Done():
+			canceled = true
+			cancelCtx, cancel := context.WithTimeout(context.Background(), cancelGracePeriod)
+			err := c.pgconn.CancelRequest(cancelCtx)
+			cancel()
+			if err != nil {
+				interrupt()
+				break
+			}
+
+			timer := time.NewTimer(cancelGracePeriod)
+			select {
+			case <-queryDone:
+			case <-timer.C:
+				interrupt()
+			}
+			timer.Stop()
+		}
+		watchDone <- canceled
+	}()
+
+	err = f(queryCtx)
+	close(queryDone)
+	canceled := <-watchDone
+
+	if canceled && err != nil && (queryCtx.Err() != nil || isQueryCanceled(err)) {
+		return ctx.Err()
+	}
+	return err
+}
+
+// isQueryCanceled checks if err is the server reporting that a query was canceled by a cancel request.
+func isQueryCanceled(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.QueryCanceled
+}
@@ -0,0 +1,68 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnQueryCancelRequest(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = db.Query(ctx, "select pg_sleep(10)", nil, []interface{}{nil}, func() error { return nil })
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.True(t, time.Since(start) < 5*time.Second)
+	require.False(t, pgConn.IsClosed())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = db.Exec(ctx, "select pg_sleep(10)")
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.False(t, pgConn.IsClosed())
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = db.Exec(ctx, "select 1")
+	require.Equal(t, context.Canceled, err)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestPoolQueryCancelRequestKeepsConn(t *testing.T) {
+	t.Parallel()
+
+	config, err := goldilocks.ParsePoolConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	db, err := goldilocks.NewPoolConfig(config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var pid int32
+	_, err = db.Query(context.Background(), "select pg_backend_pid()", nil, []interface{}{&pid}, func() error { return nil })
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = db.Exec(ctx, "select pg_sleep(10)")
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	var pidAfterCancel int32
+	_, err = db.Query(context.Background(), "select pg_backend_pid()", nil, []interface{}{&pidAfterCancel}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, pid, pidAfterCancel)
+}
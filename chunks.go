@@ -0,0 +1,172 @@
+package goldilocks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+)
+
+// QueryChunks executes sql like Query but has the server send at most chunkSize rows at a time. After rowFunc has been
+// called for each row of a chunk, chunkFunc is called. The next chunk is not requested until chunkFunc returns so the
+// server produces rows no faster than they are consumed. If chunkFunc returns an error the query is stopped and
+// QueryChunks returns that error. chunkFunc may be nil.
+//
+// The query is suspended between chunks. It holds the transaction it executes in open until all rows are read, so a
+// query outside of an explicit transaction holds an implicit transaction open. QueryChunks always uses the extended
+// protocol. rowFunc and chunkFunc must not use the Conn.
+func (c *Conn) QueryChunks(ctx context.Context, sql string, args []interface{}, results []interface{}, chunkSize int, rowFunc func() error, chunkFunc func() error) (int64, error) {
+	if chunkSize <= 0 {
+		return 0, c.handleError(fmt.Errorf("chunkSize must be greater than 0"))
+	}
+
+	err := c.acquire(ctx)
+	if err != nil {
+		return 0, c.handleError(err)
+	}
+	defer c.release()
+
+	return c.queryChunks(ctx, sql, args, results, uint32(chunkSize), rowFunc, chunkFunc)
+}
+
+func (c *Conn) queryChunks(ctx context.Context, sql string, args []interface{}, results []interface{}, chunkSize uint32, rowFunc func() error, chunkFunc func() error) (int64, error) {
+	start := time.Now()
+	defer c.recordStats(start)
+
+	sql, args, err := c.rewriteQuery(ctx, sql, nil, args)
+	if err != nil {
+		return 0, c.handleError(err)
+	}
+
+	ctx, endTrace := c.startTrace(ctx, TraceData{Op: TraceQuery, SQL: sql, ArgCount: len(args)})
+
+	var rowCount int64
+	err = c.withCancelRequest(ctx, func(ctx context.Context) error {
+		var err error
+		rowCount, err = c.execChunksParams(ctx, sql, args, results, chunkSize, rowFunc, chunkFunc)
+		return err
+	})
+	err = sqlError(err, sql, args)
+	endTrace(rowCount, err)
+	c.logQuery(ctx, "QueryChunks", sql, args, start, rowCount, err)
+	if err != nil {
+		return rowCount, c.handleError(err)
+	}
+
+	c.releaseOversizedParamValuesBuf()
+
+	return rowCount, nil
+}
+
+// execChunksParams prepares args and results and executes sql with execChunks.
+func (c *Conn) execChunksParams(ctx context.Context, sql string, args []interface{}, results []interface{}, chunkSize uint32, rowFunc func() error, chunkFunc func() error) (int64, error) {
+	err := c.prepareParams(args, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.prepareResults(results)
+	if err != nil {
+		return 0, err
+	}
+
+	rowCount, err := c.execChunks(ctx, sql, chunkSize, rowFunc, chunkFunc)
+	if err != nil && rowCount == 0 && c.useTextResultFallback(err) {
+		rowCount, err = c.execChunks(ctx, sql, chunkSize, rowFunc, chunkFunc)
+	}
+	return rowCount, err
+}
+
+// execChunks executes sql with the prepared params and results in the unnamed portal. Execute requests at most
+// chunkSize rows. Flush is used instead of Sync between chunks because Sync would close the portal.
+func (c *Conn) execChunks(ctx context.Context, sql string, chunkSize uint32, rowFunc func() error, chunkFunc func() error) (int64, error) {
+	buf := (&pgproto3.Parse{Query: sql, ParameterOIDs: c.paramOIDs}).Encode(nil)
+	buf = (&pgproto3.Bind{
+		ParameterFormatCodes: c.paramFormats,
+		Parameters:           c.paramValues,
+		ResultFormatCodes:    c.resultFormats,
+	}).Encode(buf)
+	buf = (&pgproto3.Execute{MaxRows: chunkSize}).Encode(buf)
+	buf = (&pgproto3.Flush{}).Encode(buf)
+
+	err := c.pgconn.SendBytes(ctx, buf)
+	if err != nil {
+		return 0, c.connError(err)
+	}
+
+	var rowCount int64
+	for {
+		msg, err := c.pgconn.ReceiveMessage(ctx)
+		if err != nil {
+			// The connection is in the middle of the query so it cannot be used again.
+			c.pgconn.Close(context.Background())
+			return rowCount, c.connError(err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.DataRow:
+			if len(msg.Values) != len(c.resultDecoders) && len(c.resultDecoders) > 0 {
+				return rowCount, c.syncChunks(ctx, fmt.Errorf("query returned %d columns but %d results were given", len(msg.Values), len(c.resultDecoders)))
+			}
+			rowCount++
+
+			for i := range c.resultDecoders {
+				err := c.resultDecoders[i].DecodeResult(msg.Values[i])
+				if err != nil {
+					// The query is not described so the column names are unknown.
+					return rowCount, c.syncChunks(ctx, &DecodeError{Index: i, Err: err})
+				}
+			}
+
+			err := rowFunc()
+			if err != nil {
+				return rowCount, c.syncChunks(ctx, err)
+			}
+		case *pgproto3.PortalSuspended:
+			if chunkFunc != nil {
+				err := chunkFunc()
+				if err != nil {
+					return rowCount, c.syncChunks(ctx, err)
+				}
+			}
+
+			buf = (&pgproto3.Execute{MaxRows: chunkSize}).Encode(buf[:0])
+			buf = (&pgproto3.Flush{}).Encode(buf)
+			err := c.pgconn.SendBytes(ctx, buf)
+			if err != nil {
+				c.pgconn.Close(context.Background())
+				return rowCount, c.connError(err)
+			}
+		case *pgproto3.CommandComplete, *pgproto3.EmptyQueryResponse:
+			return rowCount, c.syncChunks(ctx, nil)
+		case *pgproto3.ErrorResponse:
+			return rowCount, c.syncChunks(ctx, c.connError(pgconn.ErrorResponseToPgError(msg)))
+		}
+	}
+}
+
+// syncChunks ends a query started by execChunks by sending Sync and reading until the server is ready for the next
+// query. It returns err, or if err is nil, any error that occurred ending the query.
+func (c *Conn) syncChunks(ctx context.Context, err error) error {
+	syncErr := c.pgconn.SendBytes(ctx, (&pgproto3.Sync{}).Encode(nil))
+	for syncErr == nil {
+		var msg pgproto3.BackendMessage
+		msg, syncErr = c.pgconn.ReceiveMessage(ctx)
+		switch msg := msg.(type) {
+		case *pgproto3.ReadyForQuery:
+			return err
+		case *pgproto3.ErrorResponse:
+			if err == nil {
+				err = c.connError(pgconn.ErrorResponseToPgError(msg))
+			}
+		}
+	}
+
+	c.pgconn.Close(context.Background())
+	if err == nil {
+		err = c.connError(syncErr)
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package goldilocks_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnQueryChunks(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var n int32
+	var chunks [][]int32
+	var chunk []int32
+	rowCount, err := db.QueryChunks(
+		context.Background(),
+		"select n from generate_series(1, $1) n",
+		[]interface{}{int32(10)},
+		[]interface{}{&n},
+		3,
+		func() error {
+			chunk = append(chunk, n)
+			return nil
+		},
+		func() error {
+			chunks = append(chunks, chunk)
+			chunk = nil
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, rowCount)
+	require.Equal(t, [][]int32{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, chunks)
+	require.Equal(t, []int32{10}, chunk)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestConnQueryChunksStopped(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	stop := errors.New("stop")
+	var n int32
+	rowCount, err := db.QueryChunks(
+		context.Background(),
+		"select n from generate_series(1, 10) n",
+		nil,
+		[]interface{}{&n},
+		2,
+		func() error { return nil },
+		func() error { return stop },
+	)
+	require.Equal(t, stop, err)
+	require.EqualValues(t, 2, rowCount)
+
+	rowCount, err = db.QueryChunks(
+		context.Background(),
+		"select 10 / (5 - n) from generate_series(1, 10) n",
+		nil,
+		[]interface{}{&n},
+		2,
+		func() error { return nil },
+		nil,
+	)
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr))
+	require.Equal(t, "22012", pgErr.Code)
+	require.EqualValues(t, 4, rowCount)
+
+	_, err = db.QueryChunks(context.Background(), "select 1", nil, nil, 0, func() error { return nil }, nil)
+	require.EqualError(t, err, "chunkSize must be greater than 0")
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,257 @@
+package goldilocks
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgio"
+)
+
+// CompositeField is a field of a CompositeType.
+type CompositeField struct {
+	Name string
+	OID  uint32
+}
+
+// CompositeType is a PostgreSQL composite type loaded with LoadCompositeType. It maps composite values to and from Go
+// structs. It is safe for concurrent use.
+//
+// Struct fields are matched to composite fields by the name in their db tag. If no exported field of the struct has a
+// db tag, the exported fields are matched to the composite fields in order. Each struct field is encoded and decoded as
+// if it were a query param or result. When encoding, the Go type of each field must be encoded as the exact PostgreSQL
+// type of the composite field.
+type CompositeType struct {
+	Name   string
+	OID    uint32
+	Fields []CompositeField
+}
+
+// LoadCompositeType loads the composite type name from the database. name may be schema qualified. It may also be the
+// name of a table.
+func LoadCompositeType(ctx context.Context, db StdDB, name string) (*CompositeType, error) {
+	ct := &CompositeType{Name: name}
+
+	var oid, fieldOID int64
+	var fieldName string
+	_, err := db.Query(
+		ctx,
+		`select t.oid::int8, a.attname::text, a.atttypid::int8
+from pg_type t
+  join pg_attribute a on a.attrelid = t.typrelid
+where t.oid = $1::regtype
+  and t.typtype = 'c'
+  and a.attnum > 0
+  and not a.attisdropped
+order by a.attnum`,
+		[]interface{}{name},
+		[]interface{}{&oid, &fieldName, &fieldOID},
+		func() error {
+			ct.OID = uint32(oid)
+			ct.Fields = append(ct.Fields, CompositeField{Name: fieldName, OID: uint32(fieldOID)})
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ct.Fields) == 0 {
+		return nil, fmt.Errorf("%s is not a composite type or has no fields", name)
+	}
+
+	return ct, nil
+}
+
+// Param returns a query param of type ct for src. src must be a struct or a pointer to a struct. A nil pointer is
+// encoded as NULL.
+func (ct *CompositeType) Param(src interface{}) ParamEncoder {
+	return compositeParam{ct: ct, src: src}
+}
+
+// Result returns a result decoder that decodes a value of type ct into dst. dst must be a pointer to a struct.
+func (ct *CompositeType) Result(dst interface{}) ResultDecoder {
+	return compositeResult{ct: ct, dst: dst}
+}
+
+// structFieldIndexes returns the index of the struct field in t for each field of ct.
+func (ct *CompositeType) structFieldIndexes(t reflect.Type) ([]int, error) {
+	var exported []int
+	tagged := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		exported = append(exported, i)
+		if tag, ok := sf.Tag.Lookup("db"); ok && tag != "-" {
+			tagged[tag] = i
+		}
+	}
+
+	if len(tagged) == 0 {
+		if len(exported) != len(ct.Fields) {
+			return nil, fmt.Errorf("%s has %d fields but %v has %d exported fields", ct.Name, len(ct.Fields), t, len(exported))
+		}
+		return exported, nil
+	}
+
+	indexes := make([]int, len(ct.Fields))
+	for i, f := range ct.Fields {
+		idx, ok := tagged[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("%v has no field with db tag %s", t, f.Name)
+		}
+		indexes[i] = idx
+	}
+	return indexes, nil
+}
+
+type compositeParam struct {
+	ct  *CompositeType
+	src interface{}
+}
+
+func (p compositeParam) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	v := reflect.ValueOf(p.src)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, p.ct.OID, binaryFormat, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, 0, 0, fmt.Errorf("%s requires a struct, got %T", p.ct.Name, p.src)
+	}
+
+	indexes, err := p.ct.structFieldIndexes(v.Type())
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	buf = pgio.AppendInt32(buf, int32(len(p.ct.Fields)))
+	for i, f := range p.ct.Fields {
+		buf = pgio.AppendUint32(buf, f.OID)
+		sp := len(buf)
+		buf = pgio.AppendInt32(buf, -1)
+
+		arg := v.Field(indexes[i]).Interface()
+		value, _, format, err := encodeParam(buf, arg)
+		if err == errUnsupportedType {
+			return nil, 0, 0, fmt.Errorf("%s.%s is unsupported type %T", p.ct.Name, f.Name, arg)
+		}
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("%s.%s: %w", p.ct.Name, f.Name, err)
+		}
+		if value == nil {
+			continue
+		}
+		if format != binaryFormat && !textIsBinaryFormat(f.OID) {
+			return nil, 0, 0, fmt.Errorf("%s.%s: %T is not encoded in the binary format", p.ct.Name, f.Name, arg)
+		}
+
+		buf = value
+		pgio.SetInt32(buf[sp:], int32(len(buf[sp:])-4))
+	}
+
+	return buf, p.ct.OID, binaryFormat, nil
+}
+
+type compositeResult struct {
+	ct  *CompositeType
+	dst interface{}
+}
+
+func (compositeResult) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (r compositeResult) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return fmt.Errorf("NULL cannot be converted to %s", r.ct.Name)
+	}
+
+	v := reflect.ValueOf(r.dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%s requires a pointer to a struct, got %T", r.ct.Name, r.dst)
+	}
+	v = v.Elem()
+
+	indexes, err := r.ct.structFieldIndexes(v.Type())
+	if err != nil {
+		return err
+	}
+
+	fields, err := readCompositeFields(buf, r.ct.Name)
+	if err != nil {
+		return err
+	}
+	if len(fields) != len(r.ct.Fields) {
+		return fmt.Errorf("%s requires %d fields, got %d", r.ct.Name, len(r.ct.Fields), len(fields))
+	}
+
+	for i, f := range r.ct.Fields {
+		dst := v.Field(indexes[i]).Addr().Interface()
+		err := decodeCompositeField(r.ct.Name+"."+f.Name, fields[i], dst)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readCompositeFields decodes the fields of a composite or record in the binary format. The values of the returned fields
+// alias buf. typeName is used in error messages.
+func readCompositeFields(buf []byte, typeName string) ([]RecordField, error) {
+	if len(buf) < 4 {
+		return nil, fmt.Errorf("%s requires data length of at least 4, got %d", typeName, len(buf))
+	}
+	n := int(int32(binary.BigEndian.Uint32(buf)))
+	buf = buf[4:]
+
+	// Each field requires at least its 8 byte oid and length. Checking this before allocating prevents a corrupt count
+	// from causing a huge allocation.
+	if n < 0 || len(buf) < n*8 {
+		return nil, fmt.Errorf("%s field count of %d exceeds data length of %d", typeName, n, len(buf))
+	}
+
+	fields := make([]RecordField, n)
+	for i := range fields {
+		if len(buf) < 8 {
+			return nil, fmt.Errorf("%s field %d is missing", typeName, i)
+		}
+		fields[i].OID = binary.BigEndian.Uint32(buf)
+		fieldLen := int(int32(binary.BigEndian.Uint32(buf[4:])))
+		buf = buf[8:]
+
+		if fieldLen < 0 {
+			continue
+		}
+		if len(buf) < fieldLen {
+			return nil, fmt.Errorf("%s field %d requires data length of %d, got %d", typeName, i, fieldLen, len(buf))
+		}
+		fields[i].Value = buf[:fieldLen:fieldLen]
+		buf = buf[fieldLen:]
+	}
+
+	return fields, nil
+}
+
+// decodeCompositeField decodes field into dst as if dst were a query result. name is used in error messages.
+func decodeCompositeField(name string, field RecordField, dst interface{}) error {
+	rd, ok := newResultDecoder(dst)
+	if !ok {
+		return fmt.Errorf("%s is unsupported type %T", name, dst)
+	}
+	if rd.ResultFormat() != binaryFormat && !textIsBinaryFormat(field.OID) {
+		return fmt.Errorf("%s: %T does not decode the binary format", name, dst)
+	}
+
+	err := rd.DecodeResult(field.Value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
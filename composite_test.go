@@ -0,0 +1,94 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+type inventoryItem struct {
+	Name     string
+	Supplier goldilocks.NullInt32
+	Price    float64
+}
+
+type taggedInventoryItem struct {
+	Price float64 `db:"price"`
+	Name  string  `db:"name"`
+	Note  string  `db:"-"`
+	Count int32   `db:"supplier_id"`
+}
+
+func TestCompositeType(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "create type pg_temp.inventory_item as (name text, supplier_id int4, price float8)")
+	require.NoError(t, err)
+
+	itemType, err := goldilocks.LoadCompositeType(context.Background(), db, "pg_temp.inventory_item")
+	require.NoError(t, err)
+	require.NotZero(t, itemType.OID)
+	require.Equal(t,
+		[]goldilocks.CompositeField{{Name: "name", OID: 25}, {Name: "supplier_id", OID: 23}, {Name: "price", OID: 701}},
+		itemType.Fields,
+	)
+
+	item := inventoryItem{Name: "fuzzy dice", Price: 1.99}
+	var text string
+	var result inventoryItem
+	_, err = db.Query(
+		context.Background(),
+		"select $1::text, $1",
+		[]interface{}{itemType.Param(item)},
+		[]interface{}{&text, itemType.Result(&result)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, `("fuzzy dice",,1.99)`, text)
+	require.Equal(t, item, result)
+
+	var tagged taggedInventoryItem
+	_, err = db.Query(
+		context.Background(),
+		"select row('dice', 42, 2.5)::pg_temp.inventory_item",
+		nil,
+		[]interface{}{itemType.Result(&tagged)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, taggedInventoryItem{Name: "dice", Count: 42, Price: 2.5}, tagged)
+
+	var isNull bool
+	_, err = db.Query(
+		context.Background(),
+		"select $1 is null",
+		[]interface{}{itemType.Param((*inventoryItem)(nil))},
+		[]interface{}{&isNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.True(t, isNull)
+
+	_, err = db.Query(
+		context.Background(),
+		"select null::pg_temp.inventory_item",
+		nil,
+		[]interface{}{itemType.Result(&result)},
+		func() error { return nil },
+	)
+	require.EqualError(t, err, `cannot decode results[0] (column "inventory_item"): NULL cannot be converted to pg_temp.inventory_item`)
+
+	_, err = goldilocks.LoadCompositeType(context.Background(), db, "int4")
+	require.EqualError(t, err, "int4 is not a composite type or has no fields")
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -2,14 +2,29 @@ package goldilocks
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgproto3/v2"
 )
 
 type Conn struct {
-	pgconn *pgconn.PgConn
+	pgconn   *pgconn.PgConn
+	database string
+
+	// lock is only used by a Conn created with NewSerializedConn. It is a channel rather than a sync.Mutex so waiting for
+	// it can be interrupted by a context.
+	lock chan struct{}
 
 	paramValuesBuf []byte
 
@@ -19,29 +34,247 @@ type Conn struct {
 
 	resultFormats  []int16
 	resultDecoders []ResultDecoder
+
+	config ConnConfig
+
+	preparedStatements map[string]*Statement
+
+	// sessionParamsChanged is set when SetSessionParam changes a session parameter.
+	sessionParamsChanged bool
+
+	statsMux sync.Mutex
+	stats    ConnStats
+}
+
+// ConnStats are statistics of the statements executed by a Conn.
+type ConnStats struct {
+	QueryCount   int64         // number of queries and execs executed, including those in transactions
+	BusyDuration time.Duration // total time spent executing queries and execs
+	LastUsed     time.Time     // when the last query or exec finished
 }
 
-// NewConn creates a Conn from pgconn.
+// ConnConfig configures a Conn created with NewConnConfig.
+type ConnConfig struct {
+	// TextResults makes results default to the text format instead of the binary format. This can help with proxies or
+	// servers that do not handle the binary format well. It only affects results whose decoders implement
+	// TextResultDecoder. Use BinaryResult to read a result in the binary format anyway.
+	TextResults bool
+
+	// ParamResolvers are called in order for each query param before the builtin encoding. The first to return true
+	// determines how the param is encoded. This allows overriding the encoding of any type, including builtin types.
+	// ParamResolvers do not apply to the fields of composites, records, or ranges.
+	ParamResolvers []ParamResolver
+
+	// ResultResolvers are called in order for each query result before the builtin decoding. The first to return true
+	// determines how the result is decoded. ResultResolvers do not apply to the fields of composites, records, or ranges.
+	ResultResolvers []ResultResolver
+
+	// ErrorHandler is called with every error returned by Query, Exec, and Begin. The error it returns is returned
+	// instead. This allows errors such as a *pgconn.PgError for a unique violation to be translated into application
+	// errors in one place. The error returned by the function passed to Begin is returned as is because any error from a
+	// query in the transaction has already been handled. ErrorHandler is not called for errors acquiring a connection
+	// from a Pool.
+	ErrorHandler func(err error) error
+
+	// Backend is the server implementation to adjust for. The default, BackendAuto, detects it from the server.
+	Backend Backend
+
+	// SimpleProtocol makes Query and Exec use the simple protocol instead of the extended protocol. Params are
+	// interpolated into the SQL as literals on the client. This is compatible with connection poolers that do not
+	// support the extended protocol and allows params in statements that cannot be parameterized, such as some utility
+	// commands. sql may contain multiple statements. Params must be of builtin scalar types or encode in the text format.
+	// Results must be in the text format or implement TextResultDecoder. A *string can receive any other type. Use
+	// WithSimpleProtocol to use the simple protocol for a single query. Prepared statements always use the extended
+	// protocol.
+	SimpleProtocol bool
+
+	// QueryRewriter is called with the SQL and args of every Query and Exec before they are executed. The SQL and args
+	// it returns are executed instead. This allows applying changes such as tenant scoping or comments to all queries
+	// in one place. It is not called for prepared statements.
+	QueryRewriter QueryRewriter
+
+	// Logger logs every Query and Exec with its SQL, args, duration, row count, and error. Successful queries are logged
+	// at LogLevelInfo and failed queries at LogLevelError. Args are logged as given so they may contain sensitive data.
+	Logger Logger
+
+	// LogLevel is the most verbose level logged by Logger. The default is LogLevelInfo. Use LogLevelError to log only
+	// failed queries and LogLevelNone to log nothing.
+	LogLevel LogLevel
+
+	// Tracer is called around every Query, Exec, Begin, Commit, Rollback, and CopyFrom. A Pool also calls it around
+	// acquiring and releasing connections.
+	Tracer Tracer
+
+	// StrictResultTypes makes Query check the type of each column against the types its result can decode before
+	// decoding any rows. A mismatch returns a *ResultTypeError such as `column 3 "created_at" is timestamptz but
+	// destination is *int64` instead of decoding a value of the wrong type or failing with a length error. Only results
+	// of builtin types in the binary format are checked.
+	StrictResultTypes bool
+}
+
+// ParamResolver returns the ParamEncoder to use for arg and true, or false if it does not handle arg.
+type ParamResolver func(arg interface{}) (ParamEncoder, bool)
+
+// ResultResolver returns the ResultDecoder to use for dst and true, or false if it does not handle dst.
+type ResultResolver func(dst interface{}) (ResultDecoder, bool)
+
+// QueryRewriter returns the SQL and args to execute in place of sql and args.
+type QueryRewriter func(ctx context.Context, sql string, args []interface{}) (string, []interface{}, error)
+
+// NewConn creates a Conn from pgconn. The Conn is not safe for concurrent use.
 func NewConn(pgconn *pgconn.PgConn) *Conn {
 	return &Conn{pgconn: pgconn}
 }
 
+// NewConnConfig creates a Conn from pgconn configured by config. The Conn is not safe for concurrent use.
+func NewConnConfig(pgconn *pgconn.PgConn, config ConnConfig) *Conn {
+	return &Conn{pgconn: pgconn, config: config}
+}
+
+// NewSerializedConn creates a Conn from pgconn that is safe for concurrent use. Query, Exec, and Begin wait until
+// operations from other goroutines have finished. If ctx is canceled while waiting ctx.Err() is returned. A Begin holds
+// the Conn until f returns, so concurrent operations wait for the entire transaction. A rowFunc must not use the Conn.
+func NewSerializedConn(pgconn *pgconn.PgConn) *Conn {
+	return &Conn{pgconn: pgconn, lock: make(chan struct{}, 1)}
+}
+
+func (c *Conn) acquire(ctx context.Context) error {
+	if c.lock == nil {
+		return nil
+	}
+
+	select {
+	case c.lock <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Conn) release() {
+	if c.lock != nil {
+		<-c.lock
+	}
+}
+
 type valueReaderFunc func([]byte) error
 
 func (c *Conn) Query(ctx context.Context, sql string, args []interface{}, results []interface{}, rowFunc func() error) (int64, error) {
-	err := c.prepareParams(args)
+	err := c.acquire(ctx)
+	if err != nil {
+		return 0, c.handleError(err)
+	}
+	defer c.release()
+
+	return c.query(ctx, sql, nil, args, results, rowFunc)
+}
+
+// query executes sql, or the prepared statement stmt if it is not nil.
+func (c *Conn) query(ctx context.Context, sql string, stmt *Statement, args []interface{}, results []interface{}, rowFunc func() error) (int64, error) {
+	start := time.Now()
+	defer c.recordStats(start)
+
+	sql, args, err := c.rewriteQuery(ctx, sql, stmt, args)
+	if err != nil {
+		return 0, c.handleError(err)
+	}
+
+	ctx, endTrace := c.startTrace(ctx, TraceData{Op: TraceQuery, SQL: sql, ArgCount: len(args)})
+
+	var rowCount int64
+	if hb, ok := ctx.Value(heartbeatCtxKey{}).(*heartbeat); ok {
+		rowCount, err = c.queryWithHeartbeat(ctx, hb, sql, stmt, args, results, rowFunc)
+	} else {
+		err = c.withCancelRequest(ctx, func(ctx context.Context) error {
+			var err error
+			rowCount, err = c.queryRows(ctx, sql, stmt, args, results, rowFunc)
+			return err
+		})
+	}
+	err = c.sqlError(ctx, err, sql, stmt, args)
+	endTrace(rowCount, err)
+	c.logQuery(ctx, "Query", sql, args, start, rowCount, err)
+
+	return rowCount, c.handleError(err)
+}
+
+func (c *Conn) queryRows(ctx context.Context, sql string, stmt *Statement, args []interface{}, results []interface{}, rowFunc func() error) (int64, error) {
+	if dst, ok := rowMapResult(results); ok {
+		return c.queryRowMap(ctx, sql, stmt, args, dst, rowFunc)
+	}
+
+	if stmt == nil && c.useSimpleProtocol(ctx) {
+		return c.simpleQueryRows(ctx, sql, args, results, rowFunc)
+	}
+
+	err := c.prepareParams(args, stmt)
 	if err != nil {
 		return 0, err
 	}
 
+	if stmt != nil {
+		err = c.checkStatementParams(stmt, args)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	err = c.prepareResults(results)
 	if err != nil {
 		return 0, err
 	}
 
-	rr := c.pgconn.ExecParams(ctx, sql, c.paramValues, c.paramOIDs, c.paramFormats, c.resultFormats)
+	if stmt != nil {
+		err = c.checkResultCount(stmt.Fields)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	rowCount, err := c.execQueryRows(ctx, sql, stmt, results, rowFunc)
+	if err != nil && rowCount == 0 && c.useTextResultFallback(err) {
+		rowCount, err = c.execQueryRows(ctx, sql, stmt, results, rowFunc)
+	}
+	if err != nil && rowCount == 0 && stmt == nil && isResultFormatCountError(err) {
+		err = c.describeResultCountError(ctx, sql, err)
+	}
+	if err != nil {
+		return rowCount, err
+	}
+
+	c.releaseOversizedParamValuesBuf()
+
+	return rowCount, nil
+}
+
+// execQueryRows executes sql, or the prepared statement stmt if it is not nil, with the prepared params and results.
+// results are the results the decoders were prepared from.
+func (c *Conn) execQueryRows(ctx context.Context, sql string, stmt *Statement, results []interface{}, rowFunc func() error) (int64, error) {
+	var rr *pgconn.ResultReader
+	if stmt == nil {
+		rr = c.pgconn.ExecParams(ctx, sql, c.paramValues, c.paramOIDs, c.paramFormats, c.resultFormats)
+	} else {
+		rr = c.pgconn.ExecPrepared(ctx, stmt.Name, c.paramValues, c.paramFormats, c.resultFormats)
+	}
 	defer rr.Close()
 
+	err := c.checkResultCount(rr.FieldDescriptions())
+	if err != nil {
+		return 0, err
+	}
+
+	if c.config.StrictResultTypes {
+		err = c.checkResultTypes(rr.FieldDescriptions(), results)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return c.decodeRows(rr, rowFunc)
+}
+
+// decodeRows decodes each row of rr with the prepared results and calls rowFunc.
+func (c *Conn) decodeRows(rr *pgconn.ResultReader, rowFunc func() error) (int64, error) {
 	var rowCount int64
 	for rr.NextRow() {
 		rowCount++
@@ -50,7 +283,11 @@ func (c *Conn) Query(ctx context.Context, sql string, args []interface{}, result
 		for i := range c.resultDecoders {
 			err := c.resultDecoders[i].DecodeResult(values[i])
 			if err != nil {
-				return rowCount, err
+				decodeErr := &DecodeError{Index: i, Err: err}
+				if fields := rr.FieldDescriptions(); i < len(fields) {
+					decodeErr.Name = string(fields[i].Name)
+				}
+				return rowCount, decodeErr
 			}
 		}
 
@@ -60,72 +297,272 @@ func (c *Conn) Query(ctx context.Context, sql string, args []interface{}, result
 		}
 	}
 
-	_, err = rr.Close()
+	_, err := rr.Close()
 	if err != nil {
-		return rowCount, err
+		return rowCount, c.connError(err)
 	}
 
-	c.releaseOversizedParamValuesBuf()
-
 	return rowCount, nil
 }
 
+// checkResultCount returns an error if the number of prepared results differs from the number of columns in fields.
+// Queries without results, which ignore the values of their columns, and statements that do not return rows are not
+// checked.
+func (c *Conn) checkResultCount(fields []pgproto3.FieldDescription) error {
+	if len(c.resultDecoders) == 0 || len(fields) == 0 || len(fields) == len(c.resultDecoders) {
+		return nil
+	}
+
+	if len(fields) > len(c.resultDecoders) {
+		names := make([]string, 0, len(fields)-len(c.resultDecoders))
+		for _, f := range fields[len(c.resultDecoders):] {
+			names = append(names, fmt.Sprintf("%q", f.Name))
+		}
+		return fmt.Errorf("query returned %d columns but %d results were given; columns without results: %s", len(fields), len(c.resultDecoders), strings.Join(names, ", "))
+	}
+
+	return fmt.Errorf("query returned %d columns but %d results were given; results[%d:] have no columns", len(fields), len(c.resultDecoders), len(fields))
+}
+
+// isResultFormatCountError checks if err is the server rejecting a query because the number of result formats differs
+// from the number of columns.
+func isResultFormatCountError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ProtocolViolation && strings.HasPrefix(pgErr.Message, "bind message has")
+}
+
+// describeResultCountError returns the error of checkResultCount for the columns of sql in place of err, the error of
+// the server rejecting the number of result formats. The columns are only known after describing sql, which is not
+// possible in a failed transaction. If sql cannot be described err is returned.
+func (c *Conn) describeResultCountError(ctx context.Context, sql string, err error) error {
+	if c.pgconn.TxStatus() != 'I' {
+		return err
+	}
+
+	sd, describeErr := c.pgconn.Prepare(ctx, "", sql, c.paramOIDs)
+	if describeErr != nil {
+		return err
+	}
+
+	countErr := c.checkResultCount(sd.Fields)
+	if countErr == nil {
+		return err
+	}
+	return countErr
+}
+
+// useTextResultFallback checks if err was caused by the server being unable to send a result in the binary format. If
+// so, and the failed statement did not leave a transaction in progress, it switches every binary result whose decoder
+// implements TextResultDecoder to the text format and returns true if any were switched.
+func (c *Conn) useTextResultFallback(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgerrcode.UndefinedFunction || !strings.HasPrefix(pgErr.Message, "no binary output function") {
+		return false
+	}
+
+	// The error aborted the statement. Outside of a transaction nothing it did persists so it is safe to execute again.
+	if c.pgconn.TxStatus() != 'I' {
+		return false
+	}
+
+	switched := false
+	for i, rd := range c.resultDecoders {
+		if trd, ok := rd.(TextResultDecoder); ok && c.resultFormats[i] == binaryFormat {
+			c.resultFormats[i] = textFormat
+			c.resultDecoders[i] = textResultDecoder{trd}
+			switched = true
+		}
+	}
+
+	return switched
+}
+
 func (c *Conn) Exec(ctx context.Context, sql string, args ...interface{}) (int64, error) {
-	err := c.prepareParams(args)
+	err := c.acquire(ctx)
 	if err != nil {
-		return 0, err
+		return 0, c.handleError(err)
 	}
+	defer c.release()
+
+	return c.exec(ctx, sql, nil, args...)
+}
 
-	commandTag, err := c.pgconn.ExecParams(ctx, sql, c.paramValues, c.paramOIDs, c.paramFormats, nil).Close()
+// exec executes sql, or the prepared statement stmt if it is not nil.
+func (c *Conn) exec(ctx context.Context, sql string, stmt *Statement, args ...interface{}) (int64, error) {
+	start := time.Now()
+	defer c.recordStats(start)
+
+	sql, args, err := c.rewriteQuery(ctx, sql, stmt, args)
+	if err != nil {
+		return 0, c.handleError(err)
+	}
+
+	ctx, endTrace := c.startTrace(ctx, TraceData{Op: TraceExec, SQL: sql, ArgCount: len(args)})
+
+	var rowsAffected int64
+	err = c.withCancelRequest(ctx, func(ctx context.Context) error {
+		var err error
+		if stmt == nil && c.useSimpleProtocol(ctx) {
+			rowsAffected, err = c.simpleExec(ctx, sql, args)
+		} else {
+			rowsAffected, err = c.execParams(ctx, sql, stmt, args)
+		}
+		return err
+	})
+	err = c.sqlError(ctx, err, sql, stmt, args)
+	endTrace(rowsAffected, err)
+	c.logQuery(ctx, "Exec", sql, args, start, rowsAffected, err)
+
+	return rowsAffected, c.handleError(err)
+}
+
+// execParams executes sql, or the prepared statement stmt if it is not nil, with args in the extended protocol.
+func (c *Conn) execParams(ctx context.Context, sql string, stmt *Statement, args []interface{}) (int64, error) {
+	err := c.prepareParams(args, stmt)
 	if err != nil {
 		return 0, err
 	}
 
+	var rr *pgconn.ResultReader
+	if stmt == nil {
+		rr = c.pgconn.ExecParams(ctx, sql, c.paramValues, c.paramOIDs, c.paramFormats, nil)
+	} else {
+		err = c.checkStatementParams(stmt, args)
+		if err != nil {
+			return 0, err
+		}
+		rr = c.pgconn.ExecPrepared(ctx, stmt.Name, c.paramValues, c.paramFormats, nil)
+	}
+
+	commandTag, err := rr.Close()
+	if err != nil {
+		return 0, c.connError(err)
+	}
+
 	c.releaseOversizedParamValuesBuf()
 
 	return commandTag.RowsAffected(), nil
 }
 
+// Begin starts a transaction and calls f with a StdDB that executes in it. If f returns nil the transaction is
+// committed. Otherwise it is rolled back. Begin returns:
+//
+//   - nil if the transaction was committed.
+//   - the error returned by f if f returned an error. The transaction was rolled back.
+//   - ErrTxFailed if f returned nil but the transaction had failed. The transaction was rolled back.
+//   - an error wrapping *CommitUnknownError if it is unknown whether the commit succeeded.
+//   - any other error if the transaction could not be started or the commit failed. The transaction was not committed.
 func (c *Conn) Begin(ctx context.Context, f func(StdDB) error) error {
-	err := c.pgconn.Exec(ctx, "begin").Close()
+	err := c.acquire(ctx)
+	if err != nil {
+		return c.handleError(err)
+	}
+	defer c.release()
+
+	return c.begin(ctx, f)
+}
+
+func (c *Conn) begin(ctx context.Context, f func(StdDB) error) error {
+	tx, err := c.beginTx(ctx)
 	if err != nil {
 		return err
 	}
-	txInProgress := true
-	rollback := func() {
-		if txInProgress == true {
-			err := c.pgconn.Exec(ctx, "rollback").Close()
-			if err != nil {
-				c.pgconn.Close(context.Background())
-			}
-			txInProgress = false
+	defer func() {
+		if !tx.closed {
+			tx.rollback(ctx)
 		}
+	}()
+
+	// A serialized Conn is already held by this goroutine. f must use it without waiting for itself.
+	var db StdDB = c
+	if c.lock != nil {
+		db = (*heldConn)(c)
 	}
-	defer rollback()
 
-	err = f(c)
+	err = f(db)
 	if err != nil {
 		return err
 	}
 
-	switch txStatus := c.pgconn.TxStatus(); txStatus {
-	case 'T':
-		return c.pgconn.Exec(ctx, "commit").Close()
-	case 'E':
-		rollback()
-		return fmt.Errorf("rolled back failed transaction")
-	case 'I':
-		return fmt.Errorf("not in transaction after calling f")
-	default:
-		return fmt.Errorf("impossible txStatus: %v", txStatus)
+	return tx.Commit(ctx)
+}
+
+// Ping checks that the connection to the server is alive by executing an empty statement.
+func (c *Conn) Ping(ctx context.Context) error {
+	err := c.acquire(ctx)
+	if err != nil {
+		return err
 	}
+	defer c.release()
+
+	err = c.pgconn.Exec(ctx, ";").Close()
+	if err != nil {
+		return c.connError(err)
+	}
+
+	return nil
+}
+
+// Stats returns the statistics of c. It is safe to call concurrently with other methods of c.
+func (c *Conn) Stats() ConnStats {
+	c.statsMux.Lock()
+	defer c.statsMux.Unlock()
+	return c.stats
+}
+
+// recordStats records a query or exec that started at start and has just finished.
+func (c *Conn) recordStats(start time.Time) {
+	now := time.Now()
+
+	c.statsMux.Lock()
+	c.stats.QueryCount++
+	c.stats.BusyDuration += now.Sub(start)
+	c.stats.LastUsed = now
+	c.statsMux.Unlock()
+}
+
+// rewriteQuery returns the SQL and args to execute as rewritten by the QueryRewriter of c. Prepared statements are not
+// rewritten.
+func (c *Conn) rewriteQuery(ctx context.Context, sql string, stmt *Statement, args []interface{}) (string, []interface{}, error) {
+	if c.config.QueryRewriter == nil || stmt != nil {
+		return sql, args, nil
+	}
+	return c.config.QueryRewriter(ctx, sql, args)
+}
+
+// handleError returns err as transformed by the ErrorHandler of c. It returns nil if err is nil.
+func (c *Conn) handleError(err error) error {
+	if err == nil || c.config.ErrorHandler == nil {
+		return err
+	}
+	return c.config.ErrorHandler(err)
+}
+
+// heldConn is a serialized Conn that is already held by the current goroutine.
+type heldConn Conn
+
+func (c *heldConn) Query(ctx context.Context, sql string, args []interface{}, results []interface{}, rowFunc func() error) (int64, error) {
+	return (*Conn)(c).query(ctx, sql, nil, args, results, rowFunc)
+}
+
+func (c *heldConn) Exec(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	return (*Conn)(c).exec(ctx, sql, nil, args...)
 }
 
+func (c *heldConn) Begin(ctx context.Context, f func(StdDB) error) error {
+	return (*Conn)(c).begin(ctx, f)
+}
+
+// ParamEncoder is implemented by types that can encode themselves as a query param. EncodeParam appends the encoded
+// value to buf. It returns a nil valueBuf to encode NULL. If it returns an error it may also return the oid of the type it
+// could not encode as to include it in the error.
 type ParamEncoder interface {
-	EncodeParam(buf []byte) (valueBuf []byte, oid uint32, format int16)
+	EncodeParam(buf []byte) (valueBuf []byte, oid uint32, format int16, err error)
 }
 
-func (c *Conn) prepareParams(args []interface{}) error {
+// prepareParams encodes args. stmt is the prepared statement args are for or nil. The types it requires are included in
+// errors for args whose encoders do not report a type.
+func (c *Conn) prepareParams(args []interface{}, stmt *Statement) error {
 	if len(args) == 0 {
 		c.paramValues = c.paramValues[0:0]
 		c.paramOIDs = c.paramOIDs[0:0]
@@ -152,35 +589,24 @@ func (c *Conn) prepareParams(args []interface{}) error {
 		c.paramFormats = c.paramFormats[0:len(args)]
 	}
 
+	// The buffer must not be nil. Otherwise an encoder that appends nothing, such as for an empty []byte, would return
+	// nil which is NULL.
+	if c.paramValuesBuf == nil {
+		c.paramValuesBuf = make([]byte, 0, 512)
+	}
 	c.paramValuesBuf = c.paramValuesBuf[0:0]
 
 	for i := range args {
-		var value []byte
-		var oid uint32
-		var format int16
-
-		switch arg := args[i].(type) {
-		case string:
-			value, oid, format = writeString(c.paramValuesBuf, arg)
-		case int16:
-			value, oid, format = writeInt16(c.paramValuesBuf, arg)
-		case int32:
-			value, oid, format = writeInt32(c.paramValuesBuf, arg)
-		case int64:
-			value, oid, format = writeInt64(c.paramValuesBuf, arg)
-		case float32:
-			value, oid, format = writeFloat32(c.paramValuesBuf, arg)
-		case float64:
-			value, oid, format = writeFloat64(c.paramValuesBuf, arg)
-		case bool:
-			value, oid, format = writeBool(c.paramValuesBuf, arg)
-		case time.Time:
-			value, oid, format = writeTime(c.paramValuesBuf, arg)
-		case ParamEncoder:
-			value, oid, format = arg.EncodeParam(c.paramValuesBuf)
-		default:
+		value, oid, format, err := encodeParam(c.paramValuesBuf, c.resolveParam(args[i]))
+		if err == errUnsupportedType {
 			return fmt.Errorf("args[%d] is unsupported type %T", i, args[i])
 		}
+		if err != nil {
+			if oid == 0 && stmt != nil && i < len(stmt.ParamOIDs) {
+				oid = stmt.ParamOIDs[i]
+			}
+			return &EncodeError{Index: i, Type: fmt.Sprintf("%T", args[i]), OID: oid, Err: err}
+		}
 
 		if value == nil {
 			c.paramValues[i] = nil
@@ -201,6 +627,45 @@ type ResultDecoder interface {
 	DecodeResult([]byte) error
 }
 
+// TextResultDecoder is implemented by a ResultDecoder that prefers the binary format but can also decode the text
+// format. If a query outside of a transaction fails because the server cannot send a result in the binary format, such
+// as a type from an extension without binary send and receive functions, the query is executed again with the text
+// format for results whose decoders implement TextResultDecoder. The fallback costs a failed query each time, so
+// decoders for types known to lack binary support should use the text format from the start.
+type TextResultDecoder interface {
+	ResultDecoder
+	DecodeTextResult([]byte) error
+}
+
+// textResultDecoder adapts a TextResultDecoder to decode the text format.
+type textResultDecoder struct {
+	d TextResultDecoder
+}
+
+func (textResultDecoder) ResultFormat() int16 {
+	return textFormat
+}
+
+func (trd textResultDecoder) DecodeResult(buf []byte) error {
+	return trd.d.DecodeTextResult(buf)
+}
+
+// TextResult returns a result decoder that reads dst in the text format.
+func TextResult(dst TextResultDecoder) ResultDecoder {
+	return textResultDecoder{dst}
+}
+
+// BinaryResult returns a result decoder that reads dst in the format of dst even if the Conn defaults to the text
+// format. See ConnConfig.TextResults.
+func BinaryResult(dst ResultDecoder) ResultDecoder {
+	return binaryResultDecoder{dst}
+}
+
+// binaryResultDecoder prevents a ResultDecoder from being switched to the text format by ConnConfig.TextResults.
+type binaryResultDecoder struct {
+	ResultDecoder
+}
+
 func (c *Conn) prepareResults(results []interface{}) error {
 	if len(results) == 0 {
 		c.resultFormats = c.resultFormats[0:0]
@@ -226,31 +691,13 @@ func (c *Conn) prepareResults(results []interface{}) error {
 	}
 
 	for i := range results {
-		var resultDecoder ResultDecoder
-		switch arg := results[i].(type) {
-		case *string:
-			resultDecoder = (*notNullString)(arg)
-		case *int16:
-			resultDecoder = (*notNullInt16)(arg)
-		case *int32:
-			resultDecoder = (*notNullInt32)(arg)
-		case *int64:
-			resultDecoder = (*notNullInt64)(arg)
-		case *float32:
-			resultDecoder = (*notNullFloat32)(arg)
-		case *float64:
-			resultDecoder = (*notNullFloat64)(arg)
-		case *bool:
-			resultDecoder = (*notNullBool)(arg)
-		case *time.Time:
-			resultDecoder = (*notNullTime)(arg)
-		case ResultDecoder:
-			resultDecoder = arg
-		case nil:
-			resultDecoder = nilSkip{}
-		default:
+		resultDecoder, ok := c.resolveResult(results[i])
+		if !ok {
 			return fmt.Errorf("results[%d] is unsupported type %T", i, results[i])
 		}
+		if trd, ok := resultDecoder.(TextResultDecoder); ok && c.config.TextResults && trd.ResultFormat() == binaryFormat {
+			resultDecoder = textResultDecoder{trd}
+		}
 
 		c.resultFormats[i] = resultDecoder.ResultFormat()
 		c.resultDecoders[i] = resultDecoder
@@ -259,6 +706,204 @@ func (c *Conn) prepareResults(results []interface{}) error {
 	return nil
 }
 
+// resolveParam returns the ParamEncoder from the first of c.config.ParamResolvers that handles arg. If none do it returns
+// arg.
+func (c *Conn) resolveParam(arg interface{}) interface{} {
+	for _, resolve := range c.config.ParamResolvers {
+		if pe, ok := resolve(arg); ok {
+			return pe
+		}
+	}
+	return arg
+}
+
+// resolveResult returns the ResultDecoder from the first of c.config.ResultResolvers that handles dst. If none do it
+// returns the builtin ResultDecoder for dst.
+func (c *Conn) resolveResult(dst interface{}) (ResultDecoder, bool) {
+	for _, resolve := range c.config.ResultResolvers {
+		if rd, ok := resolve(dst); ok {
+			return rd, true
+		}
+	}
+	// The value a pointer to a pointer points to is resolved the same way.
+	if rd, ok := newPtrResult(dst, c.resolveResult); ok {
+		return rd, true
+	}
+	return newResultDecoder(dst)
+}
+
+// errUnsupportedType is returned by encodeParam when arg is not a supported type.
+var errUnsupportedType = errors.New("unsupported type")
+
+// errUntypedNil is returned by encodeParam when arg is nil. Without a type the server cannot always infer the type of
+// the param.
+var errUntypedNil = errors.New("untyped nil cannot be encoded; use a nil pointer or a Null type for NULL")
+
+// errNilPointer is returned by encodeParam when arg is a nil pointer to a ParamEncoder that cannot encode it.
+var errNilPointer = errors.New("nil pointer cannot be encoded; use a Null type that is not valid for NULL")
+
+// encodeParam appends arg to buf in the format it is encoded with and returns the oid of its type.
+func encodeParam(buf []byte, arg interface{}) ([]byte, uint32, int16, error) {
+	switch arg := arg.(type) {
+	case string:
+		return writeString(buf, arg)
+	case int16:
+		return writeInt16(buf, arg)
+	case int32:
+		return writeInt32(buf, arg)
+	case int64:
+		return writeInt64(buf, arg)
+	case int:
+		return writeInt(buf, arg)
+	case uint:
+		return writeUint(buf, arg)
+	case uint64:
+		return writeUint64(buf, arg)
+	case float32:
+		return writeFloat32(buf, arg)
+	case float64:
+		return writeFloat64(buf, arg)
+	case bool:
+		return writeBool(buf, arg)
+	case time.Time:
+		return writeTime(buf, arg)
+	// A pointer to a scalar type encodes the value it points to or NULL if it is nil.
+	case *string:
+		if arg == nil {
+			return nil, 0, textFormat, nil
+		}
+		return writeString(buf, *arg)
+	case *int16:
+		if arg == nil {
+			return nil, 0, binaryFormat, nil
+		}
+		return writeInt16(buf, *arg)
+	case *int32:
+		if arg == nil {
+			return nil, 0, binaryFormat, nil
+		}
+		return writeInt32(buf, *arg)
+	case *int64:
+		if arg == nil {
+			return nil, 0, binaryFormat, nil
+		}
+		return writeInt64(buf, *arg)
+	case *float32:
+		if arg == nil {
+			return nil, 0, binaryFormat, nil
+		}
+		return writeFloat32(buf, *arg)
+	case *float64:
+		if arg == nil {
+			return nil, 0, binaryFormat, nil
+		}
+		return writeFloat64(buf, *arg)
+	case *bool:
+		if arg == nil {
+			return nil, 0, binaryFormat, nil
+		}
+		return writeBool(buf, *arg)
+	case *time.Time:
+		if arg == nil {
+			return nil, 0, binaryFormat, nil
+		}
+		return writeTime(buf, *arg)
+	case *big.Int:
+		if arg == nil {
+			return nil, 0, binaryFormat, nil
+		}
+		return writeBigInt(buf, arg)
+	case big.Int:
+		return writeBigInt(buf, &arg)
+	case net.IPNet:
+		return writeInet(buf, arg)
+	case net.HardwareAddr:
+		return writeHardwareAddr(buf, arg)
+	case []byte:
+		return writeBytea(buf, arg)
+	case []int16, []int32, []int64, []float32, []float64, []bool, []string, []Date, []time.Time:
+		return writeSliceArray(buf, arg)
+	case json.RawMessage:
+		return writeJSONB(buf, arg)
+	case ParamEncoder:
+		if isNilValueEncoder(arg) {
+			return nil, 0, 0, errNilPointer
+		}
+		return arg.EncodeParam(buf)
+	case DecimalDecomposer:
+		return writeDecimal(buf, arg)
+	case nil:
+		return nil, 0, 0, errUntypedNil
+	default:
+		return nil, 0, 0, errUnsupportedType
+	}
+}
+
+// isNilValueEncoder returns true if pe is a nil pointer to a type that implements ParamEncoder with a value receiver,
+// such as a nil *Date. Calling EncodeParam on it would panic.
+func isNilValueEncoder(pe ParamEncoder) bool {
+	v := reflect.ValueOf(pe)
+	return v.Kind() == reflect.Ptr && v.IsNil() && v.Type().Elem().Implements(paramEncoderType)
+}
+
+var paramEncoderType = reflect.TypeOf((*ParamEncoder)(nil)).Elem()
+
+// newResultDecoder returns the ResultDecoder for dst. It returns false if dst is not a supported type.
+func newResultDecoder(dst interface{}) (ResultDecoder, bool) {
+	switch arg := dst.(type) {
+	case *string:
+		return (*notNullString)(arg), true
+	case *byte:
+		return (*notNullChar)(arg), true
+	case *int16:
+		return (*notNullInt16)(arg), true
+	case *int32:
+		return (*notNullInt32)(arg), true
+	case *int64:
+		return (*notNullInt64)(arg), true
+	case *int:
+		return (*notNullInt)(arg), true
+	case *uint:
+		return (*notNullUint)(arg), true
+	case *uint64:
+		return (*notNullUint64)(arg), true
+	case *big.Int:
+		return (*bigInt)(arg), true
+	case *float32:
+		return (*notNullFloat32)(arg), true
+	case *float64:
+		return (*notNullFloat64)(arg), true
+	case *bool:
+		return (*notNullBool)(arg), true
+	case *time.Time:
+		return (*notNullTime)(arg), true
+	case *net.IPNet:
+		return (*notNullInet)(arg), true
+	case *net.HardwareAddr:
+		return (*notNullHardwareAddr)(arg), true
+	case *[]byte:
+		return (*bytea)(arg), true
+	case *[]int32:
+		return (*int32Array)(arg), true
+	case *[]string:
+		return (*stringArray)(arg), true
+	case *[]float64:
+		return (*float64Array)(arg), true
+	case *json.RawMessage:
+		return (*jsonRawMessage)(arg), true
+	case ResultDecoder:
+		return arg, true
+	case DecimalComposer:
+		return decimalComposer{dst: arg}, true
+	case sql.Scanner:
+		return sqlScanner{dst: arg}, true
+	case nil:
+		return nilSkip{}, true
+	default:
+		return newPtrResult(dst, newResultDecoder)
+	}
+}
+
 func (c *Conn) releaseOversizedParamValuesBuf() {
 	if len(c.paramValuesBuf)+512 < cap(c.paramValuesBuf)/2 {
 		c.paramValuesBuf = nil
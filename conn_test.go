@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -132,6 +134,7 @@ func TestConnBeginBrokenTxIsRolledBack(t *testing.T) {
 		return nil
 	})
 	require.EqualError(t, err, "rolled back failed transaction")
+	require.True(t, errors.Is(err, goldilocks.ErrTxFailed))
 
 	rowsAffected, err := db.Exec(context.Background(), "select * from goldilocks")
 	require.NoError(t, err)
@@ -139,3 +142,487 @@ func TestConnBeginBrokenTxIsRolledBack(t *testing.T) {
 
 	ensurePgConnValid(t, pgConn)
 }
+
+func TestConnErrorIdentifiesConnection(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	config.RuntimeParams["application_name"] = "goldilocks_test"
+
+	pgConn, err := pgconn.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "select 1 / 0")
+	require.Error(t, err)
+
+	var connErr *goldilocks.ConnError
+	require.True(t, errors.As(err, &connErr))
+	require.Equal(t, pgConn.Conn().RemoteAddr().String(), connErr.Host)
+	require.Equal(t, "", connErr.Database)
+	require.Equal(t, pgConn.PID(), connErr.PID)
+	require.Equal(t, "goldilocks_test", connErr.ApplicationName)
+
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr))
+	require.Equal(t, pgerrcode.DivisionByZero, pgErr.Code)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestSerializedConnConcurrentUse(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewSerializedConn(pgConn)
+
+	errChan := make(chan error)
+	for i := 0; i < 5; i++ {
+		go func(i int) {
+			if i%2 == 0 {
+				_, err := db.Exec(context.Background(), "select pg_sleep(0.05)")
+				errChan <- err
+				return
+			}
+
+			errChan <- db.Begin(context.Background(), func(db goldilocks.StdDB) error {
+				var n int32
+				_, err := db.Query(context.Background(), "select $1::int4", []interface{}{int32(i)}, []interface{}{&n}, func() error { return nil })
+				if err != nil {
+					return err
+				}
+				if n != int32(i) {
+					return fmt.Errorf("expected %d, got %d", i, n)
+				}
+				return nil
+			})
+		}(i)
+	}
+	for i := 0; i < 5; i++ {
+		require.NoError(t, <-errChan)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = db.Begin(context.Background(), func(tx goldilocks.StdDB) error {
+		_, err := db.Exec(ctx, "select 1")
+		return err
+	})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestConnPing(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	db := goldilocks.NewConn(pgConn)
+
+	require.NoError(t, db.Ping(context.Background()))
+	ensurePgConnValid(t, pgConn)
+
+	closePgConn(t, pgConn)
+	require.Error(t, db.Ping(context.Background()))
+}
+
+// testACLItem prefers the binary format but aclitem only has a text format.
+type testACLItem string
+
+func (*testACLItem) ResultFormat() int16 {
+	return 1
+}
+
+func (*testACLItem) DecodeResult(buf []byte) error {
+	return errors.New("binary format not expected")
+}
+
+func (a *testACLItem) DecodeTextResult(buf []byte) error {
+	*a = testACLItem(buf)
+	return nil
+}
+
+func TestConnQueryTextResultFallback(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	sql := "select $1::int4, (acldefault('r', (select oid from pg_roles where rolname = current_user)))[1]"
+
+	var n int32
+	var aclItem testACLItem
+	rowCount, err := db.Query(context.Background(), sql, []interface{}{int32(42)}, []interface{}{&n, &aclItem}, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 1, rowCount)
+	require.EqualValues(t, 42, n)
+	require.Regexp(t, `^\w+=\w+/\w+$`, string(aclItem))
+
+	err = db.Begin(context.Background(), func(db goldilocks.StdDB) error {
+		_, err := db.Query(context.Background(), sql, []interface{}{int32(42)}, []interface{}{&n, &aclItem}, func() error { return nil })
+		var pgErr *pgconn.PgError
+		require.True(t, errors.As(err, &pgErr))
+		require.Equal(t, pgerrcode.UndefinedFunction, pgErr.Code)
+		return nil
+	})
+	require.True(t, errors.Is(err, goldilocks.ErrTxFailed))
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestConnConfigTextResults(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{TextResults: true})
+
+	sql := "select $1::int2, $2::int4, $3::int8, $4::float4, $5::float8, $6::bool, null::int8, (acldefault('r', (select oid from pg_roles where rolname = current_user)))[1]"
+	args := []interface{}{int16(-1), int32(42), int64(1) << 40, float32(1.5), float64(-2.25), true}
+
+	// In a transaction the text format is used from the start so no fallback is needed.
+	err = db.Begin(context.Background(), func(db goldilocks.StdDB) error {
+		var i16 int16
+		var i32 int32
+		var i64 int64
+		var f32 float32
+		var f64 float64
+		var b bool
+		var null goldilocks.NullInt64
+		var aclItem testACLItem
+		_, err := db.Query(
+			context.Background(),
+			sql,
+			args,
+			[]interface{}{&i16, &i32, &i64, &f32, &f64, &b, &null, &aclItem},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.EqualValues(t, -1, i16)
+		require.EqualValues(t, 42, i32)
+		require.EqualValues(t, int64(1)<<40, i64)
+		require.EqualValues(t, 1.5, f32)
+		require.EqualValues(t, -2.25, f64)
+		require.True(t, b)
+		require.Equal(t, goldilocks.NullInt64{}, null)
+		require.Regexp(t, `^\w+=\w+/\w+$`, string(aclItem))
+		return nil
+	})
+	require.NoError(t, err)
+
+	var n goldilocks.NullInt32
+	var aclItem testACLItem
+	err = db.Begin(context.Background(), func(db goldilocks.StdDB) error {
+		_, err := db.Query(
+			context.Background(),
+			"select 1::int4, (acldefault('r', (select oid from pg_roles where rolname = current_user)))[1]",
+			nil,
+			[]interface{}{goldilocks.BinaryResult(&n), goldilocks.BinaryResult(&aclItem)},
+			func() error { return nil },
+		)
+		var pgErr *pgconn.PgError
+		require.True(t, errors.As(err, &pgErr))
+		require.Equal(t, pgerrcode.UndefinedFunction, pgErr.Code)
+		return nil
+	})
+	require.True(t, errors.Is(err, goldilocks.ErrTxFailed))
+
+	_, err = goldilocks.NewConn(pgConn).Query(
+		context.Background(),
+		"select 7::int4",
+		nil,
+		[]interface{}{goldilocks.TextResult(&n)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.NullInt32{Value: 7, Valid: true}, n)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+type nonEmptyString string
+
+func (s nonEmptyString) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if s == "" {
+		return nil, 0, 0, errors.New("string must not be empty")
+	}
+	return append(buf, s...), 25, 0, nil
+}
+
+type intResult struct {
+	dst *int
+	n   goldilocks.NullInt64
+}
+
+func (*intResult) ResultFormat() int16 {
+	return 1
+}
+
+func (r *intResult) DecodeResult(buf []byte) error {
+	err := r.n.DecodeResult(buf)
+	if err != nil {
+		return err
+	}
+	*r.dst = int(r.n.Value)
+	return nil
+}
+
+type testScanner struct {
+	src interface{}
+}
+
+func (s *testScanner) Scan(src interface{}) error {
+	s.src = src
+	return nil
+}
+
+func TestConnQuerySQLScannerResult(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var a, b testScanner
+	_, err = db.Query(context.Background(), "select '1.5'::numeric, null::text", nil, []interface{}{&a, &b}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, "1.5", a.src)
+	require.Nil(t, b.src)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestConnConfigErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+
+	errDuplicate := errors.New("duplicate")
+	var handled []error
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{
+		ErrorHandler: func(err error) error {
+			handled = append(handled, err)
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return errDuplicate
+			}
+			return err
+		},
+	})
+
+	_, err = db.Exec(context.Background(), "create temporary table t (id int4 primary key)")
+	require.NoError(t, err)
+	require.Empty(t, handled)
+
+	_, err = db.Exec(context.Background(), "insert into t (id) values (1), (1)")
+	require.Equal(t, errDuplicate, err)
+
+	_, err = db.Query(context.Background(), "insert into t (id) values (2), (2) returning id", nil, []interface{}{nil}, func() error { return nil })
+	require.Equal(t, errDuplicate, err)
+	require.Len(t, handled, 2)
+
+	// The error returned by f is not handled again.
+	err = db.Begin(context.Background(), func(db goldilocks.StdDB) error {
+		_, err := db.Exec(context.Background(), "insert into t (id) values (3), (3)")
+		return err
+	})
+	require.Equal(t, errDuplicate, err)
+	require.Len(t, handled, 3)
+
+	err = db.Begin(context.Background(), func(db goldilocks.StdDB) error {
+		db.Exec(context.Background(), "insert into t (id) values (4), (4)")
+		return nil
+	})
+	require.True(t, errors.Is(err, goldilocks.ErrTxFailed))
+	require.Len(t, handled, 5)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestConnConfigQueryRewriter(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+
+	errRejected := errors.New("rejected")
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{
+		QueryRewriter: func(ctx context.Context, sql string, args []interface{}) (string, []interface{}, error) {
+			if strings.Contains(sql, "drop") {
+				return "", nil, errRejected
+			}
+			return sql + " + $" + strconv.Itoa(len(args)+1), append(args, int32(10)), nil
+		},
+	})
+
+	var n int32
+	_, err = db.Query(context.Background(), "select $1::int4", []interface{}{int32(1)}, []interface{}{&n}, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 11, n)
+
+	rowsAffected, err := db.Exec(context.Background(), "select 1")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, rowsAffected)
+
+	_, err = db.Exec(context.Background(), "drop table goldilocks_missing")
+	require.Equal(t, errRejected, err)
+
+	// Prepared statements are not rewritten.
+	_, err = db.Prepare(context.Background(), "ps", "select $1::int4")
+	require.NoError(t, err)
+	_, err = db.QueryPrepared(context.Background(), "ps", []interface{}{int32(1)}, []interface{}{&n}, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestConnConfigResolvers(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{
+		ParamResolvers: []goldilocks.ParamResolver{
+			func(arg interface{}) (goldilocks.ParamEncoder, bool) {
+				if s, ok := arg.(string); ok {
+					return nonEmptyString(s), true
+				}
+				return nil, false
+			},
+			func(arg interface{}) (goldilocks.ParamEncoder, bool) {
+				if n, ok := arg.(int); ok {
+					return goldilocks.NullInt64{Value: int64(n), Valid: true}, true
+				}
+				return nil, false
+			},
+		},
+		ResultResolvers: []goldilocks.ResultResolver{
+			func(dst interface{}) (goldilocks.ResultDecoder, bool) {
+				if n, ok := dst.(*int); ok {
+					return &intResult{dst: n}, true
+				}
+				return nil, false
+			},
+		},
+	})
+
+	var s string
+	var n int
+	_, err = db.Query(
+		context.Background(),
+		"select $1::text, $2::int8 + 1",
+		[]interface{}{"foo", 41},
+		[]interface{}{&s, &n},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, "foo", s)
+	require.Equal(t, 42, n)
+
+	_, err = db.Exec(context.Background(), "select $1::text", "")
+	require.EqualError(t, err, "cannot encode args[0] (string): string must not be empty")
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestConnStats(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	require.Equal(t, goldilocks.ConnStats{}, db.Stats())
+
+	before := time.Now()
+	_, err = db.Exec(context.Background(), "select pg_sleep(0.01)")
+	require.NoError(t, err)
+	err = db.Begin(context.Background(), func(db goldilocks.StdDB) error {
+		_, err := db.Query(context.Background(), "select 1", nil, nil, func() error { return nil })
+		return err
+	})
+	require.NoError(t, err)
+
+	stats := db.Stats()
+	require.EqualValues(t, 2, stats.QueryCount)
+	require.True(t, stats.BusyDuration >= 10*time.Millisecond)
+	require.True(t, stats.BusyDuration <= time.Since(before))
+	require.False(t, stats.LastUsed.Before(before))
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestExecExpect(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "create temporary table goldilocks (a int4)")
+	require.NoError(t, err)
+
+	err = goldilocks.ExecExpect(context.Background(), db, 2, "insert into goldilocks (a) values ($1), ($1)", int32(1))
+	require.NoError(t, err)
+
+	err = db.Begin(context.Background(), func(db goldilocks.StdDB) error {
+		return goldilocks.ExecExpect(context.Background(), db, 1, "update goldilocks set a = $1", int32(2))
+	})
+	require.EqualError(t, err, "expected 1 rows affected, got 2")
+	var rowCountErr *goldilocks.UnexpectedRowCountError
+	require.True(t, errors.As(err, &rowCountErr))
+	require.EqualValues(t, 1, rowCountErr.Expected)
+	require.EqualValues(t, 2, rowCountErr.Actual)
+
+	var sum int64
+	_, err = db.Query(context.Background(), "select sum(a)::int8 from goldilocks", nil, []interface{}{&sum}, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 2, sum)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestConnQueryResultCountMismatch(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var a, b int32
+	_, err = db.Query(context.Background(), "select 1::int4 as a, 2::int4 as b, 3::int4 as c", nil, []interface{}{&a, &b}, func() error { return nil })
+	require.EqualError(t, err, `query returned 3 columns but 2 results were given; columns without results: "c"`)
+
+	_, err = db.Query(context.Background(), "select 1::int4 as a, 2::int4 as b", nil, []interface{}{&a}, func() error { return nil })
+	require.EqualError(t, err, `query returned 2 columns but 1 results were given; columns without results: "b"`)
+
+	_, err = db.Query(context.Background(), "select 1::int4 as a", nil, []interface{}{&a, &b}, func() error { return nil })
+	require.EqualError(t, err, `query returned 1 columns but 2 results were given; results[1:] have no columns`)
+
+	_, err = db.Query(goldilocks.WithSimpleProtocol(context.Background()), "select 1::int4 as a, 2::int4 as b", nil, []interface{}{&a}, func() error { return nil })
+	require.EqualError(t, err, `query returned 2 columns but 1 results were given; columns without results: "b"`)
+
+	_, err = db.Prepare(context.Background(), "result_count", "select 1::int4 as a")
+	require.NoError(t, err)
+	_, err = db.QueryPrepared(context.Background(), "result_count", nil, []interface{}{&a, &b}, func() error { return nil })
+	require.EqualError(t, err, `query returned 1 columns but 2 results were given; results[1:] have no columns`)
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,228 @@
+package goldilocks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgio"
+)
+
+// CopyFromSource is a source of rows for CopyFrom.
+type CopyFromSource interface {
+	// Next advances to the next row. It returns false when there are no more rows or an error occurred.
+	Next() bool
+
+	// Values returns the values of the current row. Each value is encoded as if it were a query param.
+	Values() ([]interface{}, error)
+
+	// Err returns the error, if any, that stopped Next.
+	Err() error
+}
+
+// CopyFromRows returns a CopyFromSource of rows.
+func CopyFromRows(rows [][]interface{}) CopyFromSource {
+	return &copyFromRows{rows: rows, idx: -1}
+}
+
+type copyFromRows struct {
+	rows [][]interface{}
+	idx  int
+}
+
+func (r *copyFromRows) Next() bool {
+	r.idx++
+	return r.idx < len(r.rows)
+}
+
+func (r *copyFromRows) Values() ([]interface{}, error) {
+	return r.rows[r.idx], nil
+}
+
+func (r *copyFromRows) Err() error {
+	return nil
+}
+
+// CopyFromSlice returns a CopyFromSource of n rows. next is called with the index of each row to get its values. This
+// allows copying a slice of any type, such as a slice of structs, without building all rows first.
+func CopyFromSlice(n int, next func(i int) ([]interface{}, error)) CopyFromSource {
+	return &copyFromSlice{n: n, next: next, idx: -1}
+}
+
+type copyFromSlice struct {
+	n    int
+	next func(i int) ([]interface{}, error)
+	idx  int
+}
+
+func (s *copyFromSlice) Next() bool {
+	s.idx++
+	return s.idx < s.n
+}
+
+func (s *copyFromSlice) Values() ([]interface{}, error) {
+	return s.next(s.idx)
+}
+
+func (s *copyFromSlice) Err() error {
+	return nil
+}
+
+// copyFromBufferSize is the size of the chunks of COPY data sent to the server.
+const copyFromBufferSize = 65536
+
+// binaryCopySignature starts the header of the binary COPY format.
+var binaryCopySignature = []byte("PGCOPY\n\377\r\n\000")
+
+// CopyFrom inserts the rows of src into columns of table with COPY FROM STDIN in the binary format. It returns the
+// number of rows copied. This is much faster than inserting rows individually. Use InsertUnnest if the inserted rows
+// need to be returned.
+//
+// Each value must be encoded in the binary format as the exact type of its column. Strings can only be copied into
+// string columns such as text and varchar. The column types are looked up before copying so a mismatch is reported as
+// an error rather than being misinterpreted by the server. If an error occurs no rows are copied.
+func (c *Conn) CopyFrom(ctx context.Context, table Ident, columns []string, src CopyFromSource) (int64, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("CopyFrom requires at least one column")
+	}
+
+	err := c.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer c.release()
+
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = Identifier(col).Sanitize()
+	}
+	columnList := strings.Join(names, ", ")
+	copySQL := fmt.Sprintf("copy %s (%s) from stdin binary", table.Sanitize(), columnList)
+
+	ctx, endTrace := c.startTrace(ctx, TraceData{Op: TraceCopyFrom, SQL: copySQL})
+	rowCount, err := c.copyFrom(ctx, table, columnList, copySQL, src)
+	endTrace(rowCount, err)
+	return rowCount, err
+}
+
+// copyFrom copies the rows of src into columnList of table with copySQL.
+func (c *Conn) copyFrom(ctx context.Context, table Ident, columnList string, copySQL string, src CopyFromSource) (int64, error) {
+	sd, err := c.pgconn.Prepare(ctx, "", fmt.Sprintf("select %s from %s", columnList, table.Sanitize()), nil)
+	if err != nil {
+		return 0, c.connError(err)
+	}
+	columnOIDs := make([]uint32, len(sd.Fields))
+	for i, f := range sd.Fields {
+		columnOIDs[i] = f.DataTypeOID
+	}
+
+	r, w := io.Pipe()
+	var encodeErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		encodeErr = encodeCopyFromRows(w, columnOIDs, src)
+		if encodeErr != nil {
+			w.CloseWithError(encodeErr)
+		} else {
+			w.Close()
+		}
+	}()
+
+	commandTag, err := c.pgconn.CopyFrom(ctx, r, copySQL)
+	// Closing the reader stops the encoder if the copy ended before all rows were read.
+	r.Close()
+	<-done
+
+	if encodeErr != nil && encodeErr != io.ErrClosedPipe {
+		return 0, encodeErr
+	}
+	if err != nil {
+		return 0, c.connError(err)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// CopyTo executes sql, which must be a COPY ... TO STDOUT statement, and writes the output to w as it is received. It
+// returns the number of rows copied. If an error occurs after some output has been written the output is incomplete.
+func (c *Conn) CopyTo(ctx context.Context, w io.Writer, sql string) (int64, error) {
+	err := c.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer c.release()
+
+	commandTag, err := c.pgconn.CopyTo(ctx, w, sql)
+	if err != nil {
+		return 0, c.connError(err)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// encodeCopyFromRows writes the rows of src to w in the binary COPY format.
+func encodeCopyFromRows(w io.Writer, columnOIDs []uint32, src CopyFromSource) error {
+	buf := make([]byte, 0, copyFromBufferSize)
+	buf = append(buf, binaryCopySignature...)
+	buf = pgio.AppendInt32(buf, 0) // flags
+	buf = pgio.AppendInt32(buf, 0) // header extension length
+
+	for row := 0; src.Next(); row++ {
+		values, err := src.Values()
+		if err != nil {
+			return err
+		}
+		if len(values) != len(columnOIDs) {
+			return fmt.Errorf("row %d has %d values but there are %d columns", row, len(values), len(columnOIDs))
+		}
+
+		buf = pgio.AppendInt16(buf, int16(len(values)))
+		for i, v := range values {
+			sp := len(buf)
+			buf = pgio.AppendInt32(buf, -1)
+
+			value, oid, format, err := encodeParam(buf, v)
+			if err == errUnsupportedType {
+				return fmt.Errorf("row %d column %d is unsupported type %T", row, i, v)
+			}
+			if err != nil {
+				return fmt.Errorf("row %d column %d: %w", row, i, err)
+			}
+			if value == nil {
+				continue
+			}
+			if !copyFromCompatible(oid, format, columnOIDs[i]) {
+				return fmt.Errorf("row %d column %d: %T cannot be copied into a column of type oid %d", row, i, v, columnOIDs[i])
+			}
+
+			buf = value
+			pgio.SetInt32(buf[sp:], int32(len(buf[sp:])-4))
+		}
+
+		if len(buf) >= copyFromBufferSize {
+			_, err := w.Write(buf)
+			if err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+	if err := src.Err(); err != nil {
+		return err
+	}
+
+	buf = pgio.AppendInt16(buf, -1) // trailer
+	_, err := w.Write(buf)
+	return err
+}
+
+// copyFromCompatible reports whether a value encoded as type oid in format can be copied into a column of type
+// columnOID. Values without a type are assumed to be compatible.
+func copyFromCompatible(oid uint32, format int16, columnOID uint32) bool {
+	if format != binaryFormat {
+		return textIsBinaryFormat(columnOID)
+	}
+	return oid == 0 || oid == columnOID || (textIsBinaryFormat(oid) && textIsBinaryFormat(columnOID))
+}
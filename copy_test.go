@@ -0,0 +1,160 @@
+package goldilocks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+type failingCopyFromSource struct {
+	n int
+}
+
+func (s *failingCopyFromSource) Next() bool {
+	s.n++
+	return true
+}
+
+func (s *failingCopyFromSource) Values() ([]interface{}, error) {
+	if s.n > 3 {
+		return nil, errors.New("source failed")
+	}
+	return []interface{}{int32(s.n), "x"}, nil
+}
+
+func (s *failingCopyFromSource) Err() error {
+	return nil
+}
+
+func TestConnCopyFrom(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "create temporary table widgets (id int4, name varchar(20), created_at timestamptz, weight float8)")
+	require.NoError(t, err)
+
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := make([][]interface{}, 10000)
+	for i := range rows {
+		rows[i] = []interface{}{int32(i), fmt.Sprintf("widget %d", i), createdAt, goldilocks.NullFloat64{}}
+	}
+
+	rowCount, err := db.CopyFrom(
+		context.Background(),
+		goldilocks.Identifier("widgets"),
+		[]string{"id", "name", "created_at", "weight"},
+		goldilocks.CopyFromRows(rows),
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 10000, rowCount)
+
+	var count, idSum int64
+	var name string
+	_, err = db.Query(
+		context.Background(),
+		"select count(*), sum(id)::int8, max(name) from widgets where created_at = $1 and weight is null",
+		[]interface{}{createdAt},
+		[]interface{}{&count, &idSum, &name},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 10000, count)
+	require.EqualValues(t, 9999*10000/2, idSum)
+	require.Equal(t, "widget 9999", name)
+
+	_, err = db.CopyFrom(context.Background(), goldilocks.Identifier("widgets"), []string{"id", "name"}, &failingCopyFromSource{})
+	require.EqualError(t, err, "source failed")
+
+	_, err = db.CopyFrom(context.Background(), goldilocks.Identifier("widgets"), []string{"id"}, goldilocks.CopyFromRows([][]interface{}{{int64(1)}}))
+	require.EqualError(t, err, "row 0 column 0: int64 cannot be copied into a column of type oid 23")
+
+	_, err = db.CopyFrom(context.Background(), goldilocks.Identifier("widgets"), []string{"id", "name"}, goldilocks.CopyFromRows([][]interface{}{{int32(1)}}))
+	require.EqualError(t, err, "row 0 has 1 values but there are 2 columns")
+
+	_, err = db.CopyFrom(context.Background(), goldilocks.Identifier("widgets"), []string{"name"}, goldilocks.CopyFromRows([][]interface{}{{"much too long for the name column"}}))
+	require.Error(t, err)
+
+	_, err = db.Query(context.Background(), "select count(*) from widgets", nil, []interface{}{&count}, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 10000, count)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestCopyFromSlice(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "create temporary table people (name text, age int4)")
+	require.NoError(t, err)
+
+	type person struct {
+		Name string
+		Age  int32
+	}
+	people := []person{{"Alice", 30}, {"Bob", 25}}
+
+	rowCount, err := db.CopyFrom(
+		context.Background(),
+		goldilocks.Identifier("people"),
+		[]string{"name", "age"},
+		goldilocks.CopyFromSlice(len(people), func(i int) ([]interface{}, error) {
+			return []interface{}{people[i].Name, people[i].Age}, nil
+		}),
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, rowCount)
+
+	var names string
+	_, err = db.Query(context.Background(), "select string_agg(name || ' ' || age, ', ' order by name) from people", nil, []interface{}{&names}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, "Alice 30, Bob 25", names)
+
+	_, err = db.CopyFrom(
+		context.Background(),
+		goldilocks.Identifier("people"),
+		[]string{"name", "age"},
+		goldilocks.CopyFromSlice(len(people), func(i int) ([]interface{}, error) {
+			return nil, errors.New("invalid person")
+		}),
+	)
+	require.EqualError(t, err, "invalid person")
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestConnCopyTo(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	buf := &bytes.Buffer{}
+	rowCount, err := db.CopyTo(context.Background(), buf, "copy (select n, 'row ' || n from generate_series(1, 3) n) to stdout with (format csv)")
+	require.NoError(t, err)
+	require.EqualValues(t, 3, rowCount)
+	require.Equal(t, "1,row 1\n2,row 2\n3,row 3\n", buf.String())
+
+	_, err = db.CopyTo(context.Background(), buf, "copy (select 1/0) to stdout")
+	require.Error(t, err)
+
+	ensurePgConnValid(t, pgConn)
+}
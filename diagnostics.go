@@ -0,0 +1,140 @@
+package goldilocks
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+)
+
+// ProtocolMessage is a summary of a protocol message received from the server. The message payload is not retained so
+// no query results or other potentially sensitive data are exposed.
+type ProtocolMessage struct {
+	Type string // message type name (e.g. DataRow)
+	Len  int    // encoded length of the message in bytes including the type byte and length
+}
+
+func (m ProtocolMessage) String() string {
+	return fmt.Sprintf("%s (%d bytes)", m.Type, m.Len)
+}
+
+// EnableProtocolDiagnostics configures config so each connection retains a summary of the last n protocol messages it
+// received. When receiving a message fails for a reason other than a network timeout, such as an unknown or malformed
+// message from a misbehaving proxy or the connection being closed, the retained messages (oldest first) and the error
+// are passed to log.
+//
+// Diagnostics are intended for debugging. Recording the messages has a measurable cost.
+func EnableProtocolDiagnostics(config *pgconn.Config, n int, log func(messages []ProtocolMessage, err error)) {
+	if n < 1 {
+		panic("n must be greater than 0")
+	}
+
+	buildFrontend := config.BuildFrontend
+	config.BuildFrontend = func(r io.Reader, w io.Writer) pgconn.Frontend {
+		lr := &messageLengthReader{r: r}
+		return &diagnosticFrontend{
+			frontend: buildFrontend(lr, w),
+			lengths:  lr,
+			messages: make([]ProtocolMessage, 0, n),
+			log:      log,
+		}
+	}
+}
+
+// diagnosticFrontend wraps a pgconn.Frontend and records a summary of each message received in a ring buffer.
+type diagnosticFrontend struct {
+	frontend pgconn.Frontend
+	lengths  *messageLengthReader
+	messages []ProtocolMessage
+	next     int
+	log      func(messages []ProtocolMessage, err error)
+}
+
+func (f *diagnosticFrontend) Receive() (pgproto3.BackendMessage, error) {
+	msg, err := f.frontend.Receive()
+	if err != nil {
+		// A timeout is how pgconn interrupts a read when a context is done so it is not a protocol failure.
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			f.log(f.recentMessages(), err)
+		}
+		return nil, err
+	}
+
+	pm := ProtocolMessage{
+		Type: reflect.TypeOf(msg).Elem().Name(),
+		Len:  f.lengths.next(),
+	}
+
+	if len(f.messages) < cap(f.messages) {
+		f.messages = append(f.messages, pm)
+	} else {
+		f.messages[f.next] = pm
+		f.next = (f.next + 1) % len(f.messages)
+	}
+
+	return msg, nil
+}
+
+// recentMessages returns a copy of the recorded messages in the order they were received.
+func (f *diagnosticFrontend) recentMessages() []ProtocolMessage {
+	messages := make([]ProtocolMessage, 0, len(f.messages))
+	messages = append(messages, f.messages[f.next:]...)
+	messages = append(messages, f.messages[:f.next]...)
+	return messages
+}
+
+// messageLengthReader reads the messages received by a connection and records the length of each message from its
+// header. The frontend reads ahead so lengths are queued until the messages are received.
+type messageLengthReader struct {
+	r         io.Reader
+	header    [5]byte
+	headerLen int // the bytes of the header of the current message that have been read
+	bodyLen   int // the bytes of the body of the current message that have not been read
+	lengths   []int
+}
+
+func (lr *messageLengthReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+
+	buf := p[:n]
+	for len(buf) > 0 {
+		if lr.bodyLen > 0 {
+			skip := lr.bodyLen
+			if skip > len(buf) {
+				skip = len(buf)
+			}
+			lr.bodyLen -= skip
+			buf = buf[skip:]
+			continue
+		}
+
+		copied := copy(lr.header[lr.headerLen:], buf)
+		lr.headerLen += copied
+		buf = buf[copied:]
+		if lr.headerLen == len(lr.header) {
+			// The length includes itself but not the type byte.
+			length := int(int32(binary.BigEndian.Uint32(lr.header[1:])))
+			lr.lengths = append(lr.lengths, 1+length)
+			lr.bodyLen = length - 4
+			lr.headerLen = 0
+		}
+	}
+
+	return n, err
+}
+
+// next returns the length of the next message to be received.
+func (lr *messageLengthReader) next() int {
+	if len(lr.lengths) == 0 {
+		return 0
+	}
+	length := lr.lengths[0]
+	lr.lengths = lr.lengths[1:]
+	return length
+}
@@ -0,0 +1,84 @@
+package goldilocks_test
+
+import (
+	"bytes"
+	"testing"
+	"testing/iotest"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableProtocolDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig("")
+	require.NoError(t, err)
+
+	var loggedMessages []goldilocks.ProtocolMessage
+	var loggedErr error
+	goldilocks.EnableProtocolDiagnostics(config, 2, func(messages []goldilocks.ProtocolMessage, err error) {
+		loggedMessages = messages
+		loggedErr = err
+	})
+
+	var stream []byte
+	stream = append(stream, 'Z', 0, 0, 0, 5, 'I')                                        // ReadyForQuery
+	stream = append(stream, 'C', 0, 0, 0, 13, 'S', 'E', 'L', 'E', 'C', 'T', ' ', '1', 0) // CommandComplete
+	stream = append(stream, 'Z', 0, 0, 0, 5, 'T')                                        // ReadyForQuery
+	stream = append(stream, '~', 0, 0, 0, 4)                                             // unknown message type
+
+	// Messages are split across reads to check that their lengths are still measured correctly.
+	frontend := config.BuildFrontend(iotest.OneByteReader(bytes.NewReader(stream)), &bytes.Buffer{})
+	for i := 0; i < 3; i++ {
+		_, err := frontend.Receive()
+		require.NoError(t, err)
+	}
+	require.Nil(t, loggedMessages)
+
+	_, err = frontend.Receive()
+	require.Error(t, err)
+	require.Equal(t, err, loggedErr)
+	require.Equal(t,
+		[]goldilocks.ProtocolMessage{
+			{Type: "CommandComplete", Len: 14},
+			{Type: "ReadyForQuery", Len: 6},
+		},
+		loggedMessages,
+	)
+}
+
+type diagnosticsTimeoutError struct{}
+
+func (diagnosticsTimeoutError) Error() string   { return "i/o timeout" }
+func (diagnosticsTimeoutError) Timeout() bool   { return true }
+func (diagnosticsTimeoutError) Temporary() bool { return true }
+
+type diagnosticsTimeoutReader struct{}
+
+func (diagnosticsTimeoutReader) Read(p []byte) (int, error) { return 0, diagnosticsTimeoutError{} }
+
+func TestEnableProtocolDiagnosticsReadErrors(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig("")
+	require.NoError(t, err)
+
+	var loggedErrs []error
+	goldilocks.EnableProtocolDiagnostics(config, 2, func(messages []goldilocks.ProtocolMessage, err error) {
+		loggedErrs = append(loggedErrs, err)
+	})
+
+	// A timeout is not logged.
+	frontend := config.BuildFrontend(diagnosticsTimeoutReader{}, &bytes.Buffer{})
+	_, err = frontend.Receive()
+	require.Error(t, err)
+	require.Empty(t, loggedErrs)
+
+	// The connection being closed in the middle of a message is logged.
+	frontend = config.BuildFrontend(bytes.NewReader([]byte{'Z', 0, 0}), &bytes.Buffer{})
+	_, err = frontend.Receive()
+	require.Error(t, err)
+	require.Len(t, loggedErrs, 1)
+}
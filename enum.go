@@ -0,0 +1,104 @@
+package goldilocks
+
+import (
+	"context"
+	"fmt"
+)
+
+// EnumType is a PostgreSQL enum type loaded with LoadEnumType. It validates labels sent to and received from the server.
+// It is safe for concurrent use.
+type EnumType struct {
+	Name   string
+	OID    uint32
+	Labels []string // in sort order
+
+	labels map[string]struct{}
+}
+
+// LoadEnumType loads the enum type name from the database. name may be schema qualified.
+func LoadEnumType(ctx context.Context, db StdDB, name string) (*EnumType, error) {
+	et := &EnumType{Name: name, labels: make(map[string]struct{})}
+
+	var oid int64
+	var label string
+	_, err := db.Query(
+		ctx,
+		`select t.oid::int8, e.enumlabel::text
+from pg_type t
+  join pg_enum e on e.enumtypid = t.oid
+where t.oid = $1::regtype
+order by e.enumsortorder`,
+		[]interface{}{name},
+		[]interface{}{&oid, &label},
+		func() error {
+			et.OID = uint32(oid)
+			et.Labels = append(et.Labels, label)
+			et.labels[label] = struct{}{}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(et.Labels) == 0 {
+		return nil, fmt.Errorf("%s is not an enum type or has no labels", name)
+	}
+
+	return et, nil
+}
+
+// IsValid reports whether label is a label of et.
+func (et *EnumType) IsValid(label string) bool {
+	_, ok := et.labels[label]
+	return ok
+}
+
+// Param returns a query param of type et for label. Encoding fails if label is not a label of et.
+func (et *EnumType) Param(label string) ParamEncoder {
+	return enumParam{et: et, label: label}
+}
+
+// Result returns a result decoder that stores a label of et in dst. Use a conversion such as (*string)(&v) for a string
+// based Go type. Decoding fails if the label is not a label of et, such as when a label was added after et was loaded.
+// Decode into a *string directly to skip validation.
+func (et *EnumType) Result(dst *string) ResultDecoder {
+	return enumResult{et: et, dst: dst}
+}
+
+type enumParam struct {
+	et    *EnumType
+	label string
+}
+
+func (p enumParam) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if !p.et.IsValid(p.label) {
+		return nil, p.et.OID, 0, fmt.Errorf("%q is not a label of %s", p.label, p.et.Name)
+	}
+
+	buf = append(buf, p.label...)
+	return buf, p.et.OID, textFormat, nil
+}
+
+type enumResult struct {
+	et  *EnumType
+	dst *string
+}
+
+func (enumResult) ResultFormat() int16 {
+	return textFormat
+}
+
+func (r enumResult) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return fmt.Errorf("NULL cannot be converted to %s", r.et.Name)
+	}
+
+	label := string(buf)
+	if !r.et.IsValid(label) {
+		return fmt.Errorf("%q is not a label of %s", label, r.et.Name)
+	}
+
+	*r.dst = label
+	return nil
+}
@@ -0,0 +1,60 @@
+package goldilocks_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+type mood string
+
+const moodHappy mood = "happy"
+
+func TestEnumType(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "create type pg_temp.mood as enum ('sad', 'ok', 'happy')")
+	require.NoError(t, err)
+
+	moodType, err := goldilocks.LoadEnumType(context.Background(), db, "pg_temp.mood")
+	require.NoError(t, err)
+	require.NotZero(t, moodType.OID)
+	require.Equal(t, []string{"sad", "ok", "happy"}, moodType.Labels)
+
+	var m mood
+	var isHappy bool
+	_, err = db.Query(
+		context.Background(),
+		"select $1, $1 = 'happy'::pg_temp.mood",
+		[]interface{}{moodType.Param(string(moodHappy))},
+		[]interface{}{moodType.Result((*string)(&m)), &isHappy},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, moodHappy, m)
+	require.True(t, isHappy)
+
+	_, err = db.Exec(context.Background(), "select $1", moodType.Param("angry"))
+	require.EqualError(t, err, fmt.Sprintf(`cannot encode args[0] (goldilocks.enumParam as type oid %d): "angry" is not a label of pg_temp.mood`, moodType.OID))
+
+	_, err = db.Exec(context.Background(), "alter type pg_temp.mood add value 'angry'")
+	require.NoError(t, err)
+
+	_, err = db.Query(context.Background(), "select 'angry'::pg_temp.mood", nil, []interface{}{moodType.Result((*string)(&m))}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "mood"): "angry" is not a label of pg_temp.mood`)
+
+	_, err = goldilocks.LoadEnumType(context.Background(), db, "int4")
+	require.EqualError(t, err, "int4 is not an enum type or has no labels")
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,233 @@
+package goldilocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+)
+
+// ErrorClass returns the two character SQLSTATE class of the PostgreSQL error in err's chain. e.g. "23" for an
+// integrity constraint violation. It returns "" if err does not contain a *pgconn.PgError.
+func ErrorClass(err error) string {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || len(pgErr.Code) < 2 {
+		return ""
+	}
+	return pgErr.Code[:2]
+}
+
+// transientErrorCodes are the SQLSTATE codes of server errors that are caused by concurrent activity or server
+// availability rather than by the statement itself.
+var transientErrorCodes = map[string]struct{}{
+	pgerrcode.SerializationFailure: {},
+	pgerrcode.DeadlockDetected:     {},
+	pgerrcode.LockNotAvailable:     {},
+	pgerrcode.TooManyConnections:   {},
+	pgerrcode.AdminShutdown:        {},
+	pgerrcode.CrashShutdown:        {},
+	pgerrcode.CannotConnectNow:     {},
+}
+
+// IsTransient reports whether err is likely to succeed if the entire operation is retried. This is true for
+// serialization failures, deadlocks, lock timeouts, connection exceptions (class 08), server shutdowns, and errors that
+// pgconn reports as safe to retry because nothing was sent to the server.
+//
+// A transient error inside a transaction means the transaction must be retried from the beginning. IsTransient does not
+// consider whether a statement is idempotent. e.g. a connection lost while a commit was in progress is transient but the
+// commit may have succeeded.
+func IsTransient(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if pgerrcode.IsConnectionException(pgErr.Code) {
+			return true
+		}
+		_, ok := transientErrorCodes[pgErr.Code]
+		return ok
+	}
+
+	var safeToRetryErr interface{ SafeToRetry() bool }
+	if errors.As(err, &safeToRetryErr) {
+		return safeToRetryErr.SafeToRetry()
+	}
+
+	return false
+}
+
+// ConnError wraps an error reported by the server or the network while executing a statement with the identity of the
+// connection that produced it. Use errors.As to access the underlying error such as a *pgconn.PgError.
+type ConnError struct {
+	Host            string // network address of the server the connection is connected to
+	Database        string // empty when not known, such as for a Conn created by NewConn
+	PID             uint32 // backend process ID
+	ApplicationName string
+	Err             error
+}
+
+func (e *ConnError) Error() string {
+	return fmt.Sprintf("%v (host=%s database=%s pid=%d application_name=%s)", e.Err, e.Host, e.Database, e.PID, e.ApplicationName)
+}
+
+func (e *ConnError) Unwrap() error {
+	return e.Err
+}
+
+// connError wraps err with the identity of c.
+func (c *Conn) connError(err error) error {
+	var host string
+	if netConn := c.pgconn.Conn(); netConn != nil {
+		host = netConn.RemoteAddr().String()
+	}
+
+	return &ConnError{
+		Host:            host,
+		Database:        c.database,
+		PID:             c.pgconn.PID(),
+		ApplicationName: c.pgconn.ParameterStatus("application_name"),
+		Err:             err,
+	}
+}
+
+// DecodeError is returned by Query when a result cannot decode the value of its column.
+type DecodeError struct {
+	Index int    // the zero-based index of the result and its column
+	Name  string // the name of the column if known
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("cannot decode results[%d]: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("cannot decode results[%d] (column %q): %v", e.Index, e.Name, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// EncodeError is returned when an arg cannot be encoded as a param.
+type EncodeError struct {
+	Index int    // the zero-based index of the arg
+	Type  string // the Go type of the arg
+	OID   uint32 // the oid of the param type if known
+	Err   error
+}
+
+func (e *EncodeError) Error() string {
+	if e.OID == 0 {
+		return fmt.Sprintf("cannot encode args[%d] (%s): %v", e.Index, e.Type, e.Err)
+	}
+	return fmt.Sprintf("cannot encode args[%d] (%s as type oid %d): %v", e.Index, e.Type, e.OID, e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrTxFailed is returned by Begin when f returned nil but an error had already caused the transaction to fail. The
+// transaction was rolled back.
+var ErrTxFailed = errors.New("rolled back failed transaction")
+
+// CommitUnknownError is returned by Begin when the commit was sent to the server but the connection failed before the
+// result was received. The transaction may or may not have been committed.
+type CommitUnknownError struct {
+	Err error
+}
+
+func (e *CommitUnknownError) Error() string {
+	return fmt.Sprintf("commit status unknown: %v", e.Err)
+}
+
+func (e *CommitUnknownError) Unwrap() error {
+	return e.Err
+}
+
+// UnexpectedRowCountError is returned by ExecExpect when the number of rows affected is not the expected number.
+type UnexpectedRowCountError struct {
+	Expected int64
+	Actual   int64
+}
+
+func (e *UnexpectedRowCountError) Error() string {
+	return fmt.Sprintf("expected %d rows affected, got %d", e.Expected, e.Actual)
+}
+
+// SQLError wraps an error the server reported at a position in the SQL of a statement with the SQL and the types of
+// the params. Its message includes the SQL around the position. Use errors.As to access the underlying
+// *pgconn.PgError. Errors of statements with params sent with the simple protocol are not wrapped because the params are
+// part of the SQL the server reports the position in.
+type SQLError struct {
+	SQL        string
+	ParamTypes []string // Go types of the params, e.g. "int32"
+	Err        error
+}
+
+// sqlErrorWindow is the number of characters of SQL on each side of the error position included in SQLError messages.
+const sqlErrorWindow = 30
+
+func (e *SQLError) Error() string {
+	var position int
+	var pgErr *pgconn.PgError
+	if errors.As(e.Err, &pgErr) {
+		position = int(pgErr.Position)
+	}
+
+	// Position counts characters from 1.
+	sql := []rune(e.SQL)
+	start := position - 1 - sqlErrorWindow
+	if start < 0 {
+		start = 0
+	}
+	end := position - 1 + sqlErrorWindow
+	if end > len(sql) {
+		end = len(sql)
+	}
+	if start > end {
+		start = end
+	}
+	window := string(sql[start:end])
+	if start > 0 {
+		window = "..." + window
+	}
+	if end < len(sql) {
+		window += "..."
+	}
+
+	return fmt.Sprintf("%v (at position %d of %q, param types [%s])", e.Err, position, window, strings.Join(e.ParamTypes, ", "))
+}
+
+func (e *SQLError) Unwrap() error {
+	return e.Err
+}
+
+// sqlError wraps err in a *SQLError if it contains a *pgconn.PgError with a position in sql. If args were interpolated
+// into sql for the simple protocol err is not wrapped. The server reports the position in the interpolated SQL rather
+// than in sql, and the interpolated SQL contains the values of args.
+func (c *Conn) sqlError(ctx context.Context, err error, sql string, stmt *Statement, args []interface{}) error {
+	if stmt == nil && len(args) > 0 && c.useSimpleProtocol(ctx) {
+		return err
+	}
+	return sqlError(err, sql, args)
+}
+
+// sqlError wraps err in a *SQLError if it contains a *pgconn.PgError with a position in sql.
+func sqlError(err error, sql string, args []interface{}) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Position == 0 {
+		return err
+	}
+	var sqlErr *SQLError
+	if errors.As(err, &sqlErr) {
+		return err
+	}
+
+	paramTypes := make([]string, len(args))
+	for i, arg := range args {
+		paramTypes[i] = fmt.Sprintf("%T", arg)
+	}
+	return &SQLError{SQL: sql, ParamTypes: paramTypes, Err: err}
+}
@@ -0,0 +1,149 @@
+package goldilocks_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/stretchr/testify/require"
+)
+
+type safeToRetryError struct{}
+
+func (safeToRetryError) Error() string     { return "safe to retry" }
+func (safeToRetryError) SafeToRetry() bool { return true }
+
+func TestErrorClassAndIsTransient(t *testing.T) {
+	t.Parallel()
+
+	for i, tt := range []struct {
+		err       error
+		class     string
+		transient bool
+	}{
+		{err: &pgconn.PgError{Code: pgerrcode.SerializationFailure}, class: "40", transient: true},
+		{err: &pgconn.PgError{Code: pgerrcode.DeadlockDetected}, class: "40", transient: true},
+		{err: &pgconn.PgError{Code: pgerrcode.LockNotAvailable}, class: "55", transient: true},
+		{err: &pgconn.PgError{Code: pgerrcode.ConnectionFailure}, class: "08", transient: true},
+		{err: &pgconn.PgError{Code: pgerrcode.AdminShutdown}, class: "57", transient: true},
+		{err: &pgconn.PgError{Code: pgerrcode.QueryCanceled}, class: "57", transient: false},
+		{err: &pgconn.PgError{Code: pgerrcode.UniqueViolation}, class: "23", transient: false},
+		{err: &pgconn.PgError{Code: pgerrcode.DivisionByZero}, class: "22", transient: false},
+		{err: fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: pgerrcode.SerializationFailure}), class: "40", transient: true},
+		{err: fmt.Errorf("wrapped: %w", safeToRetryError{}), class: "", transient: true},
+		{err: context.DeadlineExceeded, class: "", transient: false},
+		{err: errors.New("other"), class: "", transient: false},
+		{err: nil, class: "", transient: false},
+	} {
+		require.Equalf(t, tt.class, goldilocks.ErrorClass(tt.err), "%d", i)
+		require.Equalf(t, tt.transient, goldilocks.IsTransient(tt.err), "%d", i)
+	}
+}
+
+func TestCommitUnknownError(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("connection reset")
+	var err error = &goldilocks.CommitUnknownError{Err: cause}
+	require.EqualError(t, err, "commit status unknown: connection reset")
+	require.True(t, errors.Is(err, cause))
+
+	var commitUnknownErr *goldilocks.CommitUnknownError
+	require.True(t, errors.As(&goldilocks.ConnError{Err: err}, &commitUnknownErr))
+}
+
+func TestSQLErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	err := &goldilocks.SQLError{
+		SQL:        "select id, name form widgets where id = $1 and name = $2 order by name",
+		ParamTypes: []string{"int32", "string"},
+		Err:        &pgconn.PgError{Severity: "ERROR", Code: "42601", Message: `syntax error at or near "form"`, Position: 17},
+	}
+	require.EqualError(t, err, `ERROR: syntax error at or near "form" (SQLSTATE 42601) (at position 17 of "select id, name form widgets where id = $1 and...", param types [int32, string])`)
+
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr))
+}
+
+func TestQuerySQLError(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	sql := "select $1::int4 form goldilocks_missing"
+	_, err = db.Query(context.Background(), sql, []interface{}{int32(1)}, nil, func() error { return nil })
+	var sqlErr *goldilocks.SQLError
+	require.True(t, errors.As(err, &sqlErr))
+	require.Equal(t, sql, sqlErr.SQL)
+	require.Equal(t, []string{"int32"}, sqlErr.ParamTypes)
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr))
+	require.Equal(t, pgerrcode.SyntaxError, pgErr.Code)
+
+	_, err = db.Exec(context.Background(), "select 1 form goldilocks_missing")
+	require.True(t, errors.As(err, &sqlErr))
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestDecodeError(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var a, b int32
+	_, err = db.Query(context.Background(), "select 1::int4 as a, 2::int8 as b", nil, []interface{}{&a, &b}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[1] (column "b"): int32 requires data length of 4, got 8`)
+	var decodeErr *goldilocks.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, 1, decodeErr.Index)
+	require.Equal(t, "b", decodeErr.Name)
+
+	_, err = db.QueryChunks(context.Background(), "select 1::int4 as a, 2::int8 as b", nil, []interface{}{&a, &b}, 10, func() error { return nil }, nil)
+	require.EqualError(t, err, `cannot decode results[1]: int32 requires data length of 4, got 8`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestEncodeError(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "select $1::int8, $2::int8", int64(1), uint64(math.MaxUint64))
+	require.EqualError(t, err, "cannot encode args[1] (uint64 as type oid 20): 18446744073709551615 is greater than maximum value for int8")
+	var encodeErr *goldilocks.EncodeError
+	require.True(t, errors.As(err, &encodeErr))
+	require.Equal(t, 1, encodeErr.Index)
+	require.Equal(t, "uint64", encodeErr.Type)
+	require.EqualValues(t, 20, encodeErr.OID)
+
+	_, err = db.Exec(context.Background(), "select $1::text", nil)
+	require.EqualError(t, err, "cannot encode args[0] (<nil>): untyped nil cannot be encoded; use a nil pointer or a Null type for NULL")
+
+	_, err = db.Exec(context.Background(), "select $1::date", (*goldilocks.Date)(nil))
+	require.EqualError(t, err, "cannot encode args[0] (*goldilocks.Date): nil pointer cannot be encoded; use a Null type that is not valid for NULL")
+
+	_, err = db.Prepare(context.Background(), "encode_error", "select $1::text")
+	require.NoError(t, err)
+	_, err = db.ExecPrepared(context.Background(), "encode_error", goldilocks.Range{Lower: int32(1), Upper: int64(2)})
+	require.EqualError(t, err, "cannot encode args[0] (goldilocks.Range as type oid 25): range bounds must be the same type, got int32 and int64")
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,442 @@
+package goldilocks
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/jackc/pgio"
+)
+
+// PostgreSQL oids for geometric types
+const (
+	pointOID   = 600
+	pathOID    = 602
+	boxOID     = 603
+	polygonOID = 604
+	lineOID    = 628
+	circleOID  = 718
+)
+
+type NullPoint struct {
+	Value Point
+	Valid bool
+}
+
+func (n NullPoint) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writePoint(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullPoint) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullPoint) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullPoint{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullPoint(buf, &n.Value)
+}
+
+// Point represents a PostgreSQL point.
+type Point struct {
+	X float64
+	Y float64
+}
+
+func (nn Point) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writePoint(buf, nn)
+}
+
+func (*Point) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *Point) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to Point")
+	}
+	return readNotNullPoint(buf, nn)
+}
+
+func readNotNullPoint(buf []byte, dst *Point) error {
+	if len(buf) != 16 {
+		return fmt.Errorf("point requires data length of 16, got %d", len(buf))
+	}
+	*dst = decodePoint(buf)
+	return nil
+}
+
+func writePoint(buf []byte, src Point) ([]byte, uint32, int16, error) {
+	return appendPoint(buf, src), pointOID, binaryFormat, nil
+}
+
+type NullLine struct {
+	Value Line
+	Valid bool
+}
+
+func (n NullLine) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeLine(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullLine) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullLine) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullLine{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullLine(buf, &n.Value)
+}
+
+// Line represents a PostgreSQL line. It is the infinite line satisfying the equation Ax + By + C = 0.
+type Line struct {
+	A float64
+	B float64
+	C float64
+}
+
+func (nn Line) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writeLine(buf, nn)
+}
+
+func (*Line) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *Line) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to Line")
+	}
+	return readNotNullLine(buf, nn)
+}
+
+func readNotNullLine(buf []byte, dst *Line) error {
+	if len(buf) != 24 {
+		return fmt.Errorf("line requires data length of 24, got %d", len(buf))
+	}
+	*dst = Line{
+		A: decodeFloat64(buf),
+		B: decodeFloat64(buf[8:]),
+		C: decodeFloat64(buf[16:]),
+	}
+	return nil
+}
+
+func writeLine(buf []byte, src Line) ([]byte, uint32, int16, error) {
+	buf = appendFloat64(buf, src.A)
+	buf = appendFloat64(buf, src.B)
+	buf = appendFloat64(buf, src.C)
+	return buf, lineOID, binaryFormat, nil
+}
+
+type NullBox struct {
+	Value Box
+	Valid bool
+}
+
+func (n NullBox) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeBox(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullBox) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullBox) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullBox{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullBox(buf, &n.Value)
+}
+
+// Box represents a PostgreSQL box. PostgreSQL reorders the corners so High is the upper right corner and Low is the
+// lower left corner.
+type Box struct {
+	High Point
+	Low  Point
+}
+
+func (nn Box) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writeBox(buf, nn)
+}
+
+func (*Box) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *Box) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to Box")
+	}
+	return readNotNullBox(buf, nn)
+}
+
+func readNotNullBox(buf []byte, dst *Box) error {
+	if len(buf) != 32 {
+		return fmt.Errorf("box requires data length of 32, got %d", len(buf))
+	}
+	*dst = Box{High: decodePoint(buf), Low: decodePoint(buf[16:])}
+	return nil
+}
+
+func writeBox(buf []byte, src Box) ([]byte, uint32, int16, error) {
+	buf = appendPoint(buf, src.High)
+	buf = appendPoint(buf, src.Low)
+	return buf, boxOID, binaryFormat, nil
+}
+
+type NullPath struct {
+	Value Path
+	Valid bool
+}
+
+func (n NullPath) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writePath(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullPath) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullPath) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullPath{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullPath(buf, &n.Value)
+}
+
+// Path represents a PostgreSQL path.
+type Path struct {
+	Points []Point
+	Closed bool
+}
+
+func (nn Path) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writePath(buf, nn)
+}
+
+func (*Path) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *Path) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to Path")
+	}
+	return readNotNullPath(buf, nn)
+}
+
+func readNotNullPath(buf []byte, dst *Path) error {
+	// The binary format is a closed flag followed by the points.
+	if len(buf) < 1 {
+		return fmt.Errorf("path requires data length of at least 1, got %d", len(buf))
+	}
+	points, err := decodePoints(buf[1:], "path")
+	if err != nil {
+		return err
+	}
+	*dst = Path{Points: points, Closed: buf[0] == 1}
+	return nil
+}
+
+func writePath(buf []byte, src Path) ([]byte, uint32, int16, error) {
+	var closed byte
+	if src.Closed {
+		closed = 1
+	}
+	buf = append(buf, closed)
+	buf = appendPoints(buf, src.Points)
+	return buf, pathOID, binaryFormat, nil
+}
+
+type NullPolygon struct {
+	Value Polygon
+	Valid bool
+}
+
+func (n NullPolygon) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writePolygon(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullPolygon) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullPolygon) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullPolygon{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullPolygon(buf, &n.Value)
+}
+
+// Polygon represents a PostgreSQL polygon.
+type Polygon struct {
+	Points []Point
+}
+
+func (nn Polygon) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writePolygon(buf, nn)
+}
+
+func (*Polygon) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *Polygon) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to Polygon")
+	}
+	return readNotNullPolygon(buf, nn)
+}
+
+func readNotNullPolygon(buf []byte, dst *Polygon) error {
+	points, err := decodePoints(buf, "polygon")
+	if err != nil {
+		return err
+	}
+	*dst = Polygon{Points: points}
+	return nil
+}
+
+func writePolygon(buf []byte, src Polygon) ([]byte, uint32, int16, error) {
+	return appendPoints(buf, src.Points), polygonOID, binaryFormat, nil
+}
+
+type NullCircle struct {
+	Value Circle
+	Valid bool
+}
+
+func (n NullCircle) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeCircle(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullCircle) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullCircle) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullCircle{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullCircle(buf, &n.Value)
+}
+
+// Circle represents a PostgreSQL circle.
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+func (nn Circle) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writeCircle(buf, nn)
+}
+
+func (*Circle) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *Circle) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to Circle")
+	}
+	return readNotNullCircle(buf, nn)
+}
+
+func readNotNullCircle(buf []byte, dst *Circle) error {
+	if len(buf) != 24 {
+		return fmt.Errorf("circle requires data length of 24, got %d", len(buf))
+	}
+	*dst = Circle{Center: decodePoint(buf), Radius: decodeFloat64(buf[16:])}
+	return nil
+}
+
+func writeCircle(buf []byte, src Circle) ([]byte, uint32, int16, error) {
+	buf = appendPoint(buf, src.Center)
+	buf = appendFloat64(buf, src.Radius)
+	return buf, circleOID, binaryFormat, nil
+}
+
+func decodeFloat64(buf []byte) float64 {
+	return math.Float64frombits(binary.BigEndian.Uint64(buf))
+}
+
+func appendFloat64(buf []byte, f float64) []byte {
+	return pgio.AppendUint64(buf, math.Float64bits(f))
+}
+
+func decodePoint(buf []byte) Point {
+	return Point{X: decodeFloat64(buf), Y: decodeFloat64(buf[8:])}
+}
+
+func appendPoint(buf []byte, p Point) []byte {
+	buf = appendFloat64(buf, p.X)
+	buf = appendFloat64(buf, p.Y)
+	return buf
+}
+
+// decodePoints decodes the number of points followed by the points. typeName is used in error messages.
+func decodePoints(buf []byte, typeName string) ([]Point, error) {
+	if len(buf) < 4 {
+		return nil, fmt.Errorf("%s requires data length of at least 4, got %d", typeName, len(buf))
+	}
+	n := int(int32(binary.BigEndian.Uint32(buf)))
+	buf = buf[4:]
+	if n < 0 || len(buf) != n*16 {
+		return nil, fmt.Errorf("%s with %d points requires data length of %d, got %d", typeName, n, n*16, len(buf))
+	}
+
+	points := make([]Point, n)
+	for i := range points {
+		points[i] = decodePoint(buf[i*16:])
+	}
+	return points, nil
+}
+
+func appendPoints(buf []byte, points []Point) []byte {
+	buf = pgio.AppendInt32(buf, int32(len(points)))
+	for _, p := range points {
+		buf = appendPoint(buf, p)
+	}
+	return buf
+}
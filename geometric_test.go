@@ -0,0 +1,110 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeometricTypes(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	for i, tt := range []struct {
+		sql      string
+		param    interface{}
+		result   interface{}
+		expected interface{}
+		text     string
+	}{
+		{
+			sql:      "point",
+			param:    goldilocks.Point{X: 1.5, Y: -2},
+			result:   &goldilocks.Point{},
+			expected: &goldilocks.Point{X: 1.5, Y: -2},
+			text:     "(1.5,-2)",
+		},
+		{
+			sql:      "line",
+			param:    goldilocks.Line{A: 1, B: -1, C: 0},
+			result:   &goldilocks.Line{},
+			expected: &goldilocks.Line{A: 1, B: -1, C: 0},
+			text:     "{1,-1,0}",
+		},
+		{
+			sql:      "box",
+			param:    goldilocks.Box{High: goldilocks.Point{X: 3, Y: 4}, Low: goldilocks.Point{X: 1, Y: 2}},
+			result:   &goldilocks.Box{},
+			expected: &goldilocks.Box{High: goldilocks.Point{X: 3, Y: 4}, Low: goldilocks.Point{X: 1, Y: 2}},
+			text:     "(3,4),(1,2)",
+		},
+		{
+			sql:      "path",
+			param:    goldilocks.Path{Points: []goldilocks.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}, Closed: false},
+			result:   &goldilocks.Path{},
+			expected: &goldilocks.Path{Points: []goldilocks.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}, Closed: false},
+			text:     "[(0,0),(1,1)]",
+		},
+		{
+			sql:      "path",
+			param:    goldilocks.Path{Points: []goldilocks.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 0}}, Closed: true},
+			result:   &goldilocks.Path{},
+			expected: &goldilocks.Path{Points: []goldilocks.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 0}}, Closed: true},
+			text:     "((0,0),(1,1),(1,0))",
+		},
+		{
+			sql:      "polygon",
+			param:    goldilocks.Polygon{Points: []goldilocks.Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 0}}},
+			result:   &goldilocks.Polygon{},
+			expected: &goldilocks.Polygon{Points: []goldilocks.Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 0}}},
+			text:     "((0,0),(0,1),(1,0))",
+		},
+		{
+			sql:      "circle",
+			param:    goldilocks.Circle{Center: goldilocks.Point{X: 1, Y: 2}, Radius: 3},
+			result:   &goldilocks.Circle{},
+			expected: &goldilocks.Circle{Center: goldilocks.Point{X: 1, Y: 2}, Radius: 3},
+			text:     "<(1,2),3>",
+		},
+		{
+			sql:      "point",
+			param:    goldilocks.NullPoint{Value: goldilocks.Point{X: 1, Y: 2}, Valid: true},
+			result:   &goldilocks.NullPoint{},
+			expected: &goldilocks.NullPoint{Value: goldilocks.Point{X: 1, Y: 2}, Valid: true},
+			text:     "(1,2)",
+		},
+		{
+			sql:      "circle",
+			param:    goldilocks.NullCircle{},
+			result:   &goldilocks.NullCircle{},
+			expected: &goldilocks.NullCircle{},
+			text:     "null",
+		},
+	} {
+		var text string
+		_, err := db.Query(
+			context.Background(),
+			"select $1::"+tt.sql+", coalesce($1::"+tt.sql+"::text, 'null')",
+			[]interface{}{tt.param},
+			[]interface{}{tt.result, &text},
+			func() error { return nil },
+		)
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, tt.expected, tt.result, "%d", i)
+		require.Equalf(t, tt.text, text, "%d", i)
+	}
+
+	var p goldilocks.Point
+	_, err = db.Query(context.Background(), "select null::point", nil, []interface{}{&p}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "point"): NULL cannot be converted to Point`)
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,197 @@
+package goldilocks
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+)
+
+// EWKB geometry types and flags.
+const (
+	ewkbPoint      = 1
+	ewkbLineString = 2
+
+	ewkbZFlag    = 0x80000000
+	ewkbMFlag    = 0x40000000
+	ewkbSRIDFlag = 0x20000000
+)
+
+// Geometry is a PostGIS geometry or geography in the extended well-known binary (EWKB) format. A nil Geometry is
+// NULL. Point and LineString decode the simple cases. Other geometries can be decoded with a WKB library.
+//
+// The oid of the geometry type is assigned when the extension is created so a Geometry is sent without a type. The
+// server infers it from the query as it does for a $1::geometry param or a param inserted into a geometry column.
+// Where the type cannot be inferred, load it into a TypeMap and send the Geometry with TypeMap.Param("geometry", g).
+type Geometry []byte
+
+// PointGeometry returns the EWKB of p with the spatial reference system identifier srid. An srid of 0 is omitted.
+func PointGeometry(p Point, srid int32) Geometry {
+	buf := appendEWKBHeader(nil, ewkbPoint, srid)
+	return appendEWKBPoint(buf, p)
+}
+
+// LineStringGeometry returns the EWKB of a linestring of points with the spatial reference system identifier srid. An
+// srid of 0 is omitted.
+func LineStringGeometry(points []Point, srid int32) Geometry {
+	buf := appendEWKBHeader(nil, ewkbLineString, srid)
+	buf = appendUint32LE(buf, uint32(len(points)))
+	for _, p := range points {
+		buf = appendEWKBPoint(buf, p)
+	}
+	return buf
+}
+
+func (g Geometry) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if g == nil {
+		return nil, 0, binaryFormat, nil
+	}
+	return append(buf, g...), 0, binaryFormat, nil
+}
+
+func (*Geometry) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (g *Geometry) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*g = nil
+		return nil
+	}
+	*g = append(make(Geometry, 0, len(buf)), buf...)
+	return nil
+}
+
+// DecodeTextResult decodes the text format of a geometry, which is EWKB in hex.
+func (g *Geometry) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		*g = nil
+		return nil
+	}
+
+	dst := make(Geometry, hex.DecodedLen(len(buf)))
+	_, err := hex.Decode(dst, buf)
+	if err != nil {
+		return fmt.Errorf("invalid geometry: %w", err)
+	}
+	*g = dst
+	return nil
+}
+
+// SRID returns the spatial reference system identifier of g or 0 if it does not have one.
+func (g Geometry) SRID() (int32, error) {
+	_, _, srid, _, err := g.header()
+	return srid, err
+}
+
+// Point decodes g as a two-dimensional point.
+func (g Geometry) Point() (Point, error) {
+	order, geomType, _, buf, err := g.header()
+	if err != nil {
+		return Point{}, err
+	}
+	if geomType&(ewkbZFlag|ewkbMFlag) != 0 {
+		return Point{}, fmt.Errorf("Geometry with Z or M coordinates is not supported")
+	}
+	if geomType != ewkbPoint {
+		return Point{}, fmt.Errorf("Geometry is type %d, not a point", geomType)
+	}
+	if len(buf) != 16 {
+		return Point{}, fmt.Errorf("Geometry point requires data length of 16, got %d", len(buf))
+	}
+	return readEWKBPoint(order, buf), nil
+}
+
+// LineString decodes g as a two-dimensional linestring.
+func (g Geometry) LineString() ([]Point, error) {
+	order, geomType, _, buf, err := g.header()
+	if err != nil {
+		return nil, err
+	}
+	if geomType&(ewkbZFlag|ewkbMFlag) != 0 {
+		return nil, fmt.Errorf("Geometry with Z or M coordinates is not supported")
+	}
+	if geomType != ewkbLineString {
+		return nil, fmt.Errorf("Geometry is type %d, not a linestring", geomType)
+	}
+	if len(buf) < 4 {
+		return nil, fmt.Errorf("Geometry linestring requires data length of at least 4, got %d", len(buf))
+	}
+	n := int(order.Uint32(buf))
+	buf = buf[4:]
+	if len(buf) != n*16 {
+		return nil, fmt.Errorf("Geometry linestring of %d points requires data length of %d, got %d", n, n*16, len(buf))
+	}
+
+	points := make([]Point, n)
+	for i := range points {
+		points[i] = readEWKBPoint(order, buf[i*16:])
+	}
+	return points, nil
+}
+
+// header reads the EWKB header of g. It returns the byte order, the geometry type without the srid flag, the srid, and
+// the remainder of g.
+func (g Geometry) header() (binary.ByteOrder, uint32, int32, []byte, error) {
+	if len(g) < 5 {
+		return nil, 0, 0, nil, fmt.Errorf("Geometry requires data length of at least 5, got %d", len(g))
+	}
+
+	var order binary.ByteOrder
+	switch g[0] {
+	case 0:
+		order = binary.BigEndian
+	case 1:
+		order = binary.LittleEndian
+	default:
+		return nil, 0, 0, nil, fmt.Errorf("Geometry has invalid byte order %d", g[0])
+	}
+
+	geomType := order.Uint32(g[1:])
+	buf := []byte(g[5:])
+
+	var srid int32
+	if geomType&ewkbSRIDFlag != 0 {
+		if len(buf) < 4 {
+			return nil, 0, 0, nil, fmt.Errorf("Geometry srid is missing")
+		}
+		srid = int32(order.Uint32(buf))
+		buf = buf[4:]
+	}
+
+	return order, geomType &^ ewkbSRIDFlag, srid, buf, nil
+}
+
+// appendEWKBHeader appends a little endian EWKB header for geomType with srid.
+func appendEWKBHeader(buf []byte, geomType uint32, srid int32) []byte {
+	buf = append(buf, 1)
+	if srid == 0 {
+		return appendUint32LE(buf, geomType)
+	}
+	buf = appendUint32LE(buf, geomType|ewkbSRIDFlag)
+	return appendUint32LE(buf, uint32(srid))
+}
+
+func appendEWKBPoint(buf []byte, p Point) []byte {
+	buf = appendUint64LE(buf, math.Float64bits(p.X))
+	return appendUint64LE(buf, math.Float64bits(p.Y))
+}
+
+func appendUint32LE(buf []byte, n uint32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], n)
+	return append(buf, b[:]...)
+}
+
+func appendUint64LE(buf []byte, n uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], n)
+	return append(buf, b[:]...)
+}
+
+func readEWKBPoint(order binary.ByteOrder, buf []byte) Point {
+	return Point{
+		X: math.Float64frombits(order.Uint64(buf)),
+		Y: math.Float64frombits(order.Uint64(buf[8:])),
+	}
+}
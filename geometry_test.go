@@ -0,0 +1,108 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeometry(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var installed bool
+	_, err = db.Query(
+		context.Background(),
+		"select exists(select 1 from pg_extension where extname = 'postgis')",
+		nil,
+		[]interface{}{&installed},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	if !installed {
+		t.Skip("postgis extension is not installed")
+	}
+
+	var point, lineString, null goldilocks.Geometry
+	var text string
+	_, err = db.Query(
+		context.Background(),
+		"select ST_SetSRID(ST_MakePoint(1.5, -2), 4326), $1::geometry, $2::geometry, ST_AsText($1::geometry)",
+		[]interface{}{
+			goldilocks.LineStringGeometry([]goldilocks.Point{{X: 0, Y: 0}, {X: 1, Y: 2}}, 0),
+			goldilocks.Geometry(nil),
+		},
+		[]interface{}{&point, &lineString, &null, &text},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+
+	p, err := point.Point()
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.Point{X: 1.5, Y: -2}, p)
+	srid, err := point.SRID()
+	require.NoError(t, err)
+	require.EqualValues(t, 4326, srid)
+
+	points, err := lineString.LineString()
+	require.NoError(t, err)
+	require.Equal(t, []goldilocks.Point{{X: 0, Y: 0}, {X: 1, Y: 2}}, points)
+	require.Nil(t, null)
+	require.Equal(t, "LINESTRING(0 0,1 2)", text)
+
+	var fromText goldilocks.Geometry
+	_, err = db.Query(
+		context.Background(),
+		"select $1::geometry",
+		[]interface{}{goldilocks.PointGeometry(goldilocks.Point{X: 3, Y: 4}, 3857)},
+		[]interface{}{goldilocks.TextResult(&fromText)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.PointGeometry(goldilocks.Point{X: 3, Y: 4}, 3857), fromText)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestGeometryEWKB(t *testing.T) {
+	t.Parallel()
+
+	g := goldilocks.PointGeometry(goldilocks.Point{X: 1, Y: 2}, 0)
+	srid, err := g.SRID()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, srid)
+	_, err = g.LineString()
+	require.EqualError(t, err, "Geometry is type 1, not a linestring")
+
+	// Big endian point with srid 4326.
+	g = goldilocks.Geometry{
+		0, 0x20, 0, 0, 1, 0, 0, 0x10, 0xe6,
+		0x3f, 0xf0, 0, 0, 0, 0, 0, 0,
+		0x40, 0, 0, 0, 0, 0, 0, 0,
+	}
+	p, err := g.Point()
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.Point{X: 1, Y: 2}, p)
+	srid, err = g.SRID()
+	require.NoError(t, err)
+	require.EqualValues(t, 4326, srid)
+
+	// Point with a Z coordinate.
+	g = goldilocks.Geometry{1, 1, 0, 0, 0x80}
+	_, err = g.Point()
+	require.EqualError(t, err, "Geometry with Z or M coordinates is not supported")
+
+	_, err = goldilocks.Geometry{2, 1, 0, 0, 0}.Point()
+	require.EqualError(t, err, "Geometry has invalid byte order 2")
+
+	_, err = goldilocks.Geometry{1}.SRID()
+	require.EqualError(t, err, "Geometry requires data length of at least 5, got 1")
+}
@@ -6,6 +6,8 @@ require (
 	github.com/jackc/pgconn v1.7.2
 	github.com/jackc/pgerrcode v0.0.0-20201024163028-a0d42d470451
 	github.com/jackc/pgio v1.0.0
+	github.com/jackc/pgproto3/v2 v2.0.6
+	github.com/jackc/pgtype v1.6.1
 	github.com/jackc/puddle v1.1.2
 	github.com/stretchr/testify v1.6.1
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1
@@ -3,8 +3,28 @@ package goldilocks
 import "context"
 
 // StdDB is the common interface that Pool, Conn, and transaction contexts support.
+//
+// If ctx is done while Query or Exec is executing a cancel request is sent to the server and ctx.Err() is returned
+// when the server has ended the query. The connection remains usable so a Pool can reuse it. A query in a transaction
+// fails the transaction as usual.
 type StdDB interface {
 	Query(ctx context.Context, sql string, args []interface{}, results []interface{}, rowFunc func() error) (rowsAffected int64, err error)
 	Exec(ctx context.Context, sql string, args ...interface{}) (rowsAffected int64, err error)
 	Begin(ctx context.Context, f func(StdDB) error) error
 }
+
+// ExecExpect executes sql with args on db like Exec. It returns an *UnexpectedRowCountError if the number of rows
+// affected is not expected. It does not roll back the changes. Use it within Begin so an error rolls back the
+// transaction.
+func ExecExpect(ctx context.Context, db StdDB, expected int64, sql string, args ...interface{}) error {
+	rowsAffected, err := db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected != expected {
+		return &UnexpectedRowCountError{Expected: expected, Actual: rowsAffected}
+	}
+
+	return nil
+}
@@ -133,12 +133,12 @@ func testQueryParamEncodersAndResultDecoders(t *testing.T, db goldilocks.StdDB)
 		nullB := goldilocks.NullBool{}
 		nullResB := goldilocks.NullBool{}
 
-		date := goldilocks.NullDate{time.Date(2020, 11, 9, 0, 0, 0, 0, time.UTC), true}
+		date := goldilocks.NullDate{Value: time.Date(2020, 11, 9, 0, 0, 0, 0, time.UTC), Valid: true}
 		resDate := goldilocks.NullDate{}
 		nullDate := goldilocks.NullDate{}
 		nullResDate := goldilocks.NullDate{}
 
-		tm := goldilocks.NullTime{time.Date(2020, 11, 9, 2, 12, 2, 0, time.UTC), true}
+		tm := goldilocks.NullTime{Value: time.Date(2020, 11, 9, 2, 12, 2, 0, time.UTC), Valid: true}
 		resTime := goldilocks.NullTime{}
 		nullTime := goldilocks.NullTime{}
 		nullResTime := goldilocks.NullTime{}
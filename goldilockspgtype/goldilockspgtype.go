@@ -0,0 +1,194 @@
+// Package goldilockspgtype allows the types of github.com/jackc/pgtype to be used as goldilocks query params and
+// results.
+package goldilockspgtype
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgtype"
+)
+
+const (
+	textFormat   = pgtype.TextFormatCode
+	binaryFormat = pgtype.BinaryFormatCode
+)
+
+// prepareConnInfo builds the lookup tables of ci that pgtype otherwise builds lazily on first use so ci can be used
+// concurrently. ci must not be modified afterwards.
+func prepareConnInfo(ci *pgtype.ConnInfo) {
+	ci.DataTypeForValue(nil)
+}
+
+// Param returns a query param that encodes value with ci. value must implement pgtype.BinaryEncoder or
+// pgtype.TextEncoder. The binary format is used when value supports it unless value prefers the text format. The type
+// of the param is the type registered in ci for value. If there is none the server infers the type.
+func Param(ci *pgtype.ConnInfo, value interface{}) goldilocks.ParamEncoder {
+	prepareConnInfo(ci)
+	return param{ci: ci, value: value}
+}
+
+// Result returns a query result that decodes into dst with ci. dst must implement pgtype.BinaryDecoder or
+// pgtype.TextDecoder. The binary format is requested when dst supports it unless dst prefers the text format.
+func Result(ci *pgtype.ConnInfo, dst interface{}) goldilocks.ResultDecoder {
+	prepareConnInfo(ci)
+	rd, ok := newResult(ci, dst)
+	if !ok {
+		return unsupportedResult{dst: dst}
+	}
+	return rd
+}
+
+// ParamResolver returns a goldilocks.ParamResolver that encodes any param that implements pgtype.BinaryEncoder or
+// pgtype.TextEncoder with ci. Add it to goldilocks.ConnConfig.ParamResolvers to use pgtype values directly as params.
+func ParamResolver(ci *pgtype.ConnInfo) goldilocks.ParamResolver {
+	prepareConnInfo(ci)
+	return func(arg interface{}) (goldilocks.ParamEncoder, bool) {
+		switch arg.(type) {
+		case pgtype.BinaryEncoder, pgtype.TextEncoder:
+			return param{ci: ci, value: arg}, true
+		default:
+			return nil, false
+		}
+	}
+}
+
+// ResultResolver returns a goldilocks.ResultResolver that decodes into any result that implements pgtype.BinaryDecoder
+// or pgtype.TextDecoder with ci. Add it to goldilocks.ConnConfig.ResultResolvers to use pgtype values directly as
+// results.
+func ResultResolver(ci *pgtype.ConnInfo) goldilocks.ResultResolver {
+	prepareConnInfo(ci)
+	return func(dst interface{}) (goldilocks.ResultDecoder, bool) {
+		return newResult(ci, dst)
+	}
+}
+
+type param struct {
+	ci    *pgtype.ConnInfo
+	value interface{}
+}
+
+func (p param) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	oid := p.oid()
+
+	be, isBinary := p.value.(pgtype.BinaryEncoder)
+	te, isText := p.value.(pgtype.TextEncoder)
+	if pfp, ok := p.value.(pgtype.ParamFormatPreferrer); ok && isText && pfp.PreferredParamFormat() == textFormat {
+		isBinary = false
+	}
+
+	var err error
+	var format int16
+	switch {
+	case isBinary:
+		buf, err = be.EncodeBinary(p.ci, buf)
+		format = binaryFormat
+	case isText:
+		buf, err = te.EncodeText(p.ci, buf)
+		format = textFormat
+	default:
+		return nil, 0, 0, fmt.Errorf("%T does not implement pgtype.BinaryEncoder or pgtype.TextEncoder", p.value)
+	}
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return buf, oid, format, nil
+}
+
+// oid returns the oid of the data type registered in p.ci for p.value or 0 if there is none.
+func (p param) oid() uint32 {
+	if dt, ok := p.ci.DataTypeForValue(p.value); ok {
+		return dt.OID
+	}
+
+	// Data types are registered as pointers but values are commonly passed as params.
+	t := reflect.TypeOf(p.value)
+	if t != nil && t.Kind() != reflect.Ptr {
+		if dt, ok := p.ci.DataTypeForValue(reflect.New(t).Interface()); ok {
+			return dt.OID
+		}
+	}
+
+	return 0
+}
+
+// newResult returns the goldilocks.ResultDecoder for dst or false if dst does not implement pgtype.BinaryDecoder or
+// pgtype.TextDecoder.
+func newResult(ci *pgtype.ConnInfo, dst interface{}) (goldilocks.ResultDecoder, bool) {
+	bd, isBinary := dst.(pgtype.BinaryDecoder)
+	td, isText := dst.(pgtype.TextDecoder)
+	if rfp, ok := dst.(pgtype.ResultFormatPreferrer); ok && isText && rfp.PreferredResultFormat() == textFormat {
+		isBinary = false
+	}
+
+	switch {
+	case isBinary && isText:
+		return binaryTextResult{binaryResult: binaryResult{ci: ci, dst: bd}, td: td}, true
+	case isBinary:
+		return binaryResult{ci: ci, dst: bd}, true
+	case isText:
+		return textResult{ci: ci, dst: td}, true
+	default:
+		return nil, false
+	}
+}
+
+// copyValue returns a copy of buf. pgtype decoders may retain the buffer they decode but goldilocks reuses it for the
+// next row.
+func copyValue(buf []byte) []byte {
+	if buf == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(buf)), buf...)
+}
+
+type binaryResult struct {
+	ci  *pgtype.ConnInfo
+	dst pgtype.BinaryDecoder
+}
+
+func (binaryResult) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (r binaryResult) DecodeResult(buf []byte) error {
+	return r.dst.DecodeBinary(r.ci, copyValue(buf))
+}
+
+// binaryTextResult is a binaryResult that can also decode the text format. This allows it to be used with
+// goldilocks.ConnConfig.TextResults and goldilocks.TextResult.
+type binaryTextResult struct {
+	binaryResult
+	td pgtype.TextDecoder
+}
+
+func (r binaryTextResult) DecodeTextResult(buf []byte) error {
+	return r.td.DecodeText(r.ci, copyValue(buf))
+}
+
+type textResult struct {
+	ci  *pgtype.ConnInfo
+	dst pgtype.TextDecoder
+}
+
+func (textResult) ResultFormat() int16 {
+	return textFormat
+}
+
+func (r textResult) DecodeResult(buf []byte) error {
+	return r.dst.DecodeText(r.ci, copyValue(buf))
+}
+
+type unsupportedResult struct {
+	dst interface{}
+}
+
+func (unsupportedResult) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (r unsupportedResult) DecodeResult(buf []byte) error {
+	return fmt.Errorf("%T does not implement pgtype.BinaryDecoder or pgtype.TextDecoder", r.dst)
+}
@@ -0,0 +1,92 @@
+package goldilockspgtype_test
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/goldilocks/goldilockspgtype"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamAndResult(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer pgConn.Close(context.Background())
+	db := goldilocks.NewConn(pgConn)
+	ci := pgtype.NewConnInfo()
+
+	uuid := pgtype.UUID{Bytes: [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, Status: pgtype.Present}
+	var uuidResult pgtype.UUID
+	var int4Result pgtype.Int4
+	var nullResult pgtype.Text
+	var typeName string
+	_, err = db.Query(
+		context.Background(),
+		"select $1, $2::int4 + 1, $3::text, pg_typeof($1)::text",
+		[]interface{}{
+			goldilockspgtype.Param(ci, uuid),
+			goldilockspgtype.Param(ci, &pgtype.Int4{Int: 41, Status: pgtype.Present}),
+			goldilockspgtype.Param(ci, pgtype.Text{Status: pgtype.Null}),
+		},
+		[]interface{}{
+			goldilockspgtype.Result(ci, &uuidResult),
+			goldilockspgtype.Result(ci, &int4Result),
+			goldilockspgtype.Result(ci, &nullResult),
+			&typeName,
+		},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, uuid, uuidResult)
+	require.Equal(t, pgtype.Int4{Int: 42, Status: pgtype.Present}, int4Result)
+	require.Equal(t, pgtype.Null, nullResult.Status)
+	require.Equal(t, "uuid", typeName)
+
+	_, err = db.Query(context.Background(), "select 1", nil, []interface{}{goldilockspgtype.Result(ci, &typeName)}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "?column?"): *string does not implement pgtype.BinaryDecoder or pgtype.TextDecoder`)
+}
+
+func TestResolvers(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer pgConn.Close(context.Background())
+	ci := pgtype.NewConnInfo()
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{
+		ParamResolvers:  []goldilocks.ParamResolver{goldilockspgtype.ParamResolver(ci)},
+		ResultResolvers: []goldilocks.ResultResolver{goldilockspgtype.ResultResolver(ci)},
+	})
+
+	var numeric pgtype.Numeric
+	var n int32
+	_, err = db.Query(
+		context.Background(),
+		"select $1::numeric * 2, $2::int4",
+		[]interface{}{&pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, Status: pgtype.Present}, int32(7)},
+		[]interface{}{&numeric, &n},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	var f float64
+	require.NoError(t, numeric.AssignTo(&f))
+	require.Equal(t, 246.9, f)
+	require.EqualValues(t, 7, n)
+
+	// The text format is used for results when configured.
+	textDB := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{
+		TextResults:     true,
+		ResultResolvers: []goldilocks.ResultResolver{goldilockspgtype.ResultResolver(ci)},
+	})
+	var interval pgtype.Interval
+	_, err = textDB.Query(context.Background(), "select '1 day 2 hours'::interval", nil, []interface{}{&interval}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, pgtype.Interval{Days: 1, Microseconds: 2 * 60 * 60 * 1000000, Status: pgtype.Present}, interval)
+}
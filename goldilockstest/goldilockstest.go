@@ -0,0 +1,146 @@
+// Package goldilockstest provides helpers for testing code that uses goldilocks.
+package goldilockstest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+)
+
+// RoundTrip checks that a custom type survives a round trip through the server as sqlType. sqlType is interpolated
+// into SQL so it must be trusted. newResult returns a new zero value of the type as a ResultDecoder. The decoder must
+// also implement goldilocks.ParamEncoder.
+//
+// Each value is sent as a param and decoded into a new result. The decoded result is then sent again and must have the
+// same text representation on the server as the original. If the decoder implements goldilocks.TextResultDecoder the
+// text format is checked the same way. A value that encodes as NULL must decode into a result that also encodes as
+// NULL.
+func RoundTrip(t testing.TB, db goldilocks.StdDB, sqlType string, newResult func() goldilocks.ResultDecoder, values ...goldilocks.ParamEncoder) {
+	t.Helper()
+
+	ctx := context.Background()
+	for i, value := range values {
+		var expected goldilocks.NullString
+		_, err := db.Query(ctx, "select $1::"+sqlType+"::text", []interface{}{value}, []interface{}{&expected}, func() error { return nil })
+		if err != nil {
+			t.Fatalf("values[%d]: send failed: %v", i, err)
+		}
+
+		result := newResult()
+		checkResult(t, db, sqlType, i, "binary", value, result, result, expected)
+
+		if trd, ok := newResult().(goldilocks.TextResultDecoder); ok {
+			checkResult(t, db, sqlType, i, "text", value, textResult{trd}, trd, expected)
+		}
+	}
+}
+
+// RoundTripNull checks that a NULL of sqlType decodes into a new result that encodes as NULL. See RoundTrip.
+func RoundTripNull(t testing.TB, db goldilocks.StdDB, sqlType string, newResult func() goldilocks.ResultDecoder) {
+	t.Helper()
+
+	result := newResult()
+	_, err := db.Query(context.Background(), "select null::"+sqlType, nil, []interface{}{result}, func() error { return nil })
+	if err != nil {
+		t.Fatalf("NULL: receive failed: %v", err)
+	}
+
+	checkResent(t, db, sqlType, "NULL", result, goldilocks.NullString{})
+}
+
+// SchemaPool creates a pool for connString whose connections use a new uniquely named schema as their search_path.
+// Tables and other objects created without a schema name are created in that schema so parallel tests are isolated
+// from each other. When the test finishes the schema and everything in it is dropped and the pool is closed.
+func SchemaPool(t testing.TB, connString string) *goldilocks.Pool {
+	t.Helper()
+
+	random := make([]byte, 8)
+	_, err := rand.Read(random)
+	if err != nil {
+		t.Fatalf("cannot generate schema name: %v", err)
+	}
+	schema := goldilocks.Identifier("goldilockstest_" + hex.EncodeToString(random)).Sanitize()
+
+	config, err := goldilocks.ParsePoolConfig(connString)
+	if err != nil {
+		t.Fatalf("cannot parse connString: %v", err)
+	}
+	config.RuntimeParams["search_path"] = schema
+
+	pool, err := goldilocks.NewPoolConfig(config)
+	if err != nil {
+		t.Fatalf("cannot create pool: %v", err)
+	}
+
+	_, err = pool.Exec(context.Background(), "create schema "+schema)
+	if err != nil {
+		pool.Close()
+		t.Fatalf("cannot create schema: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_, err := pool.Exec(context.Background(), "drop schema "+schema+" cascade")
+		if err != nil {
+			t.Errorf("cannot drop schema: %v", err)
+		}
+		pool.Close()
+	})
+
+	return pool
+}
+
+// checkResult decodes value with decoder into result then checks that result has the expected text representation.
+func checkResult(t testing.TB, db goldilocks.StdDB, sqlType string, i int, format string, value goldilocks.ParamEncoder, decoder goldilocks.ResultDecoder, result goldilocks.ResultDecoder, expected goldilocks.NullString) {
+	t.Helper()
+
+	_, err := db.Query(context.Background(), "select $1::"+sqlType, []interface{}{value}, []interface{}{decoder}, func() error { return nil })
+	if err != nil {
+		t.Fatalf("values[%d]: receive in %s format failed: %v", i, format, err)
+	}
+
+	checkResent(t, db, sqlType, fmt.Sprintf("values[%d] in %s format", i, format), result, expected)
+}
+
+// checkResent sends result and checks that it has the expected text representation on the server.
+func checkResent(t testing.TB, db goldilocks.StdDB, sqlType string, desc string, result goldilocks.ResultDecoder, expected goldilocks.NullString) {
+	t.Helper()
+
+	encoder, ok := result.(goldilocks.ParamEncoder)
+	if !ok {
+		t.Fatalf("%s: %T does not implement goldilocks.ParamEncoder", desc, result)
+	}
+
+	var actual goldilocks.NullString
+	_, err := db.Query(context.Background(), "select $1::"+sqlType+"::text", []interface{}{encoder}, []interface{}{&actual}, func() error { return nil })
+	if err != nil {
+		t.Fatalf("%s: resend failed: %v", desc, err)
+	}
+
+	if actual != expected {
+		t.Errorf("%s: expected %s, got %s", desc, formatNullString(expected), formatNullString(actual))
+	}
+}
+
+// textResult makes a TextResultDecoder decode the text format.
+type textResult struct {
+	d goldilocks.TextResultDecoder
+}
+
+func (textResult) ResultFormat() int16 {
+	return 0
+}
+
+func (tr textResult) DecodeResult(buf []byte) error {
+	return tr.d.DecodeTextResult(buf)
+}
+
+func formatNullString(s goldilocks.NullString) string {
+	if !s.Valid {
+		return "NULL"
+	}
+	return "'" + s.Value + "'"
+}
@@ -0,0 +1,76 @@
+package goldilockstest_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/goldilocks/goldilockstest"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer pgConn.Close(context.Background())
+	db := goldilocks.NewConn(pgConn)
+
+	goldilockstest.RoundTrip(t, db, "interval",
+		func() goldilocks.ResultDecoder { return &goldilocks.Interval{} },
+		goldilocks.Interval{},
+		goldilocks.Interval{Months: 14, Days: -3, Microseconds: 1},
+	)
+
+	goldilockstest.RoundTrip(t, db, "interval",
+		func() goldilocks.ResultDecoder { return &goldilocks.NullInterval{} },
+		goldilocks.NullInterval{Value: goldilocks.Interval{Days: 1}, Valid: true},
+		goldilocks.NullInterval{},
+	)
+	goldilockstest.RoundTripNull(t, db, "interval", func() goldilocks.ResultDecoder { return &goldilocks.NullInterval{} })
+
+	goldilockstest.RoundTrip(t, db, "numeric",
+		func() goldilocks.ResultDecoder { return &goldilocks.NullNumeric{} },
+		goldilocks.NullNumeric{Value: "-123.4500", Valid: true},
+	)
+}
+
+func TestSchemaPool(t *testing.T) {
+	t.Parallel()
+
+	var schema string
+	t.Run("isolated", func(t *testing.T) {
+		pool := goldilockstest.SchemaPool(t, os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+
+		_, err := pool.Exec(context.Background(), "create table widgets (id int4)")
+		require.NoError(t, err)
+
+		_, err = pool.Query(
+			context.Background(),
+			"select table_schema::text from information_schema.tables where table_name = 'widgets' and table_schema = current_schema()",
+			nil,
+			[]interface{}{&schema},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Regexp(t, "^goldilockstest_[0-9a-f]{16}$", schema)
+	})
+
+	db, err := goldilocks.NewPool(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var exists bool
+	_, err = db.Query(
+		context.Background(),
+		"select exists(select 1 from pg_namespace where nspname = $1)",
+		[]interface{}{schema},
+		[]interface{}{&exists},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
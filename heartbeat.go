@@ -0,0 +1,78 @@
+package goldilocks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type heartbeatCtxKey struct{}
+
+type heartbeat struct {
+	interval time.Duration
+	f        func() error
+}
+
+// WithHeartbeat returns a copy of ctx that causes Query to call f every interval while the query is in progress, even
+// while waiting for the server to send the next row. This allows a consumer of a long stream of rows to refresh leases
+// or locks. f is never called concurrently with rowFunc. If f returns an error the query is canceled and Query returns
+// that error.
+func WithHeartbeat(ctx context.Context, interval time.Duration, f func() error) context.Context {
+	if interval <= 0 {
+		panic("interval must be greater than 0")
+	}
+	return context.WithValue(ctx, heartbeatCtxKey{}, &heartbeat{interval: interval, f: f})
+}
+
+// queryWithHeartbeat executes the query while calling hb.f every hb.interval.
+func (c *Conn) queryWithHeartbeat(ctx context.Context, hb *heartbeat, sql string, stmt *Statement, args []interface{}, results []interface{}, rowFunc func() error) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// mux prevents the heartbeat from being called concurrently with rowFunc.
+	mux := &sync.Mutex{}
+	var heartbeatErr error
+	done := make(chan struct{})
+	wg := &sync.WaitGroup{}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(hb.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				mux.Lock()
+				err := hb.f()
+				mux.Unlock()
+				if err != nil {
+					heartbeatErr = err
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	var rowCount int64
+	err := c.withCancelRequest(ctx, func(ctx context.Context) error {
+		var err error
+		rowCount, err = c.queryRows(ctx, sql, stmt, args, results, func() error {
+			mux.Lock()
+			defer mux.Unlock()
+			return rowFunc()
+		})
+		return err
+	})
+	close(done)
+	wg.Wait()
+
+	if heartbeatErr != nil {
+		return rowCount, heartbeatErr
+	}
+	return rowCount, err
+}
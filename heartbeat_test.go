@@ -0,0 +1,49 @@
+package goldilocks_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var heartbeats int
+	var inRowFunc, concurrent bool
+	ctx := goldilocks.WithHeartbeat(context.Background(), 10*time.Millisecond, func() error {
+		if inRowFunc {
+			concurrent = true
+		}
+		heartbeats++
+		return nil
+	})
+
+	rowCount, err := db.Query(ctx, "select pg_sleep(0.1) from generate_series(1, 2)", nil, []interface{}{nil}, func() error {
+		inRowFunc = true
+		time.Sleep(20 * time.Millisecond)
+		inRowFunc = false
+		return nil
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, rowCount)
+	require.True(t, heartbeats >= 5)
+	require.False(t, concurrent)
+
+	ctx = goldilocks.WithHeartbeat(context.Background(), 10*time.Millisecond, func() error {
+		return errors.New("lease lost")
+	})
+	_, err = db.Query(ctx, "select pg_sleep(5)", nil, []interface{}{nil}, func() error { return nil })
+	require.EqualError(t, err, "lease lost")
+}
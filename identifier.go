@@ -0,0 +1,27 @@
+package goldilocks
+
+import (
+	"strings"
+)
+
+// Ident is a possibly qualified PostgreSQL identifier such as a table or column name. Identifiers cannot be query
+// params so Ident can be used to safely include them in dynamically built SQL.
+type Ident []string
+
+// Identifier returns an Ident of parts. Each part is one component of a qualified name such as the schema and table.
+func Identifier(parts ...string) Ident {
+	return Ident(parts)
+}
+
+// Sanitize returns ident quoted so it can be included in SQL. Each part is always quoted, which PostgreSQL treats the
+// same as quote_ident does. Double quotes in a part are escaped and NUL bytes, which PostgreSQL cannot store in an
+// identifier, are removed.
+func (ident Ident) Sanitize() string {
+	parts := make([]string, len(ident))
+	for i, part := range ident {
+		part = strings.ReplaceAll(part, `"`, `""`)
+		part = strings.ReplaceAll(part, "\x00", "")
+		parts[i] = `"` + part + `"`
+	}
+	return strings.Join(parts, ".")
+}
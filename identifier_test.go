@@ -0,0 +1,61 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifierSanitize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ident    goldilocks.Ident
+		expected string
+	}{
+		{goldilocks.Identifier("foo"), `"foo"`},
+		{goldilocks.Identifier("public", "users"), `"public"."users"`},
+		{goldilocks.Identifier("Mixed Case"), `"Mixed Case"`},
+		{goldilocks.Identifier(`a"b`), `"a""b"`},
+		{goldilocks.Identifier(`"; drop table users; --`), `"""; drop table users; --"`},
+		{goldilocks.Identifier("a\x00b"), `"ab"`},
+		{goldilocks.Ident{"db", "schema", "table"}, `"db"."schema"."table"`},
+	}
+
+	for i, tt := range tests {
+		require.Equalf(t, tt.expected, tt.ident.Sanitize(), "%d", i)
+	}
+}
+
+func TestIdentifierSanitizeQuery(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	table := goldilocks.Identifier("pg_temp", `odd "table" name`)
+	_, err = db.Exec(context.Background(), "create table "+table.Sanitize()+" (id int4)")
+	require.NoError(t, err)
+
+	_, err = db.Exec(context.Background(), "insert into "+table.Sanitize()+" values ($1)", int32(1))
+	require.NoError(t, err)
+
+	var quoted string
+	_, err = db.Query(
+		context.Background(),
+		"select quote_ident($1)",
+		[]interface{}{`odd "table" name`},
+		[]interface{}{&quoted},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, quoted, goldilocks.Identifier(`odd "table" name`).Sanitize())
+
+	ensurePgConnValid(t, pgConn)
+}
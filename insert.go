@@ -0,0 +1,147 @@
+package goldilocks
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Insert inserts one row into table. src is a struct, or a pointer to a struct, whose fields with a db tag are the
+// columns to insert, or a map[string]interface{} of columns to values. Map columns are inserted in sorted order.
+// Fields tagged db:"-" and fields without a db tag are not inserted.
+//
+// If returning is not empty it is used as the returning clause of the insert and the returned row is decoded into
+// results. returning is interpolated into SQL so it must be trusted. It returns the number of rows inserted.
+func Insert(ctx context.Context, db StdDB, table Ident, src interface{}, returning string, results []interface{}) (int64, error) {
+	columns, values, err := insertColumns(src)
+	if err != nil {
+		return 0, err
+	}
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("Insert requires at least one column")
+	}
+
+	names := make([]string, len(columns))
+	params := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = Identifier(c).Sanitize()
+		params[i] = "$" + strconv.Itoa(i+1)
+	}
+
+	sql := fmt.Sprintf("insert into %s (%s) values (%s)", table.Sanitize(), strings.Join(names, ", "), strings.Join(params, ", "))
+
+	if returning == "" {
+		return db.Exec(ctx, sql, values...)
+	}
+
+	return db.Query(ctx, sql+" returning "+returning, values, results, func() error { return nil })
+}
+
+// insertColumns returns the columns and values of src for Insert.
+func insertColumns(src interface{}) ([]string, []interface{}, error) {
+	if m, ok := src.(map[string]interface{}); ok {
+		columns := make([]string, 0, len(m))
+		for c := range m {
+			columns = append(columns, c)
+		}
+		sort.Strings(columns)
+
+		values := make([]interface{}, len(columns))
+		for i, c := range columns {
+			values[i] = m[c]
+		}
+		return columns, values, nil
+	}
+
+	v := reflect.ValueOf(src)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("Insert requires a struct or map[string]interface{}, got %T", src)
+	}
+
+	var columns []string
+	var values []interface{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		if tag, ok := sf.Tag.Lookup("db"); ok && tag != "-" {
+			columns = append(columns, tag)
+			values = append(values, v.Field(i).Interface())
+		}
+	}
+
+	return columns, values, nil
+}
+
+// maxParams is the maximum number of params in a statement. The protocol sends the number of params as an int16.
+const maxParams = 65535
+
+// InsertRows inserts rows into columns of table with multi-row insert statements. Each row must have one value per
+// column. Rows are inserted with as few statements as possible while keeping each statement within the limit of 65535
+// params. If more than one statement is required and one fails the rows inserted by earlier statements remain unless
+// InsertRows is called in a transaction. It returns the number of rows inserted. If rows is empty nothing is sent to
+// the server.
+//
+// InsertRows suits batches too small to benefit from CopyFrom. Unlike CopyFrom, values are converted to the column
+// types by the server as with any query param.
+func InsertRows(ctx context.Context, db StdDB, table Ident, columns []string, rows [][]interface{}) (int64, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("InsertRows requires at least one column")
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return 0, fmt.Errorf("rows[%d] has %d values but there are %d columns", i, len(row), len(columns))
+		}
+	}
+
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = Identifier(c).Sanitize()
+	}
+	prefix := fmt.Sprintf("insert into %s (%s) values ", table.Sanitize(), strings.Join(names, ", "))
+
+	rowsPerStatement := maxParams / len(columns)
+	var rowCount int64
+	for len(rows) > 0 {
+		chunk := rows
+		if len(chunk) > rowsPerStatement {
+			chunk = chunk[:rowsPerStatement]
+		}
+		rows = rows[len(chunk):]
+
+		sb := &strings.Builder{}
+		sb.WriteString(prefix)
+		args := make([]interface{}, 0, len(chunk)*len(columns))
+		for i, row := range chunk {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteByte('(')
+			for j, v := range row {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				args = append(args, v)
+				sb.WriteByte('$')
+				sb.WriteString(strconv.Itoa(len(args)))
+			}
+			sb.WriteByte(')')
+		}
+
+		n, err := db.Exec(ctx, sb.String(), args...)
+		rowCount += n
+		if err != nil {
+			return rowCount, err
+		}
+	}
+
+	return rowCount, nil
+}
@@ -0,0 +1,105 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+type insertWidget struct {
+	ID     int32  `db:"-"`
+	Name   string `db:"name"`
+	Weight int64  `db:"weight"`
+	Note   string
+}
+
+func TestInsert(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "create temporary table widgets (id serial primary key, name text not null, weight int8)")
+	require.NoError(t, err)
+
+	w := insertWidget{Name: "a", Weight: 3, Note: "not inserted"}
+	rowCount, err := goldilocks.Insert(
+		context.Background(),
+		db,
+		goldilocks.Identifier("widgets"),
+		&w,
+		"id",
+		[]interface{}{&w.ID},
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, rowCount)
+	require.EqualValues(t, 1, w.ID)
+
+	rowCount, err = goldilocks.Insert(
+		context.Background(),
+		db,
+		goldilocks.Identifier("widgets"),
+		map[string]interface{}{"name": "b", "weight": goldilocks.NullInt64{}},
+		"",
+		nil,
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, rowCount)
+
+	var name string
+	var weight goldilocks.NullInt64
+	_, err = db.Query(context.Background(), "select name, weight from widgets where id = 2", nil, []interface{}{&name, &weight}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, "b", name)
+	require.False(t, weight.Valid)
+
+	_, err = goldilocks.Insert(context.Background(), db, goldilocks.Identifier("widgets"), 1, "", nil)
+	require.EqualError(t, err, "Insert requires a struct or map[string]interface{}, got int")
+
+	_, err = goldilocks.Insert(context.Background(), db, goldilocks.Identifier("widgets"), struct{ Name string }{}, "", nil)
+	require.EqualError(t, err, "Insert requires at least one column")
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestInsertRows(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "create temporary table widgets (id int4 primary key, name text not null)")
+	require.NoError(t, err)
+
+	// More rows than fit in one statement.
+	rows := make([][]interface{}, 40000)
+	for i := range rows {
+		rows[i] = []interface{}{int32(i), "widget"}
+	}
+	rowCount, err := goldilocks.InsertRows(context.Background(), db, goldilocks.Identifier("widgets"), []string{"id", "name"}, rows)
+	require.NoError(t, err)
+	require.EqualValues(t, 40000, rowCount)
+
+	var count, sum int64
+	_, err = db.Query(context.Background(), "select count(*), sum(id) from widgets", nil, []interface{}{&count, &sum}, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 40000, count)
+	require.EqualValues(t, 39999*40000/2, sum)
+
+	rowCount, err = goldilocks.InsertRows(context.Background(), db, goldilocks.Identifier("widgets"), []string{"id", "name"}, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, rowCount)
+
+	_, err = goldilocks.InsertRows(context.Background(), db, goldilocks.Identifier("widgets"), []string{"id", "name"}, [][]interface{}{{int32(1)}})
+	require.EqualError(t, err, "rows[0] has 1 values but there are 2 columns")
+
+	ensurePgConnValid(t, pgConn)
+}
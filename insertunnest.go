@@ -0,0 +1,67 @@
+package goldilocks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// InsertUnnest inserts rows into table with a single statement. columns are the names of the columns to insert and
+// values holds the values of each column as a slice type supported by Array. All slices must have the same length and
+// the nth element of each slice is inserted as the nth row. Each slice is sent as one array param and expanded with
+// unnest so, unlike COPY, the inserted rows can be returned. Values are converted to the column types with assignment
+// casts.
+//
+// If returning is not empty it is used as the returning clause of the insert and each returned row is decoded into
+// results and passed to rowFunc as with Query. The rows are returned in the order of values. This makes it possible to
+// get generated keys such as serial ids for each inserted row. returning is interpolated into SQL so it must be trusted.
+//
+// It returns the number of rows inserted. If the slices are empty nothing is sent to the server.
+func InsertUnnest(ctx context.Context, db StdDB, table Ident, columns []string, values []interface{}, returning string, results []interface{}, rowFunc func() error) (int64, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("InsertUnnest requires at least one column")
+	}
+	if len(values) != len(columns) {
+		return 0, fmt.Errorf("InsertUnnest has %d columns but %d values", len(columns), len(values))
+	}
+
+	rowCount := -1
+	for i, v := range values {
+		_, _, n, _, err := arrayElements(v)
+		if err != nil {
+			return 0, fmt.Errorf("values[%d]: %w", i, err)
+		}
+		if rowCount == -1 {
+			rowCount = n
+		} else if n != rowCount {
+			return 0, fmt.Errorf("values[%d] has %d elements but values[0] has %d", i, n, rowCount)
+		}
+	}
+	if rowCount == 0 {
+		return 0, nil
+	}
+
+	names := make([]string, len(columns))
+	params := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = Identifier(c).Sanitize()
+		params[i] = "$" + strconv.Itoa(i+1)
+	}
+
+	// Inserting in the order of ordinality keeps the returned rows in the order of values.
+	sql := fmt.Sprintf(
+		"insert into %s (%s) select %s from unnest(%s) with ordinality as u(%s, goldilocks_ordinality) order by goldilocks_ordinality",
+		table.Sanitize(),
+		strings.Join(names, ", "),
+		strings.Join(names, ", "),
+		strings.Join(params, ", "),
+		strings.Join(names, ", "),
+	)
+
+	if returning == "" {
+		return db.Exec(ctx, sql, values...)
+	}
+
+	return db.Query(ctx, sql+" returning "+returning, values, results, rowFunc)
+}
@@ -0,0 +1,86 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertUnnest(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "create temporary table widgets (id serial primary key, name text not null, weight int8)")
+	require.NoError(t, err)
+
+	names := []string{"c", "a", "b"}
+	var id int32
+	var name string
+	var ids []int32
+	var returnedNames []string
+	rowCount, err := goldilocks.InsertUnnest(
+		context.Background(),
+		db,
+		goldilocks.Identifier("widgets"),
+		[]string{"name", "weight"},
+		[]interface{}{names, []int32{3, 1, 2}},
+		"id, name",
+		[]interface{}{&id, &name},
+		func() error {
+			ids = append(ids, id)
+			returnedNames = append(returnedNames, name)
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, rowCount)
+	require.Equal(t, []int32{1, 2, 3}, ids)
+	require.Equal(t, names, returnedNames)
+
+	rowCount, err = goldilocks.InsertUnnest(
+		context.Background(),
+		db,
+		goldilocks.Identifier("widgets"),
+		[]string{"name"},
+		[]interface{}{[]string{"d", "e"}},
+		"",
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, rowCount)
+
+	var sum int64
+	_, err = db.Query(context.Background(), "select sum(weight)::int8 from widgets where id <= 3", nil, []interface{}{&sum}, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 6, sum)
+
+	rowCount, err = goldilocks.InsertUnnest(context.Background(), db, goldilocks.Identifier("widgets"), []string{"name"}, []interface{}{[]string{}}, "", nil, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, rowCount)
+
+	_, err = goldilocks.InsertUnnest(
+		context.Background(),
+		db,
+		goldilocks.Identifier("widgets"),
+		[]string{"name", "weight"},
+		[]interface{}{[]string{"f"}, []int64{1, 2}},
+		"",
+		nil,
+		nil,
+	)
+	require.EqualError(t, err, "values[1] has 2 elements but values[0] has 1")
+
+	_, err = goldilocks.InsertUnnest(context.Background(), db, goldilocks.Identifier("widgets"), []string{"name"}, []interface{}{[]byte("f")}, "", nil, nil)
+	require.EqualError(t, err, "values[0]: Array does not support elements of type []uint8")
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,41 @@
+package goldilocks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// WriteJSONArray executes the query sql with args on db and writes its rows to w as a JSON array with one object per
+// row. The keys of each object are the column names. Rows are written to w as they are received so a slow w slows the
+// query rather than buffering the results. It returns the number of rows written.
+//
+// sql is used as a subquery so it must be a query that can be used in a from clause. If an error occurs after rows
+// have been written the output is incomplete.
+func WriteJSONArray(ctx context.Context, db StdDB, w io.Writer, sql string, args ...interface{}) (int64, error) {
+	var row json.RawMessage
+	buf := []byte{'['}
+	rowCount, err := db.Query(
+		ctx,
+		"select row_to_json(t) from ("+sql+") t",
+		args,
+		[]interface{}{&row},
+		func() error {
+			buf = append(buf, row...)
+			_, err := w.Write(buf)
+			buf = append(buf[:0], ',')
+			return err
+		},
+	)
+	if err != nil {
+		return rowCount, err
+	}
+
+	if rowCount == 0 {
+		buf = append(buf, ']')
+	} else {
+		buf[0] = ']'
+	}
+	_, err = w.Write(buf)
+	return rowCount, err
+}
@@ -0,0 +1,60 @@
+package goldilocks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.n--
+	if w.n < 0 {
+		return 0, errors.New("write failed")
+	}
+	return len(p), nil
+}
+
+func TestWriteJSONArray(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	buf := &bytes.Buffer{}
+	rowCount, err := goldilocks.WriteJSONArray(
+		context.Background(),
+		db,
+		buf,
+		"select n as id, 'item ' || n as name, n % 2 = 0 as even from generate_series(1, $1::int4) n",
+		int32(3),
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, rowCount)
+	require.JSONEq(t,
+		`[{"id":1,"name":"item 1","even":false},{"id":2,"name":"item 2","even":true},{"id":3,"name":"item 3","even":false}]`,
+		buf.String(),
+	)
+
+	buf.Reset()
+	rowCount, err = goldilocks.WriteJSONArray(context.Background(), db, buf, "select 1 where false")
+	require.NoError(t, err)
+	require.EqualValues(t, 0, rowCount)
+	require.Equal(t, "[]", buf.String())
+
+	_, err = goldilocks.WriteJSONArray(context.Background(), db, &failingWriter{n: 1}, "select n from generate_series(1, 10) n")
+	require.EqualError(t, err, "write failed")
+
+	ensurePgConnValid(t, pgConn)
+}
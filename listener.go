@@ -0,0 +1,187 @@
+package goldilocks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+// Listener receives notifications sent with NOTIFY on a connection of its own. Handlers are subscribed to channels with
+// Subscribe and are called by Run. If the connection is lost Run reconnects with exponential backoff and listens to
+// all subscribed channels again. Notifications sent while the Listener is disconnected are lost.
+type Listener struct {
+	// MinReconnectDelay is the delay before the first reconnect attempt. It doubles after each failed attempt up to
+	// MaxReconnectDelay. They default to 1 second and 1 minute.
+	MinReconnectDelay time.Duration
+	MaxReconnectDelay time.Duration
+
+	// OnError is called with each error that causes Run to reconnect. It is optional.
+	OnError func(err error)
+
+	config *pgconn.Config
+
+	mux      sync.Mutex
+	handlers map[string]func(*pgconn.Notification)
+
+	// wake is signaled when handlers change so Run can stop waiting for a notification and LISTEN or UNLISTEN.
+	wake chan struct{}
+}
+
+// NewListener returns a Listener that connects with connString.
+func NewListener(connString string) (*Listener, error) {
+	config, err := pgconn.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+	return NewListenerConfig(config), nil
+}
+
+// NewListenerConfig returns a Listener that connects with a copy of config. config.OnNotification is replaced.
+func NewListenerConfig(config *pgconn.Config) *Listener {
+	l := &Listener{
+		MinReconnectDelay: time.Second,
+		MaxReconnectDelay: time.Minute,
+		handlers:          make(map[string]func(*pgconn.Notification)),
+		wake:              make(chan struct{}, 1),
+	}
+	l.config = config.Copy()
+	l.config.OnNotification = func(_ *pgconn.PgConn, n *pgconn.Notification) {
+		l.mux.Lock()
+		handler := l.handlers[n.Channel]
+		l.mux.Unlock()
+		if handler != nil {
+			handler(n)
+		}
+	}
+	return l
+}
+
+// Subscribe calls handler with each notification sent to channel. It replaces any handler already subscribed to
+// channel. Handlers are called one at a time from the goroutine executing Run so they should not block for long.
+// Subscribe may be called before or while Run is executing.
+func (l *Listener) Subscribe(channel string, handler func(*pgconn.Notification)) {
+	l.mux.Lock()
+	l.handlers[channel] = handler
+	l.mux.Unlock()
+	l.signal()
+}
+
+// Unsubscribe stops notifications sent to channel from being handled.
+func (l *Listener) Unsubscribe(channel string) {
+	l.mux.Lock()
+	delete(l.handlers, channel)
+	l.mux.Unlock()
+	l.signal()
+}
+
+func (l *Listener) signal() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run connects, listens to the subscribed channels, and calls handlers until ctx is canceled. It reconnects whenever
+// the connection is lost. Run returns ctx.Err(). It must not be executed concurrently.
+func (l *Listener) Run(ctx context.Context) error {
+	delay := l.MinReconnectDelay
+	for {
+		err := l.listen(ctx, func() { delay = l.MinReconnectDelay })
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if l.OnError != nil {
+			l.OnError(err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if delay > l.MaxReconnectDelay {
+			delay = l.MaxReconnectDelay
+		}
+	}
+}
+
+// listen connects and handles notifications until an error occurs. connected is called once the connection is
+// established.
+func (l *Listener) listen(ctx context.Context, connected func()) error {
+	pgConn, err := pgconn.ConnectConfig(ctx, l.config)
+	if err != nil {
+		return err
+	}
+	defer pgConn.Close(context.Background())
+	connected()
+
+	listening := make(map[string]struct{})
+	for {
+		l.mux.Lock()
+		channels := make(map[string]struct{}, len(l.handlers))
+		for channel := range l.handlers {
+			channels[channel] = struct{}{}
+		}
+		l.mux.Unlock()
+
+		for channel := range channels {
+			if _, ok := listening[channel]; !ok {
+				_, err := pgConn.Exec(ctx, "listen "+Identifier(channel).Sanitize()).ReadAll()
+				if err != nil {
+					return fmt.Errorf("listen %s: %w", channel, err)
+				}
+				listening[channel] = struct{}{}
+			}
+		}
+		for channel := range listening {
+			if _, ok := channels[channel]; !ok {
+				_, err := pgConn.Exec(ctx, "unlisten "+Identifier(channel).Sanitize()).ReadAll()
+				if err != nil {
+					return fmt.Errorf("unlisten %s: %w", channel, err)
+				}
+				delete(listening, channel)
+			}
+		}
+
+		err := l.waitForNotification(ctx, pgConn)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// waitForNotification waits until a notification is handled or the handlers change. Interrupting the wait only
+// sets a deadline on the connection so it remains usable.
+func (l *Listener) waitForNotification(ctx context.Context, pgConn *pgconn.PgConn) error {
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	woken := make(chan struct{})
+	go func() {
+		select {
+		case <-l.wake:
+			close(woken)
+			cancel()
+		case <-waitCtx.Done():
+		}
+	}()
+
+	err := pgConn.WaitForNotification(waitCtx)
+	if err != nil {
+		select {
+		case <-woken:
+			if ctx.Err() == nil && !pgConn.IsClosed() {
+				return nil
+			}
+		default:
+		}
+	}
+	return err
+}
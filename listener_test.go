@@ -0,0 +1,75 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	config.RuntimeParams["application_name"] = "goldilocks_test_listener"
+	listener := goldilocks.NewListenerConfig(config)
+	listener.MinReconnectDelay = 10 * time.Millisecond
+
+	fooCh := make(chan string, 100)
+	barCh := make(chan string, 100)
+	listener.Subscribe("foo", func(n *pgconn.Notification) { fooCh <- n.Payload })
+	listener.Subscribe("bar channel", func(n *pgconn.Notification) { barCh <- n.Payload })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error)
+	go func() { runErr <- listener.Run(ctx) }()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+
+	// Notifications sent before the Listener is listening are lost so keep sending until one is received.
+	notifyUntilReceived := func(channel, payload string, received chan string) {
+		for {
+			_, err := pgConn.ExecParams(
+				context.Background(),
+				"select pg_notify($1, $2)",
+				[][]byte{[]byte(channel), []byte(payload)},
+				nil, nil, nil,
+			).Close()
+			require.NoError(t, err)
+			select {
+			case p := <-received:
+				require.Equal(t, payload, p)
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}
+
+	notifyUntilReceived("foo", "a", fooCh)
+	notifyUntilReceived("bar channel", "b", barCh)
+
+	// Terminate the Listener's connection to make it reconnect.
+	_, err = pgConn.ExecParams(
+		context.Background(),
+		"select pg_terminate_backend(pid) from pg_stat_activity where application_name = 'goldilocks_test_listener'",
+		nil, nil, nil, nil,
+	).Close()
+	require.NoError(t, err)
+
+	notifyUntilReceived("foo", "c", fooCh)
+
+	listener.Unsubscribe("foo")
+	notifyUntilReceived("bar channel", "d", barCh)
+
+	cancel()
+	require.Equal(t, context.Canceled, <-runErr)
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,86 @@
+package goldilocks
+
+import (
+	"context"
+	"time"
+)
+
+// LogLevel is the severity of a log entry. Higher levels are more verbose.
+type LogLevel int
+
+const (
+	LogLevelTrace = LogLevel(6)
+	LogLevelDebug = LogLevel(5)
+	LogLevelInfo  = LogLevel(4)
+	LogLevelWarn  = LogLevel(3)
+	LogLevelError = LogLevel(2)
+	LogLevelNone  = LogLevel(1)
+)
+
+func (ll LogLevel) String() string {
+	switch ll {
+	case LogLevelTrace:
+		return "trace"
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	case LogLevelNone:
+		return "none"
+	default:
+		return "invalid"
+	}
+}
+
+// Logger is the interface used to log the queries executed by a Conn. data contains the details of the entry. It is
+// owned by Log and not modified after Log returns. Log must be safe for concurrent use if the Logger is shared by
+// multiple Conns, such as those of a Pool.
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, data map[string]interface{})
+}
+
+// LoggerFunc is a function that implements Logger.
+type LoggerFunc func(ctx context.Context, level LogLevel, msg string, data map[string]interface{})
+
+// Log calls f.
+func (f LoggerFunc) Log(ctx context.Context, level LogLevel, msg string, data map[string]interface{}) {
+	f(ctx, level, msg, data)
+}
+
+// logQuery logs a query or exec that started at start and has just finished. Successful queries are logged at
+// LogLevelInfo and failed queries at LogLevelError. The query name of ctx is logged if it has one.
+func (c *Conn) logQuery(ctx context.Context, msg string, sql string, args []interface{}, start time.Time, rowCount int64, err error) {
+	level := LogLevelInfo
+	if err != nil {
+		level = LogLevelError
+	}
+	if c.config.Logger == nil || level > c.logLevel() {
+		return
+	}
+
+	data := map[string]interface{}{
+		"sql":      sql,
+		"args":     args,
+		"time":     time.Since(start),
+		"rowCount": rowCount,
+	}
+	if name := queryName(ctx); name != "" {
+		data["name"] = name
+	}
+	if err != nil {
+		data["err"] = err
+	}
+	c.config.Logger.Log(ctx, level, msg, data)
+}
+
+// logLevel returns the most verbose level c logs.
+func (c *Conn) logLevel() LogLevel {
+	if c.config.LogLevel == 0 {
+		return LogLevelInfo
+	}
+	return c.config.LogLevel
+}
@@ -0,0 +1,93 @@
+package goldilocks_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogEntry struct {
+	level goldilocks.LogLevel
+	msg   string
+	data  map[string]interface{}
+}
+
+type testLogger struct {
+	mux     sync.Mutex
+	entries []testLogEntry
+}
+
+func (l *testLogger) Log(ctx context.Context, level goldilocks.LogLevel, msg string, data map[string]interface{}) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.entries = append(l.entries, testLogEntry{level: level, msg: msg, data: data})
+}
+
+func TestConnConfigLogger(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+
+	logger := &testLogger{}
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{Logger: logger})
+
+	var n int32
+	_, err = db.Query(context.Background(), "select generate_series(1, $1)", []interface{}{int32(3)}, []interface{}{&n}, func() error { return nil })
+	require.NoError(t, err)
+
+	_, err = db.Exec(context.Background(), "select 1 / $1", int32(0))
+	require.Error(t, err)
+
+	require.Len(t, logger.entries, 2)
+
+	require.Equal(t, goldilocks.LogLevelInfo, logger.entries[0].level)
+	require.Equal(t, "Query", logger.entries[0].msg)
+	require.Equal(t, "select generate_series(1, $1)", logger.entries[0].data["sql"])
+	require.Equal(t, []interface{}{int32(3)}, logger.entries[0].data["args"])
+	require.EqualValues(t, 3, logger.entries[0].data["rowCount"])
+	require.IsType(t, time.Duration(0), logger.entries[0].data["time"])
+	require.NotContains(t, logger.entries[0].data, "err")
+
+	require.Equal(t, goldilocks.LogLevelError, logger.entries[1].level)
+	require.Equal(t, "Exec", logger.entries[1].msg)
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(logger.entries[1].data["err"].(error), &pgErr))
+	require.Equal(t, "22012", pgErr.Code)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestConnConfigLogLevel(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+
+	var entries []string
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{
+		Logger: goldilocks.LoggerFunc(func(ctx context.Context, level goldilocks.LogLevel, msg string, data map[string]interface{}) {
+			entries = append(entries, level.String()+" "+msg)
+		}),
+		LogLevel: goldilocks.LogLevelError,
+	})
+
+	_, err = db.Exec(context.Background(), "select 1")
+	require.NoError(t, err)
+
+	_, err = db.Exec(context.Background(), "select 1 / 0")
+	require.Error(t, err)
+
+	require.Equal(t, []string{"error Exec"}, entries)
+
+	ensurePgConnValid(t, pgConn)
+}
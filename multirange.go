@@ -0,0 +1,120 @@
+package goldilocks
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/jackc/pgio"
+)
+
+// PostgreSQL oids for multirange types. Multiranges require PostgreSQL 14 or later.
+const (
+	int4multirangeOID = 4451
+	nummultirangeOID  = 4532
+	tstzmultirangeOID = 4534
+	datemultirangeOID = 4535
+	int8multirangeOID = 4536
+)
+
+// multirangeOIDs maps range type oids to their multirange type oids.
+var multirangeOIDs = map[uint32]uint32{
+	int4rangeOID: int4multirangeOID,
+	numrangeOID:  nummultirangeOID,
+	tstzrangeOID: tstzmultirangeOID,
+	daterangeOID: datemultirangeOID,
+	int8rangeOID: int8multirangeOID,
+}
+
+// Multirange represents a PostgreSQL multirange such as an int4multirange or tstzmultirange. The PostgreSQL type of a
+// param is determined by the type of its ranges as with Range. A Multirange with no typed ranges must be cast in the
+// query (e.g. $1::int4multirange). A nil Multirange is encoded as NULL.
+type Multirange []Range
+
+func (mr Multirange) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if mr == nil {
+		return nil, 0, binaryFormat, nil
+	}
+
+	buf = pgio.AppendInt32(buf, int32(len(mr)))
+
+	var rangeOID uint32
+	for i, r := range mr {
+		sp := len(buf)
+		buf = pgio.AppendInt32(buf, -1)
+
+		value, oid, _, err := r.EncodeParam(buf)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("multirange range %d: %w", i, err)
+		}
+		if oid != 0 {
+			if rangeOID != 0 && oid != rangeOID {
+				return nil, 0, 0, fmt.Errorf("multirange ranges must be the same type, range %d differs", i)
+			}
+			rangeOID = oid
+		}
+
+		buf = value
+		pgio.SetInt32(buf[sp:], int32(len(buf[sp:])-4))
+	}
+
+	return buf, multirangeOIDs[rangeOID], binaryFormat, nil
+}
+
+// MultirangeResult returns a result decoder that decodes a multirange into dst. newRangeResult must be the range result
+// function for the range type of the multirange, such as Int4RangeResult for an int4multirange. NULL is decoded as a
+// nil Multirange.
+func MultirangeResult(dst *Multirange, newRangeResult func(*Range) ResultDecoder) ResultDecoder {
+	return &multirangeResult{dst: dst, newRangeResult: newRangeResult}
+}
+
+type multirangeResult struct {
+	dst            *Multirange
+	newRangeResult func(*Range) ResultDecoder
+}
+
+func (*multirangeResult) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (mrr *multirangeResult) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*mrr.dst = nil
+		return nil
+	}
+
+	if len(buf) < 4 {
+		return fmt.Errorf("multirange requires data length of at least 4, got %d", len(buf))
+	}
+	n := int(int32(binary.BigEndian.Uint32(buf)))
+	buf = buf[4:]
+
+	// Each range requires at least its 4 byte length and 1 byte of flags. Checking this before allocating prevents a
+	// corrupt count from causing a huge allocation.
+	if n < 0 || len(buf) < n*5 {
+		return fmt.Errorf("multirange range count of %d exceeds data length of %d", n, len(buf))
+	}
+
+	mr := make(Multirange, n)
+	for i := range mr {
+		if len(buf) < 4 {
+			return fmt.Errorf("multirange range %d is missing", i)
+		}
+		rangeLen := int(int32(binary.BigEndian.Uint32(buf)))
+		buf = buf[4:]
+		if rangeLen < 0 || len(buf) < rangeLen {
+			return fmt.Errorf("multirange range %d requires data length of %d, got %d", i, rangeLen, len(buf))
+		}
+
+		err := mrr.newRangeResult(&mr[i]).DecodeResult(buf[:rangeLen])
+		if err != nil {
+			return fmt.Errorf("multirange range %d: %w", i, err)
+		}
+		buf = buf[rangeLen:]
+	}
+	if len(buf) != 0 {
+		return fmt.Errorf("multirange has %d bytes of unexpected trailing data", len(buf))
+	}
+
+	*mrr.dst = mr
+	return nil
+}
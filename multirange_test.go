@@ -0,0 +1,67 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultirange(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	serverVersion, err := strconv.Atoi(pgConn.ParameterStatus("server_version_num"))
+	require.NoError(t, err)
+	if serverVersion < 140000 {
+		t.Skipf("multiranges require PostgreSQL 14, server_version_num is %d", serverVersion)
+	}
+
+	param := goldilocks.Multirange{
+		{Lower: int32(1), Upper: int32(3), LowerInclusive: true},
+		{Lower: int32(10), UpperInfinite: true, LowerInclusive: true},
+	}
+
+	var text string
+	var result goldilocks.Multirange
+	_, err = db.Query(
+		context.Background(),
+		"select $1::text, $1",
+		[]interface{}{param},
+		[]interface{}{&text, goldilocks.MultirangeResult(&result, goldilocks.Int4RangeResult)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, "{[1,3),[10,)}", text)
+	require.Equal(t, param, result)
+
+	_, err = db.Query(
+		context.Background(),
+		"select $1::int8multirange",
+		[]interface{}{goldilocks.Multirange{}},
+		[]interface{}{goldilocks.MultirangeResult(&result, goldilocks.Int8RangeResult)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.Multirange{}, result)
+
+	_, err = db.Query(
+		context.Background(),
+		"select null::int4multirange",
+		nil,
+		[]interface{}{goldilocks.MultirangeResult(&result, goldilocks.Int4RangeResult)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,44 @@
+package goldilocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgconn"
+)
+
+// noticeCaptures maps each *pgconn.PgConn executing ExecNotices to the *[]*pgconn.Notice its notices are appended to.
+var noticeCaptures sync.Map
+
+// EnableNoticeCapture configures config so Conn.ExecNotices can capture the notices received while executing a
+// statement. Any OnNotice handler already in config is still called for every notice.
+func EnableNoticeCapture(config *pgconn.Config) {
+	onNotice := config.OnNotice
+	config.OnNotice = func(pgConn *pgconn.PgConn, notice *pgconn.Notice) {
+		if notices, ok := noticeCaptures.Load(pgConn); ok {
+			*notices.(*[]*pgconn.Notice) = append(*notices.(*[]*pgconn.Notice), notice)
+		}
+
+		if onNotice != nil {
+			onNotice(pgConn, notice)
+		}
+	}
+}
+
+// ExecNotices is like Exec but also returns the notices received while executing sql, such as the output of VACUUM
+// VERBOSE. Notices are returned even if an error occurs. The underlying *pgconn.PgConn must have been connected with a
+// config passed to EnableNoticeCapture. Otherwise no notices are returned.
+func (c *Conn) ExecNotices(ctx context.Context, sql string, args ...interface{}) (int64, []*pgconn.Notice, error) {
+	err := c.acquire(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer c.release()
+
+	var notices []*pgconn.Notice
+	noticeCaptures.Store(c.pgconn, &notices)
+	defer noticeCaptures.Delete(c.pgconn)
+
+	rowsAffected, err := c.exec(ctx, sql, nil, args...)
+	return rowsAffected, notices, err
+}
@@ -0,0 +1,60 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnExecNotices(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+
+	var handledNotices []*pgconn.Notice
+	config.OnNotice = func(_ *pgconn.PgConn, notice *pgconn.Notice) {
+		handledNotices = append(handledNotices, notice)
+	}
+	goldilocks.EnableNoticeCapture(config)
+
+	pgConn, err := pgconn.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	sql := `do $$
+begin
+  raise notice 'first';
+  raise notice 'second';
+end$$`
+
+	rowsAffected, notices, err := db.ExecNotices(context.Background(), sql)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, rowsAffected)
+	require.Len(t, notices, 2)
+	require.Equal(t, "first", notices[0].Message)
+	require.Equal(t, "second", notices[1].Message)
+	require.Len(t, handledNotices, 2)
+
+	// Notices outside of ExecNotices are not captured.
+	_, err = db.Exec(context.Background(), sql)
+	require.NoError(t, err)
+	require.Len(t, notices, 2)
+	require.Len(t, handledNotices, 4)
+
+	_, notices, err = db.ExecNotices(context.Background(), `do $$
+begin
+  raise notice 'before error';
+  raise exception 'boom';
+end$$`)
+	require.Error(t, err)
+	require.Len(t, notices, 1)
+	require.Equal(t, "before error", notices[0].Message)
+
+	ensurePgConnValid(t, pgConn)
+}
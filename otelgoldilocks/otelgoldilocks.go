@@ -0,0 +1,210 @@
+// Package otelgoldilocks instruments goldilocks with OpenTelemetry. Tracer creates a span for each operation of a Conn
+// or Pool with the semantic db.* attributes. RecordPoolStats records the statistics of a Pool as metrics.
+package otelgoldilocks
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/goldilocks"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/jackc/goldilocks/otelgoldilocks"
+
+const (
+	// RowCountKey is the attribute of the rows returned by a query or affected by an exec or copy.
+	RowCountKey = attribute.Key("goldilocks.row_count")
+
+	// ArgCountKey is the attribute of the number of args of a query or exec.
+	ArgCountKey = attribute.Key("goldilocks.arg_count")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	attrs          []attribute.KeyValue
+}
+
+// Option configures a Tracer or RecordPoolStats.
+type Option func(*config)
+
+// WithTracerProvider sets the TracerProvider used by a Tracer. The default is the global TracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the MeterProvider used by RecordPoolStats. The default is the global MeterProvider.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithAttributes adds attrs to every span or metric. This is useful for attributes the hooks cannot know such as
+// semconv.DBName or semconv.ServerAddress.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attrs = append(c.attrs, attrs...)
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Tracer is a goldilocks.Tracer that creates an OpenTelemetry span for each traced operation. Set it as the Tracer of
+// a ConnConfig or PoolConfig.
+type Tracer struct {
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+}
+
+// NewTracer creates a Tracer.
+func NewTracer(opts ...Option) *Tracer {
+	c := newConfig(opts)
+	return &Tracer{
+		tracer: c.tracerProvider.Tracer(instrumentationName),
+		attrs:  append([]attribute.KeyValue{semconv.DBSystemPostgreSQL}, c.attrs...),
+	}
+}
+
+// Before starts a span for the operation described by data.
+func (t *Tracer) Before(ctx context.Context, data goldilocks.TraceData) context.Context {
+	attrs := make([]attribute.KeyValue, 0, len(t.attrs)+3)
+	attrs = append(attrs, t.attrs...)
+
+	name := data.Op.String()
+	spanKind := trace.SpanKindClient
+	switch data.Op {
+	case goldilocks.TraceQuery, goldilocks.TraceExec, goldilocks.TraceCopyFrom:
+		attrs = append(attrs, semconv.DBStatement(data.SQL), ArgCountKey.Int(data.ArgCount))
+		if op := sqlOperation(data.SQL); op != "" {
+			attrs = append(attrs, semconv.DBOperation(op))
+			name = op
+		}
+	case goldilocks.TraceAcquire, goldilocks.TraceRelease:
+		// Acquiring and releasing connections does not necessarily communicate with the server.
+		spanKind = trace.SpanKindInternal
+	}
+
+	ctx, _ = t.tracer.Start(ctx, name, trace.WithSpanKind(spanKind), trace.WithAttributes(attrs...))
+	return ctx
+}
+
+// After ends the span started by Before. If the operation failed the error is recorded and the status of the span is
+// set to error.
+func (t *Tracer) After(ctx context.Context, data goldilocks.TraceData) {
+	span := trace.SpanFromContext(ctx)
+
+	switch data.Op {
+	case goldilocks.TraceQuery, goldilocks.TraceExec, goldilocks.TraceCopyFrom:
+		span.SetAttributes(RowCountKey.Int64(data.RowCount))
+	}
+
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+
+	span.End()
+}
+
+// sqlOperation returns the first keyword of sql in upper case such as SELECT or INSERT. It returns "" if sql does not
+// start with a keyword.
+func sqlOperation(sql string) string {
+	sql = strings.TrimLeft(sql, " \t\r\n(")
+	end := 0
+	for end < len(sql) {
+		b := sql[end]
+		if !(b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z') {
+			break
+		}
+		end++
+	}
+	return strings.ToUpper(sql[:end])
+}
+
+// RecordPoolStats records the statistics of pool as OpenTelemetry metrics each time the metrics are collected. The
+// metrics are recorded until the returned Registration is unregistered.
+func RecordPoolStats(pool *goldilocks.Pool, opts ...Option) (metric.Registration, error) {
+	c := newConfig(opts)
+	meter := c.meterProvider.Meter(instrumentationName)
+	attrs := metric.WithAttributes(c.attrs...)
+
+	acquiredConns, err := meter.Int64ObservableUpDownCounter("goldilocks.pool.acquired_conns", metric.WithDescription("Number of connections currently acquired from the pool."))
+	if err != nil {
+		return nil, err
+	}
+	idleConns, err := meter.Int64ObservableUpDownCounter("goldilocks.pool.idle_conns", metric.WithDescription("Number of idle connections in the pool."))
+	if err != nil {
+		return nil, err
+	}
+	constructingConns, err := meter.Int64ObservableUpDownCounter("goldilocks.pool.constructing_conns", metric.WithDescription("Number of connections being established."))
+	if err != nil {
+		return nil, err
+	}
+	totalConns, err := meter.Int64ObservableUpDownCounter("goldilocks.pool.total_conns", metric.WithDescription("Total number of connections in the pool."))
+	if err != nil {
+		return nil, err
+	}
+	maxConns, err := meter.Int64ObservableUpDownCounter("goldilocks.pool.max_conns", metric.WithDescription("Maximum size of the pool."))
+	if err != nil {
+		return nil, err
+	}
+	acquireCount, err := meter.Int64ObservableCounter("goldilocks.pool.acquires", metric.WithDescription("Cumulative number of successful acquires."))
+	if err != nil {
+		return nil, err
+	}
+	canceledAcquireCount, err := meter.Int64ObservableCounter("goldilocks.pool.canceled_acquires", metric.WithDescription("Cumulative number of acquires canceled by a context."))
+	if err != nil {
+		return nil, err
+	}
+	emptyAcquireCount, err := meter.Int64ObservableCounter("goldilocks.pool.empty_acquires", metric.WithDescription("Cumulative number of successful acquires that waited for a connection."))
+	if err != nil {
+		return nil, err
+	}
+	acquireDuration, err := meter.Float64ObservableCounter("goldilocks.pool.acquire_duration", metric.WithUnit("s"), metric.WithDescription("Cumulative time spent acquiring connections."))
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(
+		func(ctx context.Context, o metric.Observer) error {
+			stats := pool.PoolStats()
+			o.ObserveInt64(acquiredConns, int64(stats.AcquiredConns()), attrs)
+			o.ObserveInt64(idleConns, int64(stats.IdleConns()), attrs)
+			o.ObserveInt64(constructingConns, int64(stats.ConstructingConns()), attrs)
+			o.ObserveInt64(totalConns, int64(stats.TotalConns()), attrs)
+			o.ObserveInt64(maxConns, int64(stats.MaxConns()), attrs)
+			o.ObserveInt64(acquireCount, stats.AcquireCount(), attrs)
+			o.ObserveInt64(canceledAcquireCount, stats.CanceledAcquireCount(), attrs)
+			o.ObserveInt64(emptyAcquireCount, stats.EmptyAcquireCount(), attrs)
+			o.ObserveFloat64(acquireDuration, stats.AcquireDuration().Seconds(), attrs)
+			return nil
+		},
+		acquiredConns,
+		idleConns,
+		constructingConns,
+		totalConns,
+		maxConns,
+		acquireCount,
+		canceledAcquireCount,
+		emptyAcquireCount,
+		acquireDuration,
+	)
+}
@@ -0,0 +1,90 @@
+package otelgoldilocks_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/goldilocks/otelgoldilocks"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tracer := otelgoldilocks.NewTracer(
+		otelgoldilocks.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))),
+		otelgoldilocks.WithAttributes(semconv.DBName("test")),
+	)
+
+	data := goldilocks.TraceData{Op: goldilocks.TraceQuery, SQL: "  select * from widgets where id = $1", ArgCount: 1}
+	ctx := tracer.Before(context.Background(), data)
+	data.RowCount = 1
+	tracer.After(ctx, data)
+
+	data = goldilocks.TraceData{Op: goldilocks.TraceCommit}
+	ctx = tracer.Before(context.Background(), data)
+	data.Err = errors.New("commit failed")
+	tracer.After(ctx, data)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "SELECT", spans[0].Name())
+	require.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	require.ElementsMatch(t, []attribute.KeyValue{
+		semconv.DBSystemPostgreSQL,
+		semconv.DBName("test"),
+		semconv.DBStatement("  select * from widgets where id = $1"),
+		semconv.DBOperation("SELECT"),
+		otelgoldilocks.ArgCountKey.Int(1),
+		otelgoldilocks.RowCountKey.Int64(1),
+	}, spans[0].Attributes())
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	require.Equal(t, "commit", spans[1].Name())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Equal(t, "commit failed", spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+}
+
+func TestRecordPoolStats(t *testing.T) {
+	config, err := goldilocks.ParsePoolConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	config.MaxConns = 7
+
+	pool, err := goldilocks.NewPoolConfig(config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	reader := sdkmetric.NewManualReader()
+	reg, err := otelgoldilocks.RecordPoolStats(pool, otelgoldilocks.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	err = reader.Collect(context.Background(), &rm)
+	require.NoError(t, err)
+
+	values := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+				values[m.Name] = sum.DataPoints[0].Value
+			}
+		}
+	}
+	require.EqualValues(t, 7, values["goldilocks.pool.max_conns"])
+	require.EqualValues(t, 0, values["goldilocks.pool.acquired_conns"])
+	require.Contains(t, values, "goldilocks.pool.acquires")
+
+	require.NoError(t, reg.Unregister())
+}
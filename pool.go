@@ -2,8 +2,11 @@ package goldilocks
 
 import (
 	"context"
+	"io"
 	"runtime"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgconn"
@@ -16,21 +19,40 @@ var defaultMinConns = int32(0)
 var defaultMaxConnLifetime = time.Hour
 var defaultMaxConnIdleTime = time.Minute * 5
 var defaultHealthCheckPeriod = time.Minute
+var defaultHealthCheckConcurrency = int32(4)
+
+// ErrAcquireTimeout is returned when a connection could not be acquired from a pool within PoolConfig.AcquireTimeout.
+var ErrAcquireTimeout = errors.New("timed out acquiring connection from pool")
 
 type Pool struct {
-	p                 *puddle.Pool
-	config            *PoolConfig
-	minConns          int32
-	maxConnLifetime   time.Duration
-	maxConnIdleTime   time.Duration
-	healthCheckPeriod time.Duration
-	closeChan         chan struct{}
+	p                      *puddle.Pool
+	config                 *PoolConfig
+	minConns               int32
+	maxConnLifetime        time.Duration
+	maxConnIdleTime        time.Duration
+	healthCheckPeriod      time.Duration
+	healthCheckTimeout     time.Duration
+	healthCheckConcurrency int32
+	acquireTimeout         time.Duration
+	closeChan              chan struct{}
+
+	// healthCheckChan triggers a check of MinConns between periodic health checks. It has a buffer of 1 so triggering
+	// never blocks and multiple events before the check runs cause a single check.
+	healthCheckChan chan struct{}
+
+	// pendingConns is the number of connections being created by checkMinConns. puddle does not count them in
+	// TotalConns until they are established.
+	pendingConns int32
+
+	connCreationTimesMux sync.Mutex
+	connCreationTimes    map[*Conn]time.Time
 }
 
 // PoolConfig is the configuration struct for creating a DB. It must be created by ParsePoolConfig and then it can be
 // modified. A manually initialized PoolConfig will cause NewPoolConfig to panic.
 type PoolConfig struct {
 	pgconn.Config
+	ConnConfig
 
 	// MaxConnLifetime is the duration since creation after which a connection will be automatically closed.
 	MaxConnLifetime time.Duration
@@ -48,6 +70,22 @@ type PoolConfig struct {
 	// HealthCheckPeriod is the duration between checks of the health of idle connections.
 	HealthCheckPeriod time.Duration
 
+	// HealthCheckTimeout is the maximum duration of a ping of an idle connection by the health check. A connection that
+	// fails or does not respond in time is closed. If it is 0 idle connections are not pinged.
+	HealthCheckTimeout time.Duration
+
+	// HealthCheckConcurrency is the maximum number of idle connections the health check pings at the same time.
+	HealthCheckConcurrency int32
+
+	// AcquireTimeout is the maximum duration to wait to acquire a connection when the context has no deadline. If it is
+	// exceeded ErrAcquireTimeout is returned. If it is 0 acquiring a connection waits as long as the context allows.
+	AcquireTimeout time.Duration
+
+	// SessionParams are run-time parameters such as search_path that are set on every connection when it is
+	// established. They are sent with RuntimeParams so they are the values that RESET restores. Parameters changed
+	// with Conn.SetSessionParam or Conn.SetSearchPath are reset to these values when the connection is released.
+	SessionParams map[string]string
+
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
@@ -70,28 +108,52 @@ func NewPoolConfig(config *PoolConfig) (*Pool, error) {
 	}
 
 	p := &Pool{
-		config:            config,
-		minConns:          config.MinConns,
-		maxConnLifetime:   config.MaxConnLifetime,
-		maxConnIdleTime:   config.MaxConnIdleTime,
-		healthCheckPeriod: config.HealthCheckPeriod,
-		closeChan:         make(chan struct{}),
+		config:                 config,
+		minConns:               config.MinConns,
+		maxConnLifetime:        config.MaxConnLifetime,
+		maxConnIdleTime:        config.MaxConnIdleTime,
+		healthCheckPeriod:      config.HealthCheckPeriod,
+		healthCheckTimeout:     config.HealthCheckTimeout,
+		healthCheckConcurrency: config.HealthCheckConcurrency,
+		acquireTimeout:         config.AcquireTimeout,
+		closeChan:              make(chan struct{}),
+		healthCheckChan:        make(chan struct{}, 1),
+		connCreationTimes:      make(map[*Conn]time.Time),
 	}
 
 	p.p = puddle.NewPool(
 		func(ctx context.Context) (interface{}, error) {
-			pgConn, err := pgconn.ConnectConfig(ctx, &config.Config)
+			connectConfig := &config.Config
+			if len(config.SessionParams) > 0 {
+				connectConfig = config.Config.Copy()
+				for k, v := range config.SessionParams {
+					connectConfig.RuntimeParams[k] = v
+				}
+			}
+
+			pgConn, err := pgconn.ConnectConfig(ctx, connectConfig)
 			if err != nil {
 				return nil, err
 			}
 
-			conn := &Conn{pgconn: pgConn}
+			conn := &Conn{pgconn: pgConn, database: config.Database, config: config.ConnConfig}
+
+			p.connCreationTimesMux.Lock()
+			p.connCreationTimes[conn] = time.Now()
+			p.connCreationTimesMux.Unlock()
 
 			return conn, nil
 		},
 		func(value interface{}) {
 			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 			conn := value.(*Conn)
+
+			p.connCreationTimesMux.Lock()
+			delete(p.connCreationTimes, conn)
+			p.connCreationTimesMux.Unlock()
+
+			p.triggerHealthCheck()
+
 			conn.pgconn.Close(ctx)
 			select {
 			case <-conn.pgconn.CleanupDone():
@@ -115,6 +177,9 @@ func NewPoolConfig(config *PoolConfig) (*Pool, error) {
 // pool_max_conn_lifetime: duration string
 // pool_max_conn_idle_time: duration string
 // pool_health_check_period: duration string
+// pool_health_check_timeout: duration string
+// pool_health_check_concurrency: integer greater than 0
+// pool_acquire_timeout: duration string
 //
 // See Config for definitions of these arguments.
 //
@@ -195,6 +260,38 @@ func ParsePoolConfig(connString string) (*PoolConfig, error) {
 		config.HealthCheckPeriod = defaultHealthCheckPeriod
 	}
 
+	if s, ok := config.Config.RuntimeParams["pool_health_check_timeout"]; ok {
+		delete(config.Config.RuntimeParams, "pool_health_check_timeout")
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, errors.Errorf("invalid pool_health_check_timeout: %w", err)
+		}
+		config.HealthCheckTimeout = d
+	}
+
+	if s, ok := config.Config.RuntimeParams["pool_health_check_concurrency"]; ok {
+		delete(config.Config.RuntimeParams, "pool_health_check_concurrency")
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return nil, errors.Errorf("cannot parse pool_health_check_concurrency: %w", err)
+		}
+		if n < 1 {
+			return nil, errors.Errorf("pool_health_check_concurrency too small: %d", n)
+		}
+		config.HealthCheckConcurrency = int32(n)
+	} else {
+		config.HealthCheckConcurrency = defaultHealthCheckConcurrency
+	}
+
+	if s, ok := config.Config.RuntimeParams["pool_acquire_timeout"]; ok {
+		delete(config.Config.RuntimeParams, "pool_acquire_timeout")
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, errors.Errorf("invalid pool_acquire_timeout: %w", err)
+		}
+		config.AcquireTimeout = d
+	}
+
 	return config, nil
 }
 
@@ -205,6 +302,9 @@ func (p *Pool) Close() {
 	p.p.Close()
 }
 
+// backgroundHealthCheck checks the health of idle connections and MinConns every health check period. It also checks
+// MinConns as soon as a connection is destroyed or an acquire fails so the pool does not run below MinConns until the
+// next period.
 func (p *Pool) backgroundHealthCheck() {
 	ticker := time.NewTicker(p.healthCheckPeriod)
 
@@ -216,28 +316,94 @@ func (p *Pool) backgroundHealthCheck() {
 		case <-ticker.C:
 			p.checkIdleConnsHealth()
 			p.checkMinConns()
+		case <-p.healthCheckChan:
+			p.checkMinConns()
 		}
 	}
 }
 
+// triggerHealthCheck causes backgroundHealthCheck to check MinConns without waiting for the next health check period.
+func (p *Pool) triggerHealthCheck() {
+	select {
+	case p.healthCheckChan <- struct{}{}:
+	default:
+	}
+}
+
 func (p *Pool) checkIdleConnsHealth() {
 	resources := p.p.AcquireAllIdle()
 
 	now := time.Now()
+	var pingResources []*puddle.Resource
 	for _, res := range resources {
 		if now.Sub(res.CreationTime()) > p.maxConnLifetime {
 			res.Destroy()
 		} else if res.IdleDuration() > p.maxConnIdleTime {
 			res.Destroy()
+		} else if p.healthCheckTimeout > 0 {
+			pingResources = append(pingResources, res)
 		} else {
 			res.ReleaseUnused()
 		}
 	}
+
+	p.pingIdleConns(pingResources)
+}
+
+// pingIdleConns pings the connections of resources with up to healthCheckConcurrency pings at a time. Connections that
+// fail are destroyed. The others are released without updating their last used time.
+func (p *Pool) pingIdleConns(resources []*puddle.Resource) {
+	concurrency := p.healthCheckConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	wg := &sync.WaitGroup{}
+
+	for _, res := range resources {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(res *puddle.Resource) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), p.healthCheckTimeout)
+			err := res.Value().(*Conn).Ping(ctx)
+			cancel()
+			if err != nil {
+				res.Destroy()
+			} else {
+				res.ReleaseUnused()
+			}
+		}(res)
+	}
+
+	wg.Wait()
 }
 
+// checkMinConns creates connections until the pool has MinConns connections, counting those still being created. The
+// pool is never grown beyond MaxConns.
+//
+// Established connections are counted by connCreationTimes rather than by puddle. puddle counts a destroyed connection
+// until its destructor has closed it, which would hide the connection the destructor triggered this check to replace.
 func (p *Pool) checkMinConns() {
-	for i := p.minConns - p.PoolStats().TotalConns(); i > 0; i-- {
+	stat := p.p.Stat()
+	minConns := p.minConns
+	if maxConns := stat.MaxResources(); minConns > maxConns {
+		minConns = maxConns
+	}
+
+	p.connCreationTimesMux.Lock()
+	establishedConns := int32(len(p.connCreationTimes))
+	p.connCreationTimesMux.Unlock()
+
+	totalConns := establishedConns + stat.ConstructingResources() + atomic.LoadInt32(&p.pendingConns)
+	for i := minConns - totalConns; i > 0; i-- {
+		atomic.AddInt32(&p.pendingConns, 1)
 		go func() {
+			defer atomic.AddInt32(&p.pendingConns, -1)
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 			defer cancel()
 			p.p.CreateResource(ctx)
@@ -246,11 +412,11 @@ func (p *Pool) checkMinConns() {
 }
 
 func (p *Pool) Acquire(ctx context.Context, f func(*Conn) error) error {
-	res, err := p.p.Acquire(ctx)
+	res, err := p.acquire(ctx)
 	if err != nil {
 		return err
 	}
-	defer p.releaseConn(res)
+	defer p.releaseConn(ctx, res)
 
 	conn := res.Value().(*Conn)
 	err = f(conn)
@@ -261,6 +427,63 @@ func (p *Pool) Acquire(ctx context.Context, f func(*Conn) error) error {
 	return nil
 }
 
+// acquire acquires a connection from the pool. An acquire that fails for a reason other than ctx being done triggers a
+// check of MinConns.
+func (p *Pool) acquire(ctx context.Context) (*puddle.Resource, error) {
+	ctx, endTrace := startTrace(ctx, p.config.Tracer, TraceData{Op: TraceAcquire})
+	res, err := p.acquireWithTimeout(ctx)
+	endTrace(0, err)
+	if err != nil && ctx.Err() == nil {
+		p.triggerHealthCheck()
+	}
+	return res, err
+}
+
+// acquireWithTimeout acquires a connection from the pool. If ctx has no deadline it waits at most p.acquireTimeout.
+func (p *Pool) acquireWithTimeout(ctx context.Context) (*puddle.Resource, error) {
+	if p.acquireTimeout == 0 {
+		return p.p.Acquire(ctx)
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return p.p.Acquire(ctx)
+	}
+
+	acquireCtx, cancel := context.WithTimeout(ctx, p.acquireTimeout)
+	defer cancel()
+
+	res, err := p.p.Acquire(acquireCtx)
+	if err != nil && ctx.Err() == nil && acquireCtx.Err() == context.DeadlineExceeded {
+		return nil, ErrAcquireTimeout
+	}
+	return res, err
+}
+
+// AcquireAllIdle acquires all currently idle connections and calls f with each one. It does not wait for connections
+// that are in use or being established. This is useful for maintenance such as running DISCARD on each connection.
+// Each connection is returned to the pool as soon as f returns. As with Acquire, a connection that f leaves in a broken
+// state or in a transaction is destroyed. Iteration stops at the first error returned by f or when ctx is canceled; the
+// remaining connections are returned to the pool unused.
+func (p *Pool) AcquireAllIdle(ctx context.Context, f func(*Conn) error) error {
+	resources := p.p.AcquireAllIdle()
+
+	var err error
+	for _, res := range resources {
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			res.ReleaseUnused()
+			continue
+		}
+
+		conn := res.Value().(*Conn)
+		err = f(conn)
+		p.releaseConn(ctx, res)
+	}
+
+	return err
+}
+
 func (p *Pool) Query(ctx context.Context, sql string, args []interface{}, results []interface{}, rowFunc func() error) (int64, error) {
 	var rowCount int64
 	err := p.Acquire(ctx, func(conn *Conn) error {
@@ -287,7 +510,66 @@ func (p *Pool) Begin(ctx context.Context, f func(StdDB) error) error {
 	})
 }
 
-func (p *Pool) releaseConn(res *puddle.Resource) {
+// QueryChunks acquires a connection and calls Conn.QueryChunks with it.
+func (p *Pool) QueryChunks(ctx context.Context, sql string, args []interface{}, results []interface{}, chunkSize int, rowFunc func() error, chunkFunc func() error) (int64, error) {
+	var rowCount int64
+	err := p.Acquire(ctx, func(conn *Conn) error {
+		var err error
+		rowCount, err = conn.QueryChunks(ctx, sql, args, results, chunkSize, rowFunc, chunkFunc)
+		return err
+	})
+	return rowCount, err
+}
+
+// BeginTx acquires a connection and starts a transaction on it. The connection is returned to the pool when the Tx is
+// committed or rolled back.
+func (p *Pool) BeginTx(ctx context.Context) (*Tx, error) {
+	res, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := res.Value().(*Conn).BeginTx(ctx)
+	if err != nil {
+		p.releaseConn(ctx, res)
+		return nil, err
+	}
+	connEnd := tx.end
+	tx.end = func() {
+		connEnd()
+		p.releaseConn(ctx, res)
+	}
+
+	return tx, nil
+}
+
+// CopyFrom acquires a connection and calls Conn.CopyFrom with it.
+func (p *Pool) CopyFrom(ctx context.Context, table Ident, columns []string, src CopyFromSource) (int64, error) {
+	var rowCount int64
+	err := p.Acquire(ctx, func(conn *Conn) error {
+		var err error
+		rowCount, err = conn.CopyFrom(ctx, table, columns, src)
+		return err
+	})
+	return rowCount, err
+}
+
+// CopyTo acquires a connection and calls Conn.CopyTo with it.
+func (p *Pool) CopyTo(ctx context.Context, w io.Writer, sql string) (int64, error) {
+	var rowCount int64
+	err := p.Acquire(ctx, func(conn *Conn) error {
+		var err error
+		rowCount, err = conn.CopyTo(ctx, w, sql)
+		return err
+	})
+	return rowCount, err
+}
+
+// releaseConn returns res to the pool or destroys it if it cannot be reused. ctx is only used for tracing.
+func (p *Pool) releaseConn(ctx context.Context, res *puddle.Resource) {
+	_, endTrace := startTrace(ctx, p.config.Tracer, TraceData{Op: TraceRelease})
+	defer endTrace(0, nil)
+
 	conn := res.Value().(*Conn)
 	now := time.Now()
 	if conn.pgconn.IsClosed() || conn.pgconn.IsBusy() || conn.pgconn.TxStatus() != 'I' || (now.Sub(res.CreationTime()) > p.maxConnLifetime) {
@@ -295,15 +577,53 @@ func (p *Pool) releaseConn(res *puddle.Resource) {
 		return
 	}
 
+	if conn.sessionParamsChanged {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		err := conn.resetSessionParams(ctx)
+		cancel()
+		if err != nil {
+			res.Destroy()
+			return
+		}
+	}
+
 	res.Release()
 }
 
 func (p *Pool) PoolStats() *PoolStats {
-	return &PoolStats{s: p.p.Stat()}
+	stats := &PoolStats{s: p.p.Stat()}
+
+	now := time.Now()
+	var totalConnAge time.Duration
+	p.connCreationTimesMux.Lock()
+	for conn, t := range p.connCreationTimes {
+		stats.connStats = append(stats.connStats, conn.Stats())
+
+		age := now.Sub(t)
+		if stats.minConnAge == 0 || age < stats.minConnAge {
+			stats.minConnAge = age
+		}
+		if age > stats.maxConnAge {
+			stats.maxConnAge = age
+		}
+		totalConnAge += age
+	}
+	if len(p.connCreationTimes) > 0 {
+		stats.avgConnAge = totalConnAge / time.Duration(len(p.connCreationTimes))
+	}
+	p.connCreationTimesMux.Unlock()
+
+	return stats
 }
 
 type PoolStats struct {
 	s *puddle.Stat
+
+	minConnAge time.Duration
+	avgConnAge time.Duration
+	maxConnAge time.Duration
+
+	connStats []ConnStats
 }
 
 // AcquireCount returns the cumulative count of successful acquires from the pool.
@@ -357,3 +677,24 @@ func (s *PoolStats) MaxConns() int32 {
 func (s *PoolStats) TotalConns() int32 {
 	return s.s.TotalResources()
 }
+
+// MinConnAge returns the age of the newest connection in the pool. It is 0 if there are no connections.
+func (s *PoolStats) MinConnAge() time.Duration {
+	return s.minConnAge
+}
+
+// AvgConnAge returns the average age of the connections in the pool. It is 0 if there are no connections.
+func (s *PoolStats) AvgConnAge() time.Duration {
+	return s.avgConnAge
+}
+
+// MaxConnAge returns the age of the oldest connection in the pool. It is 0 if there are no connections.
+func (s *PoolStats) MaxConnAge() time.Duration {
+	return s.maxConnAge
+}
+
+// ConnStats returns the statistics of each connection in the pool in no particular order. Comparing them shows how
+// evenly work is distributed across connections.
+func (s *PoolStats) ConnStats() []ConnStats {
+	return s.connStats
+}
@@ -2,9 +2,11 @@ package goldilocks_test
 
 import (
 	"context"
+	"errors"
 	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/jackc/goldilocks"
 	"github.com/stretchr/testify/require"
@@ -24,6 +26,47 @@ func TestPoolAcquire(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestPoolAcquireAllIdle(t *testing.T) {
+	t.Parallel()
+
+	db, err := goldilocks.NewPool(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := db.Exec(context.Background(), "select pg_sleep(0.1)")
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	idleConns := db.PoolStats().IdleConns()
+	require.True(t, idleConns > 0)
+
+	var count int32
+	err = db.AcquireAllIdle(context.Background(), func(db *goldilocks.Conn) error {
+		count++
+		_, err := db.Exec(context.Background(), "discard all")
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, idleConns, count)
+	require.Equal(t, idleConns, db.PoolStats().IdleConns())
+
+	count = 0
+	err = db.AcquireAllIdle(context.Background(), func(db *goldilocks.Conn) error {
+		count++
+		return errors.New("stop")
+	})
+	require.EqualError(t, err, "stop")
+	require.EqualValues(t, 1, count)
+	require.Equal(t, idleConns, db.PoolStats().IdleConns())
+}
+
 func TestPoolStdDB(t *testing.T) {
 	db, err := goldilocks.NewPool(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
 	require.NoError(t, err)
@@ -87,3 +130,191 @@ func TestPoolStress(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestPoolConnErrorIncludesDatabase(t *testing.T) {
+	t.Parallel()
+
+	config, err := goldilocks.ParsePoolConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	db, err := goldilocks.NewPoolConfig(config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(context.Background(), "select 1 / 0")
+	var connErr *goldilocks.ConnError
+	require.True(t, errors.As(err, &connErr))
+	require.Equal(t, config.Database, connErr.Database)
+	require.NotZero(t, connErr.PID)
+}
+
+func TestParsePoolConfigHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	config, err := goldilocks.ParsePoolConfig("pool_health_check_timeout=2s pool_health_check_concurrency=8")
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Second, config.HealthCheckTimeout)
+	require.EqualValues(t, 8, config.HealthCheckConcurrency)
+	require.NotContains(t, config.RuntimeParams, "pool_health_check_timeout")
+	require.NotContains(t, config.RuntimeParams, "pool_health_check_concurrency")
+
+	config, err = goldilocks.ParsePoolConfig("")
+	require.NoError(t, err)
+	require.Zero(t, config.HealthCheckTimeout)
+	require.EqualValues(t, 4, config.HealthCheckConcurrency)
+
+	_, err = goldilocks.ParsePoolConfig("pool_health_check_concurrency=0")
+	require.EqualError(t, err, "pool_health_check_concurrency too small: 0")
+}
+
+func TestPoolStatsConnAge(t *testing.T) {
+	t.Parallel()
+
+	db, err := goldilocks.NewPool(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	stats := db.PoolStats()
+	require.Zero(t, stats.MinConnAge())
+	require.Zero(t, stats.AvgConnAge())
+	require.Zero(t, stats.MaxConnAge())
+
+	_, err = db.Exec(context.Background(), "select 1")
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	stats = db.PoolStats()
+	require.True(t, stats.MinConnAge() >= 10*time.Millisecond)
+	require.True(t, stats.MinConnAge() <= stats.AvgConnAge())
+	require.True(t, stats.AvgConnAge() <= stats.MaxConnAge())
+}
+
+func TestPoolAcquireTimeout(t *testing.T) {
+	t.Parallel()
+
+	config, err := goldilocks.ParsePoolConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+	config.AcquireTimeout = 50 * time.Millisecond
+
+	db, err := goldilocks.NewPoolConfig(config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Acquire(context.Background(), func(*goldilocks.Conn) error {
+		err := db.Acquire(context.Background(), func(*goldilocks.Conn) error { return nil })
+		require.True(t, errors.Is(err, goldilocks.ErrAcquireTimeout))
+
+		// A context with a deadline overrides AcquireTimeout.
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		err = db.Acquire(ctx, func(*goldilocks.Conn) error { return nil })
+		require.False(t, errors.Is(err, goldilocks.ErrAcquireTimeout))
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	config, err = goldilocks.ParsePoolConfig("pool_acquire_timeout=3s")
+	require.NoError(t, err)
+	require.Equal(t, 3*time.Second, config.AcquireTimeout)
+	require.NotContains(t, config.RuntimeParams, "pool_acquire_timeout")
+}
+
+func TestPoolRestoresMinConnsAfterDestroy(t *testing.T) {
+	t.Parallel()
+
+	config, err := goldilocks.ParsePoolConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	config.MinConns = 2
+	config.HealthCheckPeriod = time.Hour
+
+	db, err := goldilocks.NewPoolConfig(config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// The connection is left in a transaction so it is destroyed when it is released. Destroying connections while
+	// earlier replacements are still being created must not create more than MinConns.
+	for i := 0; i < 3; i++ {
+		err = db.Acquire(context.Background(), func(conn *goldilocks.Conn) error {
+			_, err := conn.Exec(context.Background(), "begin")
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool { return db.PoolStats().TotalConns() == 2 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.EqualValues(t, 2, db.PoolStats().TotalConns())
+}
+
+func TestPoolReplacesDestroyedConnAtMinConns(t *testing.T) {
+	t.Parallel()
+
+	config, err := goldilocks.ParsePoolConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	config.MinConns = 2
+	config.HealthCheckPeriod = time.Hour
+
+	db, err := goldilocks.NewPoolConfig(config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	backendPIDs := func() []int32 {
+		var pids []int32
+		err := db.AcquireAllIdle(context.Background(), func(conn *goldilocks.Conn) error {
+			var pid int32
+			_, err := conn.Query(context.Background(), "select pg_backend_pid()", nil, []interface{}{&pid}, func() error { return nil })
+			pids = append(pids, pid)
+			return err
+		})
+		require.NoError(t, err)
+		return pids
+	}
+
+	// Destroying a connection fills the pool to MinConns.
+	err = db.Acquire(context.Background(), func(conn *goldilocks.Conn) error {
+		_, err := conn.Exec(context.Background(), "begin")
+		return err
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(backendPIDs()) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// Destroying a connection of a pool with exactly MinConns connections replaces it without waiting for the next
+	// health check.
+	var destroyedPID int32
+	err = db.Acquire(context.Background(), func(conn *goldilocks.Conn) error {
+		_, err := conn.Query(context.Background(), "select pg_backend_pid()", nil, []interface{}{&destroyedPID}, func() error { return nil })
+		if err != nil {
+			return err
+		}
+		_, err = conn.Exec(context.Background(), "begin")
+		return err
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		pids := backendPIDs()
+		return len(pids) == 2 && pids[0] != destroyedPID && pids[1] != destroyedPID
+	}, 5*time.Second, 10*time.Millisecond)
+	require.EqualValues(t, 2, db.PoolStats().TotalConns())
+}
+
+func TestPoolStatsConnStats(t *testing.T) {
+	t.Parallel()
+
+	db, err := goldilocks.NewPool(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.Empty(t, db.PoolStats().ConnStats())
+
+	for i := 0; i < 3; i++ {
+		_, err = db.Exec(context.Background(), "select 1")
+		require.NoError(t, err)
+	}
+
+	connStats := db.PoolStats().ConnStats()
+	require.Len(t, connStats, 1)
+	require.EqualValues(t, 3, connStats[0].QueryCount)
+}
@@ -0,0 +1,45 @@
+package promgoldilocks_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/goldilocks/promgoldilocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	config, err := goldilocks.ParsePoolConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	config.MaxConns = 7
+
+	pool, err := goldilocks.NewPoolConfig(config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+	err = registry.Register(promgoldilocks.NewCollector(pool, prometheus.Labels{"pool": "test"}))
+	require.NoError(t, err)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, mf := range families {
+		m := mf.GetMetric()[0]
+		require.Equal(t, "pool", m.GetLabel()[0].GetName())
+		require.Equal(t, "test", m.GetLabel()[0].GetValue())
+		if g := m.GetGauge(); g != nil {
+			values[mf.GetName()] = g.GetValue()
+		} else {
+			values[mf.GetName()] = m.GetCounter().GetValue()
+		}
+	}
+
+	require.Len(t, values, 9)
+	require.EqualValues(t, 7, values["goldilocks_pool_max_conns"])
+	require.EqualValues(t, 0, values["goldilocks_pool_acquired_conns"])
+	require.EqualValues(t, 0, values["goldilocks_pool_acquires_total"])
+}
@@ -0,0 +1,238 @@
+package goldilocks
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgio"
+)
+
+// PostgreSQL oids for range types
+const (
+	int4rangeOID = 3904
+	numrangeOID  = 3906
+	tstzrangeOID = 3910
+	daterangeOID = 3912
+	int8rangeOID = 3926
+)
+
+// Range flags in the binary format
+const (
+	rangeEmpty          = 0x01
+	rangeLowerInclusive = 0x02
+	rangeUpperInclusive = 0x04
+	rangeLowerInfinite  = 0x08
+	rangeUpperInfinite  = 0x10
+)
+
+// Range represents a PostgreSQL int4range, int8range, numrange, tstzrange, or daterange. Lower and Upper are int32 for
+// an int4range, int64 for an int8range, BigNumeric for a numrange, time.Time for a tstzrange, and Date for a daterange.
+// A numrange bound may also be encoded from a DecimalDecomposer. An infinite bound is distinct from a bound of
+// TimeInfinity or DateInfinity, just as it is in PostgreSQL.
+//
+// Lower is ignored if LowerInfinite is true and Upper is ignored if UpperInfinite is true. All bounds are ignored if
+// Empty is true.
+//
+// The PostgreSQL range type of a param is determined by the type of its bounds. A Range with no bounds has no type
+// and the param must be cast in the query (e.g. $1::int4range). The binary format of a range does not include the type
+// of its bounds so results must be read with Int4RangeResult, Int8RangeResult, NumRangeResult, TsTzRangeResult, or DateRangeResult.
+type Range struct {
+	Lower          interface{}
+	Upper          interface{}
+	LowerInclusive bool
+	UpperInclusive bool
+	LowerInfinite  bool
+	UpperInfinite  bool
+	Empty          bool
+}
+
+func (r Range) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	var flags byte
+	var bounds []interface{}
+	if r.Empty {
+		flags = rangeEmpty
+	} else {
+		if r.LowerInclusive {
+			flags |= rangeLowerInclusive
+		}
+		if r.UpperInclusive {
+			flags |= rangeUpperInclusive
+		}
+		if r.LowerInfinite {
+			flags |= rangeLowerInfinite
+		} else {
+			bounds = append(bounds, r.Lower)
+		}
+		if r.UpperInfinite {
+			flags |= rangeUpperInfinite
+		} else {
+			bounds = append(bounds, r.Upper)
+		}
+	}
+
+	buf = append(buf, flags)
+
+	var elemOID uint32
+	for _, bound := range bounds {
+		sp := len(buf)
+		buf = pgio.AppendInt32(buf, -1)
+
+		value, oid, format, err := encodeParam(buf, bound)
+		if err == errUnsupportedType {
+			return nil, 0, 0, fmt.Errorf("range bound is unsupported type %T", bound)
+		}
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("cannot encode range bound: %w", err)
+		}
+		if value == nil {
+			return nil, 0, 0, errors.New("range bound cannot be NULL, use an infinite bound instead")
+		}
+		if format != binaryFormat {
+			return nil, 0, 0, fmt.Errorf("range bound %T is not encoded in the binary format", bound)
+		}
+		if elemOID != 0 && oid != elemOID {
+			return nil, 0, 0, fmt.Errorf("range bounds must be the same type, got %T and %T", r.Lower, r.Upper)
+		}
+		elemOID = oid
+
+		buf = value
+		pgio.SetInt32(buf[sp:], int32(len(buf[sp:])-4))
+	}
+
+	var rangeOID uint32
+	switch elemOID {
+	case 0:
+	case int4OID:
+		rangeOID = int4rangeOID
+	case int8OID:
+		rangeOID = int8rangeOID
+	case numericOID:
+		rangeOID = numrangeOID
+	case timestamptzOID:
+		rangeOID = tstzrangeOID
+	case dateOID:
+		rangeOID = daterangeOID
+	default:
+		return nil, 0, 0, fmt.Errorf("range bound %T is not an int32, int64, numeric, time.Time, or Date", bounds[0])
+	}
+
+	return buf, rangeOID, binaryFormat, nil
+}
+
+// Int4RangeResult returns a result decoder that decodes an int4range into dst. The bounds are decoded as int32.
+func Int4RangeResult(dst *Range) ResultDecoder {
+	return &rangeResult{dst: dst, typeName: "int4range", readBound: func(buf []byte) (interface{}, error) {
+		var n int32
+		err := readNotNullInt32(buf, &n)
+		return n, err
+	}}
+}
+
+// Int8RangeResult returns a result decoder that decodes an int8range into dst. The bounds are decoded as int64.
+func Int8RangeResult(dst *Range) ResultDecoder {
+	return &rangeResult{dst: dst, typeName: "int8range", readBound: func(buf []byte) (interface{}, error) {
+		var n int64
+		err := readNotNullInt64(buf, &n)
+		return n, err
+	}}
+}
+
+// NumRangeResult returns a result decoder that decodes a numrange into dst. The bounds are decoded as BigNumeric.
+func NumRangeResult(dst *Range) ResultDecoder {
+	return &rangeResult{dst: dst, typeName: "numrange", readBound: func(buf []byte) (interface{}, error) {
+		var n BigNumeric
+		err := readNotNullBigNumeric(buf, &n)
+		return n, err
+	}}
+}
+
+// TsTzRangeResult returns a result decoder that decodes a tstzrange into dst. The bounds are decoded as time.Time.
+func TsTzRangeResult(dst *Range) ResultDecoder {
+	return &rangeResult{dst: dst, typeName: "tstzrange", readBound: func(buf []byte) (interface{}, error) {
+		var t time.Time
+		err := readNotNullTime(buf, &t)
+		return t, err
+	}}
+}
+
+// DateRangeResult returns a result decoder that decodes a daterange into dst. The bounds are decoded as Date.
+func DateRangeResult(dst *Range) ResultDecoder {
+	return &rangeResult{dst: dst, typeName: "daterange", readBound: func(buf []byte) (interface{}, error) {
+		var t time.Time
+		err := readNotNullDate(buf, &t)
+		return Date(t), err
+	}}
+}
+
+type rangeResult struct {
+	dst       *Range
+	typeName  string
+	readBound func([]byte) (interface{}, error)
+}
+
+func (*rangeResult) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (rr *rangeResult) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return fmt.Errorf("NULL cannot be converted to %s", rr.typeName)
+	}
+	if len(buf) < 1 {
+		return fmt.Errorf("%s requires data length of at least 1, got 0", rr.typeName)
+	}
+
+	flags := buf[0]
+	buf = buf[1:]
+
+	if flags&rangeEmpty != 0 {
+		*rr.dst = Range{Empty: true}
+		return nil
+	}
+
+	r := Range{
+		LowerInclusive: flags&rangeLowerInclusive != 0,
+		UpperInclusive: flags&rangeUpperInclusive != 0,
+		LowerInfinite:  flags&rangeLowerInfinite != 0,
+		UpperInfinite:  flags&rangeUpperInfinite != 0,
+	}
+
+	var err error
+	if !r.LowerInfinite {
+		r.Lower, buf, err = rr.readRangeBound(buf, "lower")
+		if err != nil {
+			return err
+		}
+	}
+	if !r.UpperInfinite {
+		r.Upper, buf, err = rr.readRangeBound(buf, "upper")
+		if err != nil {
+			return err
+		}
+	}
+	if len(buf) != 0 {
+		return fmt.Errorf("%s has %d bytes of unexpected trailing data", rr.typeName, len(buf))
+	}
+
+	*rr.dst = r
+	return nil
+}
+
+func (rr *rangeResult) readRangeBound(buf []byte, name string) (interface{}, []byte, error) {
+	if len(buf) < 4 {
+		return nil, nil, fmt.Errorf("%s %s bound is missing", rr.typeName, name)
+	}
+	boundLen := int(int32(binary.BigEndian.Uint32(buf)))
+	buf = buf[4:]
+	if boundLen < 0 || len(buf) < boundLen {
+		return nil, nil, fmt.Errorf("%s %s bound requires data length of %d, got %d", rr.typeName, name, boundLen, len(buf))
+	}
+
+	bound, err := rr.readBound(buf[:boundLen])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s %s bound: %w", rr.typeName, name, err)
+	}
+	return bound, buf[boundLen:], nil
+}
@@ -0,0 +1,206 @@
+package goldilocks_test
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRange(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	tests := []struct {
+		sql       string
+		param     goldilocks.Range
+		newResult func(*goldilocks.Range) goldilocks.ResultDecoder
+		text      string
+		expected  goldilocks.Range
+	}{
+		{
+			sql:       "select $1::text, $1",
+			param:     goldilocks.Range{Lower: int32(1), Upper: int32(5), LowerInclusive: true, UpperInclusive: true},
+			newResult: goldilocks.Int4RangeResult,
+			text:      "[1,6)",
+			expected:  goldilocks.Range{Lower: int32(1), Upper: int32(6), LowerInclusive: true},
+		},
+		{
+			sql:       "select $1::text, $1",
+			param:     goldilocks.Range{Lower: int64(-10), UpperInfinite: true},
+			newResult: goldilocks.Int8RangeResult,
+			text:      "[-9,)",
+			expected:  goldilocks.Range{Lower: int64(-9), LowerInclusive: true, UpperInfinite: true},
+		},
+		{
+			sql: "select $1::text, $1",
+			param: goldilocks.Range{
+				Lower:          goldilocks.BigNumeric{Int: big.NewInt(150), Exp: -2},
+				Upper:          goldilocks.BigNumeric{Int: big.NewInt(3)},
+				LowerInclusive: true,
+			},
+			newResult: goldilocks.NumRangeResult,
+			text:      "[1.50,3)",
+			expected: goldilocks.Range{
+				Lower:          goldilocks.BigNumeric{Int: big.NewInt(150), Exp: -2},
+				Upper:          goldilocks.BigNumeric{Int: big.NewInt(3), Exp: 0},
+				LowerInclusive: true,
+			},
+		},
+		{
+			sql:       "select $1::int4range::text, $1::int4range",
+			param:     goldilocks.Range{LowerInfinite: true, UpperInfinite: true},
+			newResult: goldilocks.Int4RangeResult,
+			text:      "(,)",
+			expected:  goldilocks.Range{LowerInfinite: true, UpperInfinite: true},
+		},
+		{
+			sql:       "select $1::text, $1",
+			param:     goldilocks.Range{Lower: int32(3), Upper: int32(3)},
+			newResult: goldilocks.Int4RangeResult,
+			text:      "empty",
+			expected:  goldilocks.Range{Empty: true},
+		},
+	}
+
+	for i, tt := range tests {
+		var text string
+		var result goldilocks.Range
+		_, err := db.Query(
+			context.Background(),
+			tt.sql,
+			[]interface{}{tt.param},
+			[]interface{}{&text, tt.newResult(&result)},
+			func() error { return nil },
+		)
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, tt.text, text, "%d", i)
+		require.Equalf(t, tt.expected, result, "%d", i)
+	}
+
+	var result goldilocks.Range
+	_, err = db.Query(
+		context.Background(),
+		"select null::int4range",
+		nil,
+		[]interface{}{goldilocks.Int4RangeResult(&result)},
+		func() error { return nil },
+	)
+	require.EqualError(t, err, `cannot decode results[0] (column "int4range"): NULL cannot be converted to int4range`)
+
+	_, err = db.Exec(context.Background(), "select $1", goldilocks.Range{Lower: int32(1), Upper: int64(2)})
+	require.EqualError(t, err, "cannot encode args[0] (goldilocks.Range): range bounds must be the same type, got int32 and int64")
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestDateRange(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	tests := []struct {
+		param    goldilocks.Range
+		text     string
+		expected goldilocks.Range
+	}{
+		{
+			param: goldilocks.Range{
+				Lower:          goldilocks.Date(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+				Upper:          goldilocks.Date(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+				LowerInclusive: true,
+				UpperInclusive: true,
+			},
+			text: "[2024-01-01,2024-01-11)",
+			expected: goldilocks.Range{
+				Lower:          goldilocks.Date(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+				Upper:          goldilocks.Date(time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)),
+				LowerInclusive: true,
+			},
+		},
+		{
+			param:    goldilocks.Range{Lower: goldilocks.DateNegativeInfinity, Upper: goldilocks.DateInfinity, LowerInclusive: true},
+			text:     "[-infinity,infinity)",
+			expected: goldilocks.Range{Lower: goldilocks.DateNegativeInfinity, Upper: goldilocks.DateInfinity, LowerInclusive: true},
+		},
+		{
+			param:    goldilocks.Range{LowerInfinite: true, Upper: goldilocks.Date(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+			text:     "(,2024-01-01)",
+			expected: goldilocks.Range{LowerInfinite: true, Upper: goldilocks.Date(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+		},
+	}
+
+	for i, tt := range tests {
+		var text string
+		var result goldilocks.Range
+		_, err := db.Query(
+			context.Background(),
+			"select $1::text, $1",
+			[]interface{}{tt.param},
+			[]interface{}{&text, goldilocks.DateRangeResult(&result)},
+			func() error { return nil },
+		)
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, tt.text, text, "%d", i)
+		require.Equalf(t, tt.expected, result, "%d", i)
+	}
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestTsTzRange(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	lower := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	param := goldilocks.Range{Lower: lower, Upper: goldilocks.TimeInfinity, LowerInclusive: true}
+
+	var contains bool
+	var upperIsInfinity bool
+	var result goldilocks.Range
+	_, err = db.Query(
+		context.Background(),
+		"select $1 @> '2024-06-01 00:00:00Z'::timestamptz, upper($1) = 'infinity', $1",
+		[]interface{}{param},
+		[]interface{}{&contains, &upperIsInfinity, goldilocks.TsTzRangeResult(&result)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.True(t, contains)
+	require.True(t, upperIsInfinity)
+	require.True(t, result.LowerInclusive)
+	require.False(t, result.UpperInclusive)
+	require.False(t, result.LowerInfinite)
+	require.False(t, result.UpperInfinite)
+	require.True(t, lower.Equal(result.Lower.(time.Time)))
+	require.Equal(t, goldilocks.TimeInfinity, result.Upper)
+
+	_, err = db.Query(
+		context.Background(),
+		"select tstzrange(null, '2024-01-01 00:00:00Z')",
+		nil,
+		[]interface{}{goldilocks.TsTzRangeResult(&result)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.True(t, result.LowerInfinite)
+	require.True(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Equal(result.Upper.(time.Time)))
+
+	ensurePgConnValid(t, pgConn)
+}
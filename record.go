@@ -0,0 +1,73 @@
+package goldilocks
+
+import (
+	"fmt"
+)
+
+// RecordField is a field of a composite or record value in the binary format. Value is nil for NULL.
+type RecordField struct {
+	OID   uint32
+	Value []byte
+}
+
+// Record is an anonymous record such as the result of select (1, 'a', true). A record has no static field layout so
+// Record exposes the raw binary format value and type oid of each field. Record is a ResultDecoder. NULL cannot be
+// decoded into a Record.
+type Record struct {
+	Fields []RecordField
+}
+
+func (*Record) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (dst *Record) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return fmt.Errorf("NULL cannot be converted to Record")
+	}
+
+	// Copy buf once as it is only valid until the next row is read.
+	buf = append([]byte(nil), buf...)
+	fields, err := readCompositeFields(buf, "Record")
+	if err != nil {
+		return err
+	}
+
+	dst.Fields = fields
+	return nil
+}
+
+// RecordResult returns a result decoder that decodes the fields of a record into dst. dst must have one element per
+// field. Each field is decoded as if its element of dst were a query result.
+func RecordResult(dst ...interface{}) ResultDecoder {
+	return recordResult(dst)
+}
+
+type recordResult []interface{}
+
+func (recordResult) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (dst recordResult) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return fmt.Errorf("NULL cannot be converted to record")
+	}
+
+	fields, err := readCompositeFields(buf, "record")
+	if err != nil {
+		return err
+	}
+	if len(fields) != len(dst) {
+		return fmt.Errorf("record has %d fields but %d destinations", len(fields), len(dst))
+	}
+
+	for i := range fields {
+		err := decodeCompositeField(fmt.Sprintf("record field %d", i), fields[i], dst[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
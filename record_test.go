@@ -0,0 +1,93 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var record goldilocks.Record
+	_, err = db.Query(
+		context.Background(),
+		"select row(1::int4, 'a'::text, null::bool)",
+		nil,
+		[]interface{}{&record},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t,
+		[]goldilocks.RecordField{
+			{OID: 23, Value: []byte{0, 0, 0, 1}},
+			{OID: 25, Value: []byte("a")},
+			{OID: 16, Value: nil},
+		},
+		record.Fields,
+	)
+
+	_, err = db.Query(
+		context.Background(),
+		"select null::record",
+		nil,
+		[]interface{}{&record},
+		func() error { return nil },
+	)
+	require.EqualError(t, err, `cannot decode results[0] (column "record"): NULL cannot be converted to Record`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestRecordResult(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var n int32
+	var s string
+	var b goldilocks.NullBool
+	_, err = db.Query(
+		context.Background(),
+		"select row(1::int4, 'a'::text, true)",
+		nil,
+		[]interface{}{goldilocks.RecordResult(&n, &s, &b)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	require.Equal(t, "a", s)
+	require.Equal(t, goldilocks.NullBool{Value: true, Valid: true}, b)
+
+	_, err = db.Query(
+		context.Background(),
+		"select row(1::int4, 'a'::text)",
+		nil,
+		[]interface{}{goldilocks.RecordResult(&n)},
+		func() error { return nil },
+	)
+	require.EqualError(t, err, `cannot decode results[0] (column "row"): record has 2 fields but 1 destinations`)
+
+	_, err = db.Query(
+		context.Background(),
+		"select row(null::int4)",
+		nil,
+		[]interface{}{goldilocks.RecordResult(&n)},
+		func() error { return nil },
+	)
+	require.EqualError(t, err, `cannot decode results[0] (column "row"): record field 0: NULL cannot be converted to int32`)
+
+	ensurePgConnValid(t, pgConn)
+}
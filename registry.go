@@ -0,0 +1,207 @@
+package goldilocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ErrQueryNotRegistered is returned by QueryRegistry when no query is registered with the name.
+var ErrQueryNotRegistered = errors.New("not registered")
+
+type queryNameCtxKey struct{}
+
+// WithQueryName returns a copy of ctx that labels queries and execs with name. The name is included in the TraceData
+// passed to ConnConfig.Tracer and the data logged by ConnConfig.Logger. QueryRegistry labels its queries with their
+// registered names.
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameCtxKey{}, name)
+}
+
+// queryName returns the name ctx labels queries with or "" if there is none.
+func queryName(ctx context.Context) string {
+	name, _ := ctx.Value(queryNameCtxKey{}).(string)
+	return name
+}
+
+// QueryRegistry is a set of SQL statements registered by name. Executing statements by name keeps all SQL in one place
+// for review and labels errors, traces, and logs with the statement name. A query can be registered with a result
+// mapping so callers do not need to know the columns it returns. A QueryRegistry is safe for concurrent use.
+type QueryRegistry struct {
+	mux     sync.RWMutex
+	queries map[string]*registeredQuery
+}
+
+// registeredQuery is a query registered in a QueryRegistry. results is nil if the query has no result mapping.
+type registeredQuery struct {
+	sql     string
+	results func(dst interface{}) ([]interface{}, error)
+}
+
+// NewQueryRegistry creates an empty QueryRegistry.
+func NewQueryRegistry() *QueryRegistry {
+	return &QueryRegistry{queries: make(map[string]*registeredQuery)}
+}
+
+// Register registers sql as name. It panics if name is empty or is already registered.
+func (r *QueryRegistry) Register(name, sql string) {
+	r.register(name, &registeredQuery{sql: sql})
+}
+
+// RegisterResults registers sql as name with a result mapping for QueryInto. results returns the results each row is
+// decoded into for the dst passed to QueryInto. It panics if name is empty or is already registered.
+func (r *QueryRegistry) RegisterResults(name, sql string, results func(dst interface{}) ([]interface{}, error)) {
+	if results == nil {
+		panic("results must not be nil")
+	}
+	r.register(name, &registeredQuery{sql: sql, results: results})
+}
+
+// RegisterStruct registers sql as name with a result mapping to the struct type of example for QueryInto. Each column
+// is decoded into the field with a db tag in the same position. Fields tagged db:"-" and fields without a db tag are
+// skipped as with Insert. The dst passed to QueryInto must be a pointer to a struct of that type. It panics if example
+// is not a struct or a pointer to a struct with at least one db tag, or if name is empty or is already registered.
+func (r *QueryRegistry) RegisterStruct(name, sql string, example interface{}) {
+	t := reflect.TypeOf(example)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("RegisterStruct requires a struct or a pointer to a struct, got %T", example))
+	}
+
+	var indexes []int
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		if tag, ok := sf.Tag.Lookup("db"); ok && tag != "-" {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 {
+		panic(fmt.Sprintf("%s has no fields with a db tag", t))
+	}
+
+	r.register(name, &registeredQuery{sql: sql, results: func(dst interface{}) ([]interface{}, error) {
+		v := reflect.ValueOf(dst)
+		if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Type() != t {
+			return nil, fmt.Errorf("dst must be a non-nil *%s, got %T", t, dst)
+		}
+
+		results := make([]interface{}, len(indexes))
+		for i, fi := range indexes {
+			results[i] = v.Elem().Field(fi).Addr().Interface()
+		}
+		return results, nil
+	}})
+}
+
+func (r *QueryRegistry) register(name string, q *registeredQuery) {
+	if name == "" {
+		panic("name must not be empty")
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if _, ok := r.queries[name]; ok {
+		panic(fmt.Sprintf("query %q is already registered", name))
+	}
+	r.queries[name] = q
+}
+
+// SQL returns the SQL registered as name and whether it was found.
+func (r *QueryRegistry) SQL(name string) (string, bool) {
+	q, ok := r.query(name)
+	if !ok {
+		return "", false
+	}
+	return q.sql, true
+}
+
+func (r *QueryRegistry) query(name string) (*registeredQuery, bool) {
+	r.mux.RLock()
+	q, ok := r.queries[name]
+	r.mux.RUnlock()
+	return q, ok
+}
+
+// Names returns the names of all registered queries in sorted order.
+func (r *QueryRegistry) Names() []string {
+	r.mux.RLock()
+	names := make([]string, 0, len(r.queries))
+	for name := range r.queries {
+		names = append(names, name)
+	}
+	r.mux.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Query executes the query registered as name on db. See StdDB for the meaning of the other arguments. Errors are
+// prefixed with name.
+func (r *QueryRegistry) Query(ctx context.Context, db StdDB, name string, args []interface{}, results []interface{}, rowFunc func() error) (int64, error) {
+	q, ok := r.query(name)
+	if !ok {
+		return 0, queryNameError(name, ErrQueryNotRegistered)
+	}
+
+	rowCount, err := db.Query(WithQueryName(ctx, name), q.sql, args, results, rowFunc)
+	if err != nil {
+		return rowCount, queryNameError(name, err)
+	}
+
+	return rowCount, nil
+}
+
+// QueryInto executes the query registered as name on db and decodes each row into dst with the result mapping the
+// query was registered with. rowFunc is called after each row is decoded. See StdDB for the meaning of the other
+// arguments. Errors are prefixed with name.
+func (r *QueryRegistry) QueryInto(ctx context.Context, db StdDB, name string, args []interface{}, dst interface{}, rowFunc func() error) (int64, error) {
+	q, ok := r.query(name)
+	if !ok {
+		return 0, queryNameError(name, ErrQueryNotRegistered)
+	}
+	if q.results == nil {
+		return 0, queryNameError(name, errors.New("registered without a result mapping"))
+	}
+
+	results, err := q.results(dst)
+	if err != nil {
+		return 0, queryNameError(name, err)
+	}
+
+	rowCount, err := db.Query(WithQueryName(ctx, name), q.sql, args, results, rowFunc)
+	if err != nil {
+		return rowCount, queryNameError(name, err)
+	}
+
+	return rowCount, nil
+}
+
+// Exec executes the statement registered as name on db. See StdDB for the meaning of the other arguments. Errors are
+// prefixed with name.
+func (r *QueryRegistry) Exec(ctx context.Context, db StdDB, name string, args ...interface{}) (int64, error) {
+	q, ok := r.query(name)
+	if !ok {
+		return 0, queryNameError(name, ErrQueryNotRegistered)
+	}
+
+	rowsAffected, err := db.Exec(WithQueryName(ctx, name), q.sql, args...)
+	if err != nil {
+		return rowsAffected, queryNameError(name, err)
+	}
+
+	return rowsAffected, nil
+}
+
+// queryNameError prefixes err with the name of the registered query it occurred in.
+func queryNameError(name string, err error) error {
+	return fmt.Errorf("query %q: %w", name, err)
+}
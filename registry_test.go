@@ -0,0 +1,100 @@
+package goldilocks_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRegistry(t *testing.T) {
+	t.Parallel()
+
+	registry := goldilocks.NewQueryRegistry()
+	registry.Register("sum", "select $1::int4 + $2::int4")
+	registry.Register("divide_by_zero", "select 1 / 0")
+
+	require.Equal(t, []string{"divide_by_zero", "sum"}, registry.Names())
+	sql, ok := registry.SQL("sum")
+	require.True(t, ok)
+	require.Equal(t, "select $1::int4 + $2::int4", sql)
+	require.Panics(t, func() { registry.Register("sum", "select 1") })
+
+	_, err := registry.Exec(context.Background(), nil, "missing")
+	require.EqualError(t, err, `query "missing": not registered`)
+	require.True(t, errors.Is(err, goldilocks.ErrQueryNotRegistered))
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	tracer := &testTracer{}
+	logger := &testLogger{}
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{Tracer: tracer, Logger: logger})
+
+	var sum int32
+	rowCount, err := registry.Query(context.Background(), db, "sum", []interface{}{int32(1), int32(2)}, []interface{}{&sum}, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 1, rowCount)
+	require.EqualValues(t, 3, sum)
+
+	require.Equal(t, "sum", tracer.after[0].Name)
+	require.Equal(t, "sum", logger.entries[0].data["name"])
+
+	_, err = registry.Exec(context.Background(), db, "divide_by_zero")
+	require.Error(t, err)
+	require.Regexp(t, `^query "divide_by_zero": `, err.Error())
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr))
+	require.Equal(t, pgerrcode.DivisionByZero, pgErr.Code)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+type registryWidget struct {
+	ID      int32  `db:"id"`
+	Name    string `db:"name"`
+	Ignored string
+}
+
+func TestQueryRegistryResultMapping(t *testing.T) {
+	t.Parallel()
+
+	registry := goldilocks.NewQueryRegistry()
+	registry.RegisterStruct("widget", "select 1::int4, 'foo'::text", registryWidget{})
+	registry.RegisterResults("pair", "select 1::int4, 2::int4", func(dst interface{}) ([]interface{}, error) {
+		pair := dst.(*[2]int32)
+		return []interface{}{&pair[0], &pair[1]}, nil
+	})
+	registry.Register("unmapped", "select 1")
+	require.Panics(t, func() { registry.RegisterStruct("int", "select 1", 1) })
+	require.Panics(t, func() { registry.RegisterStruct("untagged", "select 1", struct{ ID int32 }{}) })
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var widget registryWidget
+	rowCount, err := registry.QueryInto(context.Background(), db, "widget", nil, &widget, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 1, rowCount)
+	require.Equal(t, registryWidget{ID: 1, Name: "foo"}, widget)
+
+	var pair [2]int32
+	_, err = registry.QueryInto(context.Background(), db, "pair", nil, &pair, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, [2]int32{1, 2}, pair)
+
+	_, err = registry.QueryInto(context.Background(), db, "widget", nil, &pair, func() error { return nil })
+	require.EqualError(t, err, `query "widget": dst must be a non-nil *goldilocks_test.registryWidget, got *[2]int32`)
+
+	_, err = registry.QueryInto(context.Background(), db, "unmapped", nil, &widget, func() error { return nil })
+	require.EqualError(t, err, `query "unmapped": registered without a result mapping`)
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,257 @@
+package goldilocks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+// RowMap is a row of a query with an unknown set of columns. Each value is keyed by its column name. If multiple
+// columns have the same name the last one is kept.
+//
+// A *RowMap or *map[string]interface{} can be the only result of Query. Before rowFunc is called for each row a new
+// map is assigned to it so maps can be retained after rowFunc returns. Values are decoded to Go types by column type:
+//
+//   - bool is bool
+//   - int2, int4, and int8 are int16, int32, and int64
+//   - float4 and float8 are float32 and float64
+//   - bytea is []byte
+//   - json and jsonb are json.RawMessage
+//   - date and timestamptz are time.Time
+//   - all other types, including numeric, are the string of their text format
+//   - NULL is nil
+//
+// Determining the column types costs an additional round trip to the server unless the query is a prepared statement.
+// With the simple protocol, dates and times are also strings.
+type RowMap map[string]interface{}
+
+// rowMapResult returns the map that is the only element of results, if it is one.
+func rowMapResult(results []interface{}) (*map[string]interface{}, bool) {
+	if len(results) != 1 {
+		return nil, false
+	}
+
+	switch dst := results[0].(type) {
+	case *map[string]interface{}:
+		return dst, true
+	case *RowMap:
+		return (*map[string]interface{})(dst), true
+	default:
+		return nil, false
+	}
+}
+
+// rowMapBinaryOIDs are the types that are decoded from the binary format into a RowMap.
+var rowMapBinaryOIDs = map[uint32]struct{}{
+	boolOID:        {},
+	int2OID:        {},
+	int4OID:        {},
+	int8OID:        {},
+	float4OID:      {},
+	float8OID:      {},
+	byteaOID:       {},
+	jsonOID:        {},
+	jsonbOID:       {},
+	dateOID:        {},
+	timestamptzOID: {},
+}
+
+// queryRowMap executes sql, or the prepared statement stmt if it is not nil, assigning each row to dst.
+func (c *Conn) queryRowMap(ctx context.Context, sql string, stmt *Statement, args []interface{}, dst *map[string]interface{}, rowFunc func() error) (int64, error) {
+	c.resultFormats = c.resultFormats[0:0]
+	c.resultDecoders = c.resultDecoders[0:0]
+
+	var rowCount int64
+	var err error
+	if stmt == nil && c.useSimpleProtocol(ctx) {
+		sql, err = c.interpolate(sql, args)
+		if err != nil {
+			return 0, err
+		}
+		rowCount, err = c.execSimpleRowMap(ctx, sql, dst, rowFunc)
+	} else {
+		err = c.prepareParams(args, stmt)
+		if err != nil {
+			return 0, err
+		}
+
+		if stmt == nil {
+			// Describe the query as the unnamed statement to learn the result types. It is then executed without being
+			// parsed again.
+			sd, err := c.pgconn.Prepare(ctx, "", sql, c.paramOIDs)
+			if err != nil {
+				return 0, c.connError(err)
+			}
+			stmt = &Statement{SQL: sql, ParamOIDs: sd.ParamOIDs, Fields: sd.Fields}
+		} else {
+			err = c.checkStatementParams(stmt, args)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		for _, f := range stmt.Fields {
+			format := int16(textFormat)
+			if _, ok := rowMapBinaryOIDs[f.DataTypeOID]; ok {
+				format = binaryFormat
+			}
+			c.resultFormats = append(c.resultFormats, format)
+		}
+
+		rr := c.pgconn.ExecPrepared(ctx, stmt.Name, c.paramValues, c.paramFormats, c.resultFormats)
+		rowCount, err = c.decodeRowMapRows(rr, dst, rowFunc)
+		rr.Close()
+	}
+	if err != nil {
+		return rowCount, err
+	}
+
+	c.releaseOversizedParamValuesBuf()
+
+	return rowCount, nil
+}
+
+// execSimpleRowMap executes sql with the simple protocol assigning each row of every statement to dst.
+func (c *Conn) execSimpleRowMap(ctx context.Context, sql string, dst *map[string]interface{}, rowFunc func() error) (int64, error) {
+	mrr := c.pgconn.Exec(ctx, sql)
+	defer mrr.Close()
+
+	var rowCount int64
+	for mrr.NextResult() {
+		n, err := c.decodeRowMapRows(mrr.ResultReader(), dst, rowFunc)
+		rowCount += n
+		if err != nil {
+			return rowCount, err
+		}
+	}
+
+	err := mrr.Close()
+	if err != nil {
+		return rowCount, c.connError(err)
+	}
+
+	return rowCount, nil
+}
+
+// decodeRowMapRows assigns a map of each row of rr to dst and calls rowFunc.
+func (c *Conn) decodeRowMapRows(rr *pgconn.ResultReader, dst *map[string]interface{}, rowFunc func() error) (int64, error) {
+	fields := rr.FieldDescriptions()
+
+	var rowCount int64
+	for rr.NextRow() {
+		rowCount++
+
+		values := rr.Values()
+		m := make(map[string]interface{}, len(fields))
+		for i, f := range fields {
+			v, err := decodeRowMapValue(f.DataTypeOID, f.Format, values[i])
+			if err != nil {
+				return rowCount, fmt.Errorf("cannot decode column %s: %w", f.Name, err)
+			}
+			m[string(f.Name)] = v
+		}
+		*dst = m
+
+		err := rowFunc()
+		if err != nil {
+			return rowCount, err
+		}
+	}
+
+	_, err := rr.Close()
+	if err != nil {
+		return rowCount, c.connError(err)
+	}
+
+	return rowCount, nil
+}
+
+// decodeRowMapValue decodes buf of type oid in format to a Go value.
+func decodeRowMapValue(oid uint32, format int16, buf []byte) (interface{}, error) {
+	if buf == nil {
+		return nil, nil
+	}
+
+	if format == textFormat {
+		switch oid {
+		case boolOID:
+			var b bool
+			err := readNotNullBoolText(buf, &b)
+			return b, err
+		case int2OID:
+			var n int16
+			err := readNotNullInt16Text(buf, &n)
+			return n, err
+		case int4OID:
+			var n int32
+			err := readNotNullInt32Text(buf, &n)
+			return n, err
+		case int8OID:
+			var n int64
+			err := readNotNullInt64Text(buf, &n)
+			return n, err
+		case float4OID:
+			var f float32
+			err := readNotNullFloat32Text(buf, &f)
+			return f, err
+		case float8OID:
+			var f float64
+			err := readNotNullFloat64Text(buf, &f)
+			return f, err
+		case byteaOID:
+			var b bytea
+			err := b.DecodeTextResult(buf)
+			return []byte(b), err
+		case jsonOID, jsonbOID:
+			return json.RawMessage(append([]byte(nil), buf...)), nil
+		default:
+			return string(buf), nil
+		}
+	}
+
+	switch oid {
+	case boolOID:
+		var b bool
+		err := readNotNullBool(buf, &b)
+		return b, err
+	case int2OID:
+		var n int16
+		err := readNotNullInt16(buf, &n)
+		return n, err
+	case int4OID:
+		var n int32
+		err := readNotNullInt32(buf, &n)
+		return n, err
+	case int8OID:
+		var n int64
+		err := readNotNullInt64(buf, &n)
+		return n, err
+	case float4OID:
+		var f float32
+		err := readNotNullFloat32(buf, &f)
+		return f, err
+	case float8OID:
+		var f float64
+		err := readNotNullFloat64(buf, &f)
+		return f, err
+	case byteaOID:
+		return append([]byte(nil), buf...), nil
+	case jsonOID, jsonbOID:
+		var j jsonRawMessage
+		err := j.DecodeResult(buf)
+		return json.RawMessage(j), err
+	case dateOID:
+		var t time.Time
+		err := readNotNullDate(buf, &t)
+		return t, err
+	case timestamptzOID:
+		var t time.Time
+		err := readNotNullTime(buf, &t)
+		return t, err
+	default:
+		return nil, fmt.Errorf("unexpected binary format of type oid %d", oid)
+	}
+}
@@ -0,0 +1,84 @@
+package goldilocks_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowMap(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var row goldilocks.RowMap
+	var rows []goldilocks.RowMap
+	rowCount, err := db.Query(
+		context.Background(),
+		`select n, n::int2 as small, n::int8 as big, n * 1.5::float8 as f, n = 1 as b, $1::text as s, '\x01ff'::bytea as bytes,
+			'{"a": 1}'::jsonb as j, '2020-01-02'::date as d, '1.50'::numeric as num, null::text as null_text
+		from generate_series(1, 2) n`,
+		[]interface{}{"foo"},
+		[]interface{}{&row},
+		func() error {
+			rows = append(rows, row)
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, rowCount)
+
+	require.Equal(t, goldilocks.RowMap{
+		"n":         int32(1),
+		"small":     int16(1),
+		"big":       int64(1),
+		"f":         1.5,
+		"b":         true,
+		"s":         "foo",
+		"bytes":     []byte{1, 255},
+		"j":         json.RawMessage(`{"a": 1}`),
+		"d":         time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		"num":       "1.50",
+		"null_text": nil,
+	}, rows[0])
+	require.Equal(t, int32(2), rows[1]["n"])
+	require.Equal(t, false, rows[1]["b"])
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestRowMapSimpleProtocol(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{SimpleProtocol: true})
+
+	var row map[string]interface{}
+	_, err = db.Query(
+		context.Background(),
+		`select $1::int4 as n, true as b, '\x01ff'::bytea as bytes, '2020-01-02'::date as d`,
+		[]interface{}{int32(7)},
+		[]interface{}{&row},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"n":     int32(7),
+		"b":     true,
+		"bytes": []byte{1, 255},
+		"d":     "2020-01-02",
+	}, row)
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,57 @@
+package goldilocks
+
+import (
+	"context"
+	"strings"
+)
+
+// SetSearchPath sets the search_path of the session to schemas. The schemas are quoted so they are used exactly as
+// given. If c belongs to a Pool the search_path is reset when c is released. See SetSessionParam.
+func (c *Conn) SetSearchPath(ctx context.Context, schemas ...string) error {
+	names := make([]string, len(schemas))
+	for i, s := range schemas {
+		names[i] = Identifier(s).Sanitize()
+	}
+	return c.SetSessionParam(ctx, "search_path", strings.Join(names, ", "))
+}
+
+// SetSessionParam sets the run-time parameter name to value for the rest of the session. If c belongs to a Pool all
+// session parameters are reset with RESET ALL when c is released so the change does not leak to other users of the
+// Pool. Parameters changed with SET statements are not tracked and are not reset.
+func (c *Conn) SetSessionParam(ctx context.Context, name, value string) error {
+	err := c.acquire(ctx)
+	if err != nil {
+		return c.handleError(err)
+	}
+	defer c.release()
+
+	_, err = c.exec(ctx, "select set_config($1, $2, false)", nil, name, value)
+	if err != nil {
+		return err
+	}
+	c.sessionParamsChanged = true
+	return nil
+}
+
+// SetLocal sets the run-time parameter name to value until the end of the current transaction. db must be in a
+// transaction such as the StdDB passed to the function given to Begin or a Tx. Outside of a transaction it has no
+// effect.
+func SetLocal(ctx context.Context, db StdDB, name, value string) error {
+	_, err := db.Exec(ctx, "select set_config($1, $2, true)", name, value)
+	return err
+}
+
+// resetSessionParams resets all session parameters if they were changed by SetSessionParam. It returns an error if
+// they could not be reset.
+func (c *Conn) resetSessionParams(ctx context.Context) error {
+	if !c.sessionParamsChanged {
+		return nil
+	}
+
+	err := c.pgconn.Exec(ctx, "reset all").Close()
+	if err != nil {
+		return err
+	}
+	c.sessionParamsChanged = false
+	return nil
+}
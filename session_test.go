@@ -0,0 +1,91 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSetSearchPath(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	err = db.SetSearchPath(context.Background(), "foo", "Bar baz", "public")
+	require.NoError(t, err)
+
+	var searchPath string
+	_, err = db.Query(context.Background(), "show search_path", nil, []interface{}{&searchPath}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, `foo, "Bar baz", public`, searchPath)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestSetLocal(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var name string
+	err = db.Begin(context.Background(), func(tx goldilocks.StdDB) error {
+		err := goldilocks.SetLocal(context.Background(), tx, "application_name", "set local test")
+		if err != nil {
+			return err
+		}
+		_, err = tx.Query(context.Background(), "show application_name", nil, []interface{}{&name}, func() error { return nil })
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, "set local test", name)
+
+	_, err = db.Query(context.Background(), "show application_name", nil, []interface{}{&name}, func() error { return nil })
+	require.NoError(t, err)
+	require.NotEqual(t, "set local test", name)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestPoolSessionParams(t *testing.T) {
+	t.Parallel()
+
+	config, err := goldilocks.ParsePoolConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+	config.SessionParams = map[string]string{"search_path": "pg_catalog"}
+
+	db, err := goldilocks.NewPoolConfig(config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var searchPath string
+	_, err = db.Query(context.Background(), "show search_path", nil, []interface{}{&searchPath}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, "pg_catalog", searchPath)
+
+	err = db.Acquire(context.Background(), func(conn *goldilocks.Conn) error {
+		err := conn.SetSearchPath(context.Background(), "public")
+		if err != nil {
+			return err
+		}
+		_, err = conn.Query(context.Background(), "show search_path", nil, []interface{}{&searchPath}, func() error { return nil })
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, "public", searchPath)
+
+	// The only connection was reset when it was released.
+	_, err = db.Query(context.Background(), "show search_path", nil, []interface{}{&searchPath}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, "pg_catalog", searchPath)
+}
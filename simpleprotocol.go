@@ -0,0 +1,467 @@
+package goldilocks
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+type simpleProtocolCtxKey struct{}
+
+// WithSimpleProtocol returns a copy of ctx that causes Query and Exec to use the simple protocol. See
+// ConnConfig.SimpleProtocol.
+func WithSimpleProtocol(ctx context.Context) context.Context {
+	return context.WithValue(ctx, simpleProtocolCtxKey{}, true)
+}
+
+// useSimpleProtocol reports whether a query with ctx should use the simple protocol.
+func (c *Conn) useSimpleProtocol(ctx context.Context) bool {
+	return c.config.SimpleProtocol || ctx.Value(simpleProtocolCtxKey{}) != nil
+}
+
+// errSimpleProtocolUnsupported is returned by paramLiteral for a param that cannot be converted to a literal.
+var errSimpleProtocolUnsupported = errors.New("cannot be sent with the simple protocol")
+
+// QuoteLiteral returns s quoted as a PostgreSQL string literal so it can be included in SQL. The literal is correct
+// whether or not standard_conforming_strings is enabled. It returns an error if s contains a NUL byte, which PostgreSQL
+// cannot store in a string.
+func QuoteLiteral(s string) (string, error) {
+	if strings.IndexByte(s, 0) >= 0 {
+		return "", errors.New("string literal cannot contain a NUL byte")
+	}
+
+	s = strings.ReplaceAll(s, `'`, `''`)
+	if strings.IndexByte(s, '\\') >= 0 {
+		return `E'` + strings.ReplaceAll(s, `\`, `\\`) + `'`, nil
+	}
+	return `'` + s + `'`, nil
+}
+
+// simpleProtocolTypeNames are the names used to cast text format params whose type is known.
+var simpleProtocolTypeNames = map[uint32]string{
+	boolOID:        "bool",
+	byteaOID:       "bytea",
+	nameOID:        "name",
+	int8OID:        "int8",
+	int2OID:        "int2",
+	int4OID:        "int4",
+	textOID:        "text",
+	float4OID:      "float4",
+	float8OID:      "float8",
+	inetOID:        "inet",
+	macaddrOID:     "macaddr",
+	bpcharOID:      "bpchar",
+	varcharOID:     "varchar",
+	dateOID:        "date",
+	timestamptzOID: "timestamptz",
+	intervalOID:    "interval",
+	numericOID:     "numeric",
+	jsonbOID:       "jsonb",
+}
+
+// interpolate returns sql with its placeholders replaced by literals of args.
+func (c *Conn) interpolate(sql string, args []interface{}) (string, error) {
+	err := c.prepareParams(args, nil)
+	if err != nil {
+		return "", err
+	}
+
+	literals := make([]string, len(args))
+	for i := range args {
+		literals[i], err = paramLiteral(c.paramValues[i], c.paramOIDs[i], c.paramFormats[i])
+		if err == errSimpleProtocolUnsupported {
+			return "", fmt.Errorf("args[%d] of type %T %w", i, args[i], err)
+		}
+		if err != nil {
+			return "", &EncodeError{Index: i, Type: fmt.Sprintf("%T", args[i]), OID: c.paramOIDs[i], Err: err}
+		}
+	}
+
+	return replacePlaceholders(sql, literals)
+}
+
+// paramLiteral converts an encoded param to a SQL literal. Text format params are quoted and cast to their type if it
+// is known. Otherwise they are left untyped for the server to infer, as it does for a param without a type. Binary
+// format params are only supported for builtin scalar types.
+func paramLiteral(value []byte, oid uint32, format int16) (string, error) {
+	if value == nil {
+		return "null", nil
+	}
+
+	if format == textFormat {
+		s, err := QuoteLiteral(string(value))
+		if err != nil {
+			return "", err
+		}
+		if name, ok := simpleProtocolTypeNames[oid]; ok {
+			s += "::" + name
+		}
+		return s, nil
+	}
+
+	switch oid {
+	case boolOID:
+		var b bool
+		err := readNotNullBool(value, &b)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(b), nil
+	case int2OID, int4OID, int8OID:
+		n, err := readNotNullAnyInt(value, "integer")
+		if err != nil {
+			return "", err
+		}
+		return numberLiteral(strconv.FormatInt(n, 10)), nil
+	case float4OID:
+		var f float32
+		err := readNotNullFloat32(value, &f)
+		if err != nil {
+			return "", err
+		}
+		return floatLiteral(float64(f), 32, "float4"), nil
+	case float8OID:
+		var f float64
+		err := readNotNullFloat64(value, &f)
+		if err != nil {
+			return "", err
+		}
+		return floatLiteral(f, 64, "float8"), nil
+	case numericOID:
+		if len(value) >= 8 {
+			switch binary.BigEndian.Uint16(value[4:]) {
+			case numericNaNSign:
+				return "'NaN'::numeric", nil
+			case numericInfinitySign:
+				return "'Infinity'::numeric", nil
+			case numericNegativeInfinitySign:
+				return "'-Infinity'::numeric", nil
+			}
+		}
+		var n BigNumeric
+		err := readNotNullBigNumeric(value, &n)
+		if err != nil {
+			return "", err
+		}
+		return numberLiteral(n.String()), nil
+	case byteaOID:
+		s, err := QuoteLiteral(`\x` + hex.EncodeToString(value))
+		if err != nil {
+			return "", err
+		}
+		return s + "::bytea", nil
+	case textOID, varcharOID, bpcharOID, nameOID:
+		s, err := QuoteLiteral(string(value))
+		if err != nil {
+			return "", err
+		}
+		return s + "::" + simpleProtocolTypeNames[oid], nil
+	case jsonbOID:
+		if len(value) == 0 || value[0] != jsonbVersion {
+			return "", errors.New("invalid jsonb")
+		}
+		s, err := QuoteLiteral(string(value[1:]))
+		if err != nil {
+			return "", err
+		}
+		return s + "::jsonb", nil
+	case dateOID:
+		var t time.Time
+		err := readNotNullDate(value, &t)
+		if err != nil {
+			return "", err
+		}
+		switch Date(t) {
+		case DateInfinity:
+			return "'infinity'::date", nil
+		case DateNegativeInfinity:
+			return "'-infinity'::date", nil
+		}
+		year, era := eraYear(t.Year())
+		return fmt.Sprintf("'%04d-%02d-%02d%s'::date", year, t.Month(), t.Day(), era), nil
+	case timestamptzOID:
+		var t time.Time
+		err := readNotNullTime(value, &t)
+		if err != nil {
+			return "", err
+		}
+		switch t {
+		case TimeInfinity:
+			return "'infinity'::timestamptz", nil
+		case TimeNegativeInfinity:
+			return "'-infinity'::timestamptz", nil
+		}
+		t = t.UTC()
+		year, era := eraYear(t.Year())
+		return fmt.Sprintf(
+			"'%04d-%02d-%02d %02d:%02d:%02d.%06d+00%s'::timestamptz",
+			year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1000, era,
+		), nil
+	default:
+		return "", errSimpleProtocolUnsupported
+	}
+}
+
+// numberLiteral returns s with a leading space if it is negative so it cannot form a -- comment with a preceding minus.
+func numberLiteral(s string) string {
+	if strings.HasPrefix(s, "-") {
+		return " " + s
+	}
+	return s
+}
+
+// floatLiteral returns the literal of f formatted with bitSize. NaN and infinity are cast to typeName because they are
+// not numeric literals.
+func floatLiteral(f float64, bitSize int, typeName string) string {
+	switch {
+	case math.IsNaN(f):
+		return "'NaN'::" + typeName
+	case math.IsInf(f, 1):
+		return "'Infinity'::" + typeName
+	case math.IsInf(f, -1):
+		return "'-Infinity'::" + typeName
+	}
+	return numberLiteral(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
+
+// eraYear converts an astronomical year, where year 0 is 1 BC, to the year and era suffix PostgreSQL expects.
+func eraYear(year int) (int, string) {
+	if year <= 0 {
+		return 1 - year, " BC"
+	}
+	return year, ""
+}
+
+// replacePlaceholders returns sql with each $n placeholder replaced by literals[n-1]. Placeholders in string literals,
+// quoted identifiers, dollar quoted strings, and comments are not replaced.
+func replacePlaceholders(sql string, literals []string) (string, error) {
+	sb := &strings.Builder{}
+	sb.Grow(len(sql))
+
+	for i := 0; i < len(sql); {
+		ch := sql[i]
+		switch {
+		case ch == '\'':
+			escapes := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i == 1 || !isIdentByte(sql[i-2]))
+			end := skipQuoted(sql, i, '\'', escapes)
+			sb.WriteString(sql[i:end])
+			i = end
+		case ch == '"':
+			end := skipQuoted(sql, i, '"', false)
+			sb.WriteString(sql[i:end])
+			i = end
+		case ch == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql)
+			} else {
+				end += i + 1
+			}
+			sb.WriteString(sql[i:end])
+			i = end
+		case ch == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := skipBlockComment(sql, i)
+			sb.WriteString(sql[i:end])
+			i = end
+		case ch == '$' && (i == 0 || !isIdentByte(sql[i-1])):
+			j := i + 1
+			for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				n, err := strconv.Atoi(sql[i+1 : j])
+				if err != nil || n < 1 || n > len(literals) {
+					return "", fmt.Errorf("sql references %s but there are %d args", sql[i:j], len(literals))
+				}
+				sb.WriteString(literals[n-1])
+				i = j
+				continue
+			}
+
+			end := skipDollarQuoted(sql, i)
+			sb.WriteString(sql[i:end])
+			i = end
+		default:
+			sb.WriteByte(ch)
+			i++
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// isIdentByte reports whether b can be part of an unquoted identifier or keyword.
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' || b >= 0x80 || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// skipQuoted returns the index after the quoted string or identifier starting at sql[start]. A doubled quote is part of
+// the string. If escapes is true a backslash escapes the next byte.
+func skipQuoted(sql string, start int, quote byte, escapes bool) int {
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if escapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// skipBlockComment returns the index after the possibly nested block comment starting at sql[start].
+func skipBlockComment(sql string, start int) int {
+	depth := 0
+	for i := start; i < len(sql)-1; i++ {
+		switch {
+		case sql[i] == '/' && sql[i+1] == '*':
+			depth++
+			i++
+		case sql[i] == '*' && sql[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(sql)
+}
+
+// skipDollarQuoted returns the index after the dollar quoted string starting at sql[start]. If sql[start] does not begin
+// a dollar quote it returns the index after the $.
+func skipDollarQuoted(sql string, start int) int {
+	i := start + 1
+	for i < len(sql) && sql[i] != '$' {
+		if !isIdentByte(sql[i]) || (i == start+1 && sql[i] >= '0' && sql[i] <= '9') {
+			return start + 1
+		}
+		i++
+	}
+	if i == len(sql) {
+		return start + 1
+	}
+
+	tag := sql[start : i+1]
+	end := strings.Index(sql[i+1:], tag)
+	if end < 0 {
+		return len(sql)
+	}
+	return i + 1 + end + len(tag)
+}
+
+// execSimpleQueryRows executes sql with the simple protocol and the prepared results. The rows of every statement in
+// sql are decoded.
+func (c *Conn) execSimpleQueryRows(ctx context.Context, sql string, rowFunc func() error) (int64, error) {
+	mrr := c.pgconn.Exec(ctx, sql)
+	defer mrr.Close()
+
+	var rowCount int64
+	for mrr.NextResult() {
+		err := c.checkResultCount(mrr.ResultReader().FieldDescriptions())
+		if err != nil {
+			return rowCount, err
+		}
+
+		n, err := c.decodeRows(mrr.ResultReader(), rowFunc)
+		rowCount += n
+		if err != nil {
+			return rowCount, err
+		}
+	}
+
+	err := mrr.Close()
+	if err != nil {
+		return rowCount, c.connError(err)
+	}
+
+	return rowCount, nil
+}
+
+// execSimple executes sql with the simple protocol and returns the rows affected by the last statement in sql.
+func (c *Conn) execSimple(ctx context.Context, sql string) (int64, error) {
+	results, err := c.pgconn.Exec(ctx, sql).ReadAll()
+	if err != nil {
+		return 0, c.connError(err)
+	}
+
+	var commandTag pgconn.CommandTag
+	if len(results) > 0 {
+		commandTag = results[len(results)-1].CommandTag
+	}
+	return commandTag.RowsAffected(), nil
+}
+
+// useTextResults switches the prepared results to the text format, which is the only format the simple protocol
+// supports.
+func (c *Conn) useTextResults(results []interface{}) error {
+	for i, rd := range c.resultDecoders {
+		if c.resultFormats[i] == textFormat {
+			continue
+		}
+		trd, ok := rd.(TextResultDecoder)
+		if !ok {
+			return fmt.Errorf("results[%d] of type %T %w", i, results[i], errSimpleProtocolUnsupported)
+		}
+		c.resultFormats[i] = textFormat
+		c.resultDecoders[i] = textResultDecoder{trd}
+	}
+	return nil
+}
+
+// simpleQueryRows executes sql with args interpolated using the simple protocol.
+func (c *Conn) simpleQueryRows(ctx context.Context, sql string, args []interface{}, results []interface{}, rowFunc func() error) (int64, error) {
+	sql, err := c.interpolate(sql, args)
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.prepareResults(results)
+	if err != nil {
+		return 0, err
+	}
+	err = c.useTextResults(results)
+	if err != nil {
+		return 0, err
+	}
+
+	rowCount, err := c.execSimpleQueryRows(ctx, sql, rowFunc)
+	if err != nil {
+		return rowCount, err
+	}
+
+	c.releaseOversizedParamValuesBuf()
+
+	return rowCount, nil
+}
+
+// simpleExec executes sql with args interpolated using the simple protocol.
+func (c *Conn) simpleExec(ctx context.Context, sql string, args []interface{}) (int64, error) {
+	sql, err := c.interpolate(sql, args)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := c.execSimple(ctx, sql)
+	if err != nil {
+		return 0, err
+	}
+
+	c.releaseOversizedParamValuesBuf()
+
+	return rowsAffected, nil
+}
@@ -0,0 +1,157 @@
+package goldilocks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteLiteral(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		s        string
+		expected string
+	}{
+		{s: "", expected: `''`},
+		{s: "foo", expected: `'foo'`},
+		{s: "it's", expected: `'it''s'`},
+		{s: `a\b`, expected: `E'a\\b'`},
+		{s: `\'; drop table users; --`, expected: `E'\\''; drop table users; --'`},
+	} {
+		actual, err := goldilocks.QuoteLiteral(tt.s)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, actual, tt.s)
+	}
+
+	_, err := goldilocks.QuoteLiteral("a\x00b")
+	require.EqualError(t, err, "string literal cannot contain a NUL byte")
+}
+
+func TestSimpleProtocolQuery(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{SimpleProtocol: true})
+
+	tm := time.Date(2020, 3, 4, 5, 6, 7, 123456000, time.UTC)
+	var (
+		s, quote, backslash, placeholders string
+		n16                               int16
+		n32                               int32
+		n64, difference                   int64
+		f64                               float64
+		nan                               float64
+		b                                 bool
+		bytes                             []byte
+		date                              string
+		tsEqual                           bool
+		j                                 json.RawMessage
+		null                              goldilocks.NullString
+	)
+	_, err = db.Query(
+		context.Background(),
+		`select $1::text, $2::text, $3::text, $4::int2, $5::int4, $6::int8, 1-$6, $7::float8, $8::float8, $9::bool,
+			$10::bytea, $11::date::text, $12::timestamptz = '2020-03-04 05:06:07.123456Z', $13::jsonb, $14::text,
+			'$1' || "t"."$1" || $$ $1 $$ || $tag$ $1 $tag$ -- $1
+			/* $1 /* $1 */ $1 */ from (select '$1' as "$1") t`,
+		[]interface{}{
+			"foo", "it's", `a\b`, int16(-1), int32(2), int64(-3), 1.5, math.NaN(), true,
+			[]byte{0, 1, 255}, goldilocks.Date(time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC)), tm,
+			json.RawMessage(`{"a": 1}`), goldilocks.NullString{},
+		},
+		[]interface{}{
+			&s, &quote, &backslash, &n16, &n32, &n64, &difference, &f64, &nan, &b, &bytes, &date, &tsEqual, &j, &null,
+			&placeholders,
+		},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, "foo", s)
+	require.Equal(t, "it's", quote)
+	require.Equal(t, `a\b`, backslash)
+	require.EqualValues(t, -1, n16)
+	require.EqualValues(t, 2, n32)
+	require.EqualValues(t, -3, n64)
+	require.EqualValues(t, 4, difference)
+	require.Equal(t, 1.5, f64)
+	require.True(t, math.IsNaN(nan))
+	require.True(t, b)
+	require.Equal(t, []byte{0, 1, 255}, bytes)
+	require.Equal(t, "2021-02-03", date)
+	require.True(t, tsEqual)
+	require.JSONEq(t, `{"a": 1}`, string(j))
+	require.False(t, null.Valid)
+	require.Equal(t, "$1$1 $1  $1 ", placeholders)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestSimpleProtocolExec(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+	ctx := goldilocks.WithSimpleProtocol(context.Background())
+
+	// SET cannot be parameterized with the extended protocol.
+	_, err = db.Exec(ctx, "set statement_timeout = $1", "5s")
+	require.NoError(t, err)
+
+	var timeout string
+	_, err = db.Query(ctx, "show statement_timeout", nil, []interface{}{&timeout}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, "5s", timeout)
+
+	rowsAffected, err := db.Exec(ctx, "create temporary table t (n int); insert into t select generate_series(1, $1)", 3)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, rowsAffected)
+
+	_, err = db.Exec(ctx, "select $2", 1)
+	require.EqualError(t, err, "sql references $2 but there are 1 args")
+
+	_, err = db.Exec(ctx, "select $1", goldilocks.Point{X: 1, Y: 2})
+	require.EqualError(t, err, "args[0] of type goldilocks.Point cannot be sent with the simple protocol")
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestSimpleProtocolErrorDoesNotIncludeParamValues(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{SimpleProtocol: true})
+
+	secret := "correct horse battery staple"
+	_, err = db.Exec(context.Background(), "select $1::text form goldilocks_missing", secret)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), secret)
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr))
+
+	var s string
+	_, err = db.Query(context.Background(), "select $1::text form goldilocks_missing", []interface{}{secret}, []interface{}{&s}, func() error { return nil })
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), secret)
+
+	_, err = db.Query(context.Background(), "select $1::text form goldilocks_missing", []interface{}{secret}, []interface{}{&goldilocks.RowMap{}}, func() error { return nil })
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), secret)
+
+	ensurePgConnValid(t, pgConn)
+}
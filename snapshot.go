@@ -0,0 +1,40 @@
+package goldilocks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ExportSnapshot exports the snapshot of the transaction db is in and returns its id. db must be the StdDB passed to a
+// Begin callback. The snapshot can be imported with ImportSnapshot by other connections until that transaction ends.
+//
+// Set the transaction isolation level to repeatable read before calling ExportSnapshot so the exporting transaction
+// keeps reading from the exported snapshot.
+func ExportSnapshot(ctx context.Context, db StdDB) (string, error) {
+	var snapshotID string
+	_, err := db.Query(ctx, "select pg_export_snapshot()", nil, []interface{}{&snapshotID}, func() error { return nil })
+	if err != nil {
+		return "", err
+	}
+	return snapshotID, nil
+}
+
+// ImportSnapshot makes the transaction db is in a read only, repeatable read transaction that reads from the snapshot
+// snapshotID exported by ExportSnapshot. db must be the StdDB passed to a Begin callback and ImportSnapshot must be
+// called before any other query in the transaction.
+func ImportSnapshot(ctx context.Context, db StdDB, snapshotID string) error {
+	// set transaction snapshot does not accept params so the snapshot id is interpolated. Snapshot ids only contain hex
+	// digits and dashes.
+	if snapshotID == "" || strings.Trim(snapshotID, "0123456789ABCDEFabcdef-") != "" {
+		return fmt.Errorf("invalid snapshot id %q", snapshotID)
+	}
+
+	_, err := db.Exec(ctx, "set transaction isolation level repeatable read, read only")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, fmt.Sprintf("set transaction snapshot '%s'", snapshotID))
+	return err
+}
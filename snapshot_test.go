@@ -0,0 +1,64 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportSnapshot(t *testing.T) {
+	t.Parallel()
+
+	exportPgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, exportPgConn)
+	exportDB := goldilocks.NewConn(exportPgConn)
+
+	importPgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, importPgConn)
+	importDB := goldilocks.NewConn(importPgConn)
+
+	err = exportDB.Begin(context.Background(), func(exportTx goldilocks.StdDB) error {
+		_, err := exportTx.Exec(context.Background(), "set transaction isolation level repeatable read")
+		require.NoError(t, err)
+
+		snapshotID, err := goldilocks.ExportSnapshot(context.Background(), exportTx)
+		require.NoError(t, err)
+		require.NotEmpty(t, snapshotID)
+
+		var exportSnapshot string
+		_, err = exportTx.Query(context.Background(), "select txid_current_snapshot()::text", nil, []interface{}{&exportSnapshot}, func() error { return nil })
+		require.NoError(t, err)
+
+		err = importDB.Begin(context.Background(), func(importTx goldilocks.StdDB) error {
+			err := goldilocks.ImportSnapshot(context.Background(), importTx, snapshotID)
+			require.NoError(t, err)
+
+			var importSnapshot string
+			_, err = importTx.Query(context.Background(), "select txid_current_snapshot()::text", nil, []interface{}{&importSnapshot}, func() error { return nil })
+			require.NoError(t, err)
+			require.Equal(t, exportSnapshot, importSnapshot)
+
+			_, err = importTx.Exec(context.Background(), "create temporary table t(id int)")
+			require.Error(t, err)
+			return nil
+		})
+		require.Equal(t, goldilocks.ErrTxFailed, err)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = importDB.Begin(context.Background(), func(importTx goldilocks.StdDB) error {
+		return goldilocks.ImportSnapshot(context.Background(), importTx, "1'; drop table users; --")
+	})
+	require.EqualError(t, err, `invalid snapshot id "1'; drop table users; --"`)
+
+	ensurePgConnValid(t, exportPgConn)
+	ensurePgConnValid(t, importPgConn)
+}
@@ -0,0 +1,130 @@
+package goldilocks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgproto3/v2"
+)
+
+// Statement is a prepared statement created by Conn.Prepare. The server parses and plans it once so repeatedly
+// executing it with Conn.QueryPrepared or Conn.ExecPrepared avoids that cost.
+type Statement struct {
+	Name      string
+	SQL       string
+	ParamOIDs []uint32                    // oids of the param types inferred by the server
+	Fields    []pgproto3.FieldDescription // descriptions of the result columns
+}
+
+// Prepare creates a prepared statement named name for sql on the server. A prepared statement belongs to the
+// connection it was prepared on and exists until it is deallocated with Deallocate or the connection is closed. name
+// must not be empty because every query replaces the unnamed statement.
+func (c *Conn) Prepare(ctx context.Context, name, sql string) (*Statement, error) {
+	if name == "" {
+		return nil, fmt.Errorf("prepared statement name must not be empty")
+	}
+
+	err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release()
+
+	sd, err := c.pgconn.Prepare(ctx, name, sql, nil)
+	if err != nil {
+		return nil, c.connError(err)
+	}
+
+	stmt := &Statement{Name: sd.Name, SQL: sd.SQL, ParamOIDs: sd.ParamOIDs, Fields: sd.Fields}
+	if c.preparedStatements == nil {
+		c.preparedStatements = make(map[string]*Statement)
+	}
+	c.preparedStatements[name] = stmt
+
+	return stmt, nil
+}
+
+// QueryPrepared executes the prepared statement name. It is otherwise the same as Query.
+//
+// Params must be of the types the server inferred for the statement. A param encoded in the binary format as a
+// different type is an error rather than being misinterpreted by the server. Params encoded in the text format, such
+// as strings, are converted by the server.
+func (c *Conn) QueryPrepared(ctx context.Context, name string, args []interface{}, results []interface{}, rowFunc func() error) (int64, error) {
+	err := c.acquire(ctx)
+	if err != nil {
+		return 0, c.handleError(err)
+	}
+	defer c.release()
+
+	stmt, err := c.preparedStatement(name)
+	if err != nil {
+		return 0, c.handleError(err)
+	}
+
+	return c.query(ctx, stmt.SQL, stmt, args, results, rowFunc)
+}
+
+// ExecPrepared executes the prepared statement name. It is otherwise the same as Exec. See QueryPrepared for the
+// requirements of the params.
+func (c *Conn) ExecPrepared(ctx context.Context, name string, args ...interface{}) (int64, error) {
+	err := c.acquire(ctx)
+	if err != nil {
+		return 0, c.handleError(err)
+	}
+	defer c.release()
+
+	stmt, err := c.preparedStatement(name)
+	if err != nil {
+		return 0, c.handleError(err)
+	}
+
+	return c.exec(ctx, stmt.SQL, stmt, args...)
+}
+
+// Deallocate deallocates the prepared statement name.
+func (c *Conn) Deallocate(ctx context.Context, name string) error {
+	err := c.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.release()
+
+	if _, err := c.preparedStatement(name); err != nil {
+		return err
+	}
+
+	err = c.pgconn.Exec(ctx, "deallocate "+Identifier(name).Sanitize()).Close()
+	if err != nil {
+		return c.connError(err)
+	}
+
+	delete(c.preparedStatements, name)
+	return nil
+}
+
+func (c *Conn) preparedStatement(name string) (*Statement, error) {
+	stmt, ok := c.preparedStatements[name]
+	if !ok {
+		return nil, fmt.Errorf("prepared statement %q does not exist", name)
+	}
+	return stmt, nil
+}
+
+// checkStatementParams checks that the params prepared from args can be sent to stmt.
+func (c *Conn) checkStatementParams(stmt *Statement, args []interface{}) error {
+	if len(c.paramValues) != len(stmt.ParamOIDs) {
+		return fmt.Errorf("prepared statement %s requires %d args, got %d", stmt.Name, len(stmt.ParamOIDs), len(c.paramValues))
+	}
+
+	for i, oid := range c.paramOIDs {
+		if c.paramValues[i] == nil || c.paramFormats[i] != binaryFormat || oid == 0 || oid == stmt.ParamOIDs[i] {
+			continue
+		}
+		if textIsBinaryFormat(oid) && textIsBinaryFormat(stmt.ParamOIDs[i]) {
+			continue
+		}
+		return fmt.Errorf("args[%d] (%T) is type oid %d but prepared statement %s requires type oid %d", i, args[i], oid, stmt.Name, stmt.ParamOIDs[i])
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnPrepare(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	stmt, err := db.Prepare(context.Background(), "add", "select $1::int8 + $2::int8 as sum")
+	require.NoError(t, err)
+	require.Equal(t, "add", stmt.Name)
+	require.Equal(t, []uint32{20, 20}, stmt.ParamOIDs)
+	require.Len(t, stmt.Fields, 1)
+	require.Equal(t, "sum", string(stmt.Fields[0].Name))
+
+	var sum int64
+	for i := int64(0); i < 3; i++ {
+		rowCount, err := db.QueryPrepared(context.Background(), "add", []interface{}{i, "10"}, []interface{}{&sum}, func() error { return nil })
+		require.NoError(t, err)
+		require.EqualValues(t, 1, rowCount)
+		require.EqualValues(t, i+10, sum)
+	}
+
+	_, err = db.QueryPrepared(context.Background(), "add", []interface{}{int32(1), int64(2)}, []interface{}{&sum}, func() error { return nil })
+	require.EqualError(t, err, "args[0] (int32) is type oid 23 but prepared statement add requires type oid 20")
+
+	_, err = db.QueryPrepared(context.Background(), "add", []interface{}{int64(1)}, []interface{}{&sum}, func() error { return nil })
+	require.EqualError(t, err, "prepared statement add requires 2 args, got 1")
+
+	_, err = db.Exec(context.Background(), "create temporary table t (n int8)")
+	require.NoError(t, err)
+	_, err = db.Prepare(context.Background(), "insert t", "insert into t (n) select generate_series(1, $1::int8)")
+	require.NoError(t, err)
+	rowsAffected, err := db.ExecPrepared(context.Background(), "insert t", int64(3))
+	require.NoError(t, err)
+	require.EqualValues(t, 3, rowsAffected)
+
+	require.NoError(t, db.Deallocate(context.Background(), "add"))
+	_, err = db.QueryPrepared(context.Background(), "add", []interface{}{int64(1), int64(2)}, []interface{}{&sum}, func() error { return nil })
+	require.EqualError(t, err, `prepared statement "add" does not exist`)
+	require.EqualError(t, db.Deallocate(context.Background(), "add"), `prepared statement "add" does not exist`)
+
+	_, err = db.Prepare(context.Background(), "", "select 1")
+	require.EqualError(t, err, "prepared statement name must not be empty")
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,176 @@
+package goldilocks
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/jackc/pgproto3/v2"
+)
+
+const (
+	charOID         = 18
+	cidrOID         = 650
+	timestampOID    = 1114
+	bpcharArrayOID  = 1014
+	varcharArrayOID = 1015
+)
+
+// resultTypeNames are the names of the types in ResultTypeError messages.
+var resultTypeNames = map[uint32]string{
+	boolOID:             "bool",
+	byteaOID:            "bytea",
+	charOID:             `"char"`,
+	nameOID:             "name",
+	int8OID:             "int8",
+	int2OID:             "int2",
+	int4OID:             "int4",
+	textOID:             "text",
+	oidOID:              "oid",
+	jsonOID:             "json",
+	pointOID:            "point",
+	pathOID:             "path",
+	boxOID:              "box",
+	polygonOID:          "polygon",
+	lineOID:             "line",
+	cidrOID:             "cidr",
+	float4OID:           "float4",
+	float8OID:           "float8",
+	circleOID:           "circle",
+	macaddr8OID:         "macaddr8",
+	moneyOID:            "money",
+	macaddrOID:          "macaddr",
+	inetOID:             "inet",
+	boolArrayOID:        "bool[]",
+	int2ArrayOID:        "int2[]",
+	int4ArrayOID:        "int4[]",
+	textArrayOID:        "text[]",
+	bpcharArrayOID:      "bpchar[]",
+	varcharArrayOID:     "varchar[]",
+	int8ArrayOID:        "int8[]",
+	float4ArrayOID:      "float4[]",
+	float8ArrayOID:      "float8[]",
+	bpcharOID:           "bpchar",
+	varcharOID:          "varchar",
+	dateOID:             "date",
+	timestampOID:        "timestamp",
+	dateArrayOID:        "date[]",
+	timestamptzOID:      "timestamptz",
+	timestamptzArrayOID: "timestamptz[]",
+	intervalOID:         "interval",
+	numericOID:          "numeric",
+	jsonbOID:            "jsonb",
+}
+
+// ResultTypeError is returned by Query when ConnConfig.StrictResultTypes is set and a column is of a type its result
+// cannot decode.
+type ResultTypeError struct {
+	Column      int // the position of the column starting at 1
+	Name        string
+	OID         uint32
+	Destination interface{}
+}
+
+func (e *ResultTypeError) Error() string {
+	typeName, ok := resultTypeNames[e.OID]
+	if !ok {
+		typeName = "OID " + strconv.FormatUint(uint64(e.OID), 10)
+	}
+	return fmt.Sprintf("column %d %q is %s but destination is %T", e.Column, e.Name, typeName, e.Destination)
+}
+
+// checkResultTypes checks that each result in the binary format can decode the type of its column in fields. Results
+// that do not have a known set of types are not checked. results are the results the decoders were prepared from.
+func (c *Conn) checkResultTypes(fields []pgproto3.FieldDescription, results []interface{}) error {
+	for i, rd := range c.resultDecoders {
+		if i >= len(fields) || c.resultFormats[i] != binaryFormat {
+			continue
+		}
+
+		oids := resultOIDs(rd)
+		if oids == nil {
+			continue
+		}
+
+		// The binary format of text types is their raw bytes.
+		_, isBytea := rd.(*bytea)
+		found := isBytea && textIsBinaryFormat(fields[i].DataTypeOID)
+		for _, oid := range oids {
+			if oid == fields[i].DataTypeOID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &ResultTypeError{Column: i + 1, Name: string(fields[i].Name), OID: fields[i].DataTypeOID, Destination: results[i]}
+		}
+	}
+
+	return nil
+}
+
+// resultOIDs returns the types of the columns rd can decode in the binary format. It returns nil if rd is not a builtin
+// decoder of known types.
+func resultOIDs(rd ResultDecoder) []uint32 {
+	switch rd := rd.(type) {
+	case *notNullInt16, *NullInt16:
+		return []uint32{int2OID}
+	case *notNullInt32, *NullInt32:
+		return []uint32{int4OID}
+	case *notNullInt64, *NullInt64:
+		return []uint32{int8OID}
+	case *notNullInt, *notNullUint, *notNullUint64:
+		return []uint32{int2OID, int4OID, int8OID}
+	case *notNullFloat32, *NullFloat32:
+		return []uint32{float4OID}
+	case *notNullFloat64, *NullFloat64:
+		return []uint32{float8OID}
+	case *notNullBool, *NullBool:
+		return []uint32{boolOID}
+	case *notNullChar:
+		return []uint32{charOID}
+	case *Date, *NullDate:
+		return []uint32{dateOID}
+	case *notNullTime, *NullTime:
+		return []uint32{timestamptzOID, timestampOID}
+	case *Interval, *NullInterval:
+		return []uint32{intervalOID}
+	case *notNullInet, *NullInet:
+		return []uint32{inetOID, cidrOID}
+	case *notNullHardwareAddr, *NullHardwareAddr:
+		return []uint32{macaddrOID, macaddr8OID}
+	case *BigNumeric, *NullBigNumeric, decimalComposer:
+		return []uint32{numericOID}
+	case *Money, *NullMoney:
+		return []uint32{moneyOID}
+	case *OID, *NullOID:
+		return []uint32{oidOID}
+	case *bytea:
+		// The binary format of json is its raw bytes. Text types are checked by checkResultTypes.
+		return []uint32{byteaOID, jsonOID}
+	case *jsonRawMessage:
+		return []uint32{jsonOID, jsonbOID}
+	case *int32Array:
+		return []uint32{int4ArrayOID}
+	case *float64Array:
+		return []uint32{float8ArrayOID}
+	case *Point, *NullPoint:
+		return []uint32{pointOID}
+	case *Line, *NullLine:
+		return []uint32{lineOID}
+	case *Box, *NullBox:
+		return []uint32{boxOID}
+	case *Path, *NullPath:
+		return []uint32{pathOID}
+	case *Polygon, *NullPolygon:
+		return []uint32{polygonOID}
+	case *Circle, *NullCircle:
+		return []uint32{circleOID}
+	case ptrResult:
+		return resultOIDs(rd.inner(reflect.New(rd.ptr.Type().Elem())))
+	case textPtrResult:
+		return resultOIDs(rd.inner(reflect.New(rd.ptr.Type().Elem())))
+	default:
+		return nil
+	}
+}
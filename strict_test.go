@@ -0,0 +1,64 @@
+package goldilocks_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnConfigStrictResultTypes(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{StrictResultTypes: true})
+
+	var id int32
+	var n int
+	var name string
+	var createdAt time.Time
+	var deletedAt *time.Time
+	_, err = db.Query(
+		context.Background(),
+		"select 1::int4, 2::int8, 'foo', now(), null::timestamptz",
+		nil,
+		[]interface{}{&id, &n, &name, &createdAt, &deletedAt},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+
+	var createdAtUnix int64
+	rowFuncCalled := false
+	_, err = db.Query(
+		context.Background(),
+		"select 1::int4, 'foo', now() as created_at",
+		nil,
+		[]interface{}{&id, &name, &createdAtUnix},
+		func() error { rowFuncCalled = true; return nil },
+	)
+	require.EqualError(t, err, `column 3 "created_at" is timestamptz but destination is *int64`)
+	var typeErr *goldilocks.ResultTypeError
+	require.True(t, errors.As(err, &typeErr))
+	require.Equal(t, 3, typeErr.Column)
+	require.False(t, rowFuncCalled)
+
+	_, err = db.Query(context.Background(), "select 1::int8", nil, []interface{}{&id}, func() error { return nil })
+	require.EqualError(t, err, `column 1 "int8" is int8 but destination is *int32`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestResultTypeErrorUnknownType(t *testing.T) {
+	t.Parallel()
+
+	var n int64
+	err := &goldilocks.ResultTypeError{Column: 1, Name: "n", OID: 99999, Destination: &n}
+	require.EqualError(t, err, `column 1 "n" is OID 99999 but destination is *int64`)
+}
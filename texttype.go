@@ -0,0 +1,53 @@
+package goldilocks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// textTypeOIDs holds the oids registered with RegisterTextType.
+var textTypeOIDs sync.Map
+
+// RegisterTextType registers oid as a type whose binary format is the same as its text format, such as the citext
+// extension type. Values of the type can then be decoded into string destinations within composites, records, and
+// arrays. Top level results do not need registration as string destinations read them in the text format.
+//
+// Registrations are global. Extension types are assigned oids when the extension is created so the oid may differ
+// between databases. Use LoadTextType to look it up.
+func RegisterTextType(oid uint32) {
+	textTypeOIDs.Store(oid, struct{}{})
+}
+
+// LoadTextType looks up the oid of the string type name in db, registers it with RegisterTextType, and returns it.
+func LoadTextType(ctx context.Context, db StdDB, name string) (uint32, error) {
+	var oid int64
+	var category string
+	rowCount, err := db.Query(
+		ctx,
+		"select oid::int8, typcategory::text from pg_type where oid = $1::regtype",
+		[]interface{}{name},
+		[]interface{}{&oid, &category},
+		func() error { return nil },
+	)
+	if err != nil {
+		return 0, err
+	}
+	if rowCount == 0 || category != "S" {
+		return 0, fmt.Errorf("%s is not a string type", name)
+	}
+
+	RegisterTextType(uint32(oid))
+	return uint32(oid), nil
+}
+
+// textIsBinaryFormat reports whether the binary format of type oid is the same as its text format.
+func textIsBinaryFormat(oid uint32) bool {
+	switch oid {
+	case nameOID, textOID, bpcharOID, varcharOID:
+		return true
+	}
+
+	_, ok := textTypeOIDs.Load(oid)
+	return ok
+}
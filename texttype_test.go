@@ -0,0 +1,80 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTextTypeCitext(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var citextExists bool
+	_, err = db.Query(
+		context.Background(),
+		"select exists(select 1 from pg_type where typname = 'citext')",
+		nil,
+		[]interface{}{&citextExists},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	if !citextExists {
+		t.Skip("citext extension is not installed")
+	}
+
+	oid, err := goldilocks.LoadTextType(context.Background(), db, "citext")
+	require.NoError(t, err)
+	require.NotZero(t, oid)
+
+	var s string
+	var ss []string
+	var a, b string
+	_, err = db.Query(
+		context.Background(),
+		"select 'Hello'::citext, array['A', 'b']::citext[], row('X'::citext, 'y'::citext)",
+		nil,
+		[]interface{}{&s, &ss, goldilocks.RecordResult(&a, &b)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, "Hello", s)
+	require.Equal(t, []string{"A", "b"}, ss)
+	require.Equal(t, "X", a)
+	require.Equal(t, "y", b)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestLoadTextType(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = goldilocks.LoadTextType(context.Background(), db, "int4")
+	require.EqualError(t, err, "int4 is not a string type")
+
+	var ss []string
+	_, err = db.Query(
+		context.Background(),
+		"select array['a', 'b']::varchar[]",
+		nil,
+		[]interface{}{&ss},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, ss)
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,95 @@
+package goldilocks
+
+import (
+	"context"
+	"time"
+)
+
+// TraceOp is an operation traced by a Tracer.
+type TraceOp int
+
+const (
+	TraceQuery TraceOp = iota + 1
+	TraceExec
+	TraceBegin
+	TraceCommit
+	TraceRollback
+	TraceCopyFrom
+	TraceAcquire
+	TraceRelease
+)
+
+func (op TraceOp) String() string {
+	switch op {
+	case TraceQuery:
+		return "query"
+	case TraceExec:
+		return "exec"
+	case TraceBegin:
+		return "begin"
+	case TraceCommit:
+		return "commit"
+	case TraceRollback:
+		return "rollback"
+	case TraceCopyFrom:
+		return "copy from"
+	case TraceAcquire:
+		return "acquire"
+	case TraceRelease:
+		return "release"
+	default:
+		return "invalid"
+	}
+}
+
+// TraceData describes a traced operation. Duration, RowCount, and Err are only set when the operation has finished.
+type TraceData struct {
+	Op       TraceOp
+	Name     string // the name of a query or exec set with WithQueryName, such as by QueryRegistry
+	SQL      string // the SQL of a query, exec, or copy
+	ArgCount int    // the number of args of a query or exec
+
+	Duration time.Duration
+	RowCount int64 // the rows returned by a query or affected by an exec or copy
+	Err      error
+}
+
+// Tracer is called around the operations of a Conn or Pool. This allows integrating with tracing and APM systems.
+// Query and Exec include queries and execs in transactions and of prepared statements. QueryChunks is traced as a
+// query. Acquire and Release are only traced by a Pool. A Tracer must be safe for concurrent use if it is shared by
+// multiple Conns, such as those of a Pool.
+type Tracer interface {
+	// Before is called before an operation starts. The context it returns is used for the operation and passed to
+	// After. This allows a Tracer to start a span and store it in the context.
+	Before(ctx context.Context, data TraceData) context.Context
+
+	// After is called when the operation has finished with ctx as returned by Before.
+	After(ctx context.Context, data TraceData)
+}
+
+// startTrace calls tracer.Before with data and returns the context it returns and a function to call with the row count
+// and error when the operation has finished. If tracer is nil it returns ctx and a function that does nothing.
+func startTrace(ctx context.Context, tracer Tracer, data TraceData) (context.Context, func(rowCount int64, err error)) {
+	if tracer == nil {
+		return ctx, endNoTrace
+	}
+
+	start := time.Now()
+	ctx = tracer.Before(ctx, data)
+	return ctx, func(rowCount int64, err error) {
+		data.Duration = time.Since(start)
+		data.RowCount = rowCount
+		data.Err = err
+		tracer.After(ctx, data)
+	}
+}
+
+func endNoTrace(rowCount int64, err error) {}
+
+// startTrace starts tracing an operation of c. See startTrace. data is labeled with the query name of ctx.
+func (c *Conn) startTrace(ctx context.Context, data TraceData) (context.Context, func(rowCount int64, err error)) {
+	if c.config.Tracer != nil {
+		data.Name = queryName(ctx)
+	}
+	return startTrace(ctx, c.config.Tracer, data)
+}
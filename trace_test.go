@@ -0,0 +1,136 @@
+package goldilocks_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+type traceCtxKey struct{}
+
+type testTracer struct {
+	mux    sync.Mutex
+	before []goldilocks.TraceData
+	after  []goldilocks.TraceData
+}
+
+func (tt *testTracer) Before(ctx context.Context, data goldilocks.TraceData) context.Context {
+	tt.mux.Lock()
+	defer tt.mux.Unlock()
+	tt.before = append(tt.before, data)
+	return context.WithValue(ctx, traceCtxKey{}, len(tt.before))
+}
+
+func (tt *testTracer) After(ctx context.Context, data goldilocks.TraceData) {
+	tt.mux.Lock()
+	defer tt.mux.Unlock()
+	// The context returned by Before is passed to After.
+	if ctx.Value(traceCtxKey{}) == nil {
+		panic("missing trace context")
+	}
+	tt.after = append(tt.after, data)
+}
+
+func (tt *testTracer) ops() []goldilocks.TraceOp {
+	tt.mux.Lock()
+	defer tt.mux.Unlock()
+	ops := make([]goldilocks.TraceOp, len(tt.after))
+	for i, data := range tt.after {
+		ops[i] = data.Op
+	}
+	return ops
+}
+
+func TestConnConfigTracer(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+
+	tracer := &testTracer{}
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{Tracer: tracer})
+
+	var n int32
+	_, err = db.Query(context.Background(), "select generate_series(1, $1)", []interface{}{int32(3)}, []interface{}{&n}, func() error { return nil })
+	require.NoError(t, err)
+
+	require.Len(t, tracer.before, 1)
+	require.Equal(t, goldilocks.TraceData{Op: goldilocks.TraceQuery, SQL: "select generate_series(1, $1)", ArgCount: 1}, tracer.before[0])
+	require.Equal(t, goldilocks.TraceQuery, tracer.after[0].Op)
+	require.EqualValues(t, 3, tracer.after[0].RowCount)
+	require.True(t, tracer.after[0].Duration > 0)
+	require.NoError(t, tracer.after[0].Err)
+
+	_, err = db.Exec(context.Background(), "create temporary table widgets (id int4)")
+	require.NoError(t, err)
+
+	err = db.Begin(context.Background(), func(tx goldilocks.StdDB) error {
+		_, err := tx.Exec(context.Background(), "insert into widgets values (1)")
+		return err
+	})
+	require.NoError(t, err)
+
+	stop := errors.New("stop")
+	err = db.Begin(context.Background(), func(tx goldilocks.StdDB) error {
+		return stop
+	})
+	require.Equal(t, stop, err)
+
+	rowCount, err := db.CopyFrom(context.Background(), goldilocks.Identifier("widgets"), []string{"id"}, goldilocks.CopyFromRows([][]interface{}{{int32(2)}, {int32(3)}}))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, rowCount)
+
+	_, err = db.Exec(context.Background(), "select 1 / 0")
+	require.Error(t, err)
+
+	require.Equal(t, []goldilocks.TraceOp{
+		goldilocks.TraceQuery,
+		goldilocks.TraceExec,
+		goldilocks.TraceBegin,
+		goldilocks.TraceExec,
+		goldilocks.TraceCommit,
+		goldilocks.TraceBegin,
+		goldilocks.TraceRollback,
+		goldilocks.TraceCopyFrom,
+		goldilocks.TraceExec,
+	}, tracer.ops())
+
+	copyData := tracer.after[7]
+	require.Equal(t, `copy "widgets" ("id") from stdin binary`, copyData.SQL)
+	require.EqualValues(t, 2, copyData.RowCount)
+
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(tracer.after[8].Err, &pgErr))
+	require.Equal(t, "22012", pgErr.Code)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestPoolTracer(t *testing.T) {
+	t.Parallel()
+
+	config, err := goldilocks.ParsePoolConfig(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	tracer := &testTracer{}
+	config.Tracer = tracer
+
+	db, err := goldilocks.NewPoolConfig(config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(context.Background(), "select 1")
+	require.NoError(t, err)
+
+	require.Equal(t, []goldilocks.TraceOp{
+		goldilocks.TraceAcquire,
+		goldilocks.TraceExec,
+		goldilocks.TraceRelease,
+	}, tracer.ops())
+}
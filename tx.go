@@ -0,0 +1,146 @@
+package goldilocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+)
+
+// ErrTxClosed is returned when a Tx is used after it has been committed or rolled back.
+var ErrTxClosed = errors.New("tx is closed")
+
+// Tx is a transaction started by BeginTx. It is an alternative to Begin for transactions whose lifetime does not fit
+// within a single function. A Tx must be ended with Commit or Rollback. Deferring Rollback ensures the transaction is
+// ended on every path. Calling Rollback after Commit does nothing and returns ErrTxClosed.
+//
+// A Tx started on a serialized Conn or a Pool holds its connection until it ends.
+type Tx struct {
+	conn   *Conn
+	end    func()
+	closed bool
+}
+
+// BeginTx starts a transaction and returns a Tx that executes in it.
+func (c *Conn) BeginTx(ctx context.Context) (*Tx, error) {
+	err := c.acquire(ctx)
+	if err != nil {
+		return nil, c.handleError(err)
+	}
+
+	tx, err := c.beginTx(ctx)
+	if err != nil {
+		c.release()
+		return nil, err
+	}
+	tx.end = c.release
+
+	return tx, nil
+}
+
+// beginTx starts a transaction on a Conn that is already held.
+func (c *Conn) beginTx(ctx context.Context) (*Tx, error) {
+	ctx, endTrace := c.startTrace(ctx, TraceData{Op: TraceBegin})
+	err := c.pgconn.Exec(ctx, "begin").Close()
+	endTrace(0, err)
+	if err != nil {
+		return nil, c.handleError(c.connError(err))
+	}
+	return &Tx{conn: c}, nil
+}
+
+func (tx *Tx) Query(ctx context.Context, sql string, args []interface{}, results []interface{}, rowFunc func() error) (int64, error) {
+	if tx.closed {
+		return 0, ErrTxClosed
+	}
+	return tx.conn.query(ctx, sql, nil, args, results, rowFunc)
+}
+
+func (tx *Tx) Exec(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	if tx.closed {
+		return 0, ErrTxClosed
+	}
+	return tx.conn.exec(ctx, sql, nil, args...)
+}
+
+func (tx *Tx) Begin(ctx context.Context, f func(StdDB) error) error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+	return tx.conn.begin(ctx, f)
+}
+
+// Commit commits the transaction. It returns the same errors as Begin other than those returned by f.
+func (tx *Tx) Commit(ctx context.Context) error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+	defer tx.close()
+
+	ctx, endTrace := tx.conn.startTrace(ctx, TraceData{Op: TraceCommit})
+	err := tx.commit(ctx)
+	endTrace(0, err)
+	return err
+}
+
+// commit commits the transaction. The Tx is not closed.
+func (tx *Tx) commit(ctx context.Context) error {
+	c := tx.conn
+	switch txStatus := c.pgconn.TxStatus(); txStatus {
+	case 'T':
+		err := c.pgconn.Exec(ctx, "commit").Close()
+		if err != nil {
+			// A server error means the commit failed. An error that is safe to retry means the commit was never sent. Any
+			// other error could have happened after the server committed. CockroachDB also reports a server error when it
+			// cannot determine whether a commit succeeded.
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				if pgErr.Code == pgerrcode.StatementCompletionUnknown && c.Backend() == BackendCockroachDB {
+					err = &CommitUnknownError{Err: err}
+				}
+			} else if !pgconn.SafeToRetry(err) {
+				err = &CommitUnknownError{Err: err}
+			}
+			return c.handleError(c.connError(err))
+		}
+		return nil
+	case 'E':
+		tx.rollback(ctx)
+		return c.handleError(ErrTxFailed)
+	case 'I':
+		return c.handleError(fmt.Errorf("not in transaction"))
+	default:
+		return c.handleError(fmt.Errorf("impossible txStatus: %v", txStatus))
+	}
+}
+
+// Rollback rolls back the transaction. If the rollback fails the connection is closed because its state is unknown.
+func (tx *Tx) Rollback(ctx context.Context) error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+	defer tx.close()
+
+	return tx.conn.handleError(tx.rollback(ctx))
+}
+
+// rollback rolls back the transaction, closing the connection if that fails.
+func (tx *Tx) rollback(ctx context.Context) error {
+	ctx, endTrace := tx.conn.startTrace(ctx, TraceData{Op: TraceRollback})
+	err := tx.conn.pgconn.Exec(ctx, "rollback").Close()
+	endTrace(0, err)
+	if err != nil {
+		tx.conn.pgconn.Close(context.Background())
+		return tx.conn.connError(err)
+	}
+	return nil
+}
+
+func (tx *Tx) close() {
+	tx.closed = true
+	if tx.end != nil {
+		tx.end()
+	}
+}
@@ -0,0 +1,111 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCommitAndRollback(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "create temporary table goldilocks (a text)")
+	require.NoError(t, err)
+
+	tx, err := db.BeginTx(context.Background())
+	require.NoError(t, err)
+	_, err = tx.Exec(context.Background(), "insert into goldilocks (a) values ($1)", "committed")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit(context.Background()))
+	require.Equal(t, goldilocks.ErrTxClosed, tx.Rollback(context.Background()))
+	_, err = tx.Exec(context.Background(), "select 1")
+	require.Equal(t, goldilocks.ErrTxClosed, err)
+
+	tx, err = db.BeginTx(context.Background())
+	require.NoError(t, err)
+	_, err = tx.Exec(context.Background(), "insert into goldilocks (a) values ($1)", "rolled back")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback(context.Background()))
+
+	var values []string
+	var a string
+	_, err = db.Query(context.Background(), "select a from goldilocks", nil, []interface{}{&a}, func() error {
+		values = append(values, a)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"committed"}, values)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestTxCommitFailedTx(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	tx, err := db.BeginTx(context.Background())
+	require.NoError(t, err)
+	_, err = tx.Exec(context.Background(), "select 1/0")
+	require.Error(t, err)
+	require.Equal(t, goldilocks.ErrTxFailed, tx.Commit(context.Background()))
+	require.EqualValues(t, 'I', pgConn.TxStatus())
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestTxSerializedConnHeldUntilEnd(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewSerializedConn(pgConn)
+
+	tx, err := db.BeginTx(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = db.Exec(ctx, "select 1")
+	require.Equal(t, context.Canceled, err)
+
+	_, err = tx.Exec(context.Background(), "select 1")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit(context.Background()))
+
+	_, err = db.Exec(context.Background(), "select 1")
+	require.NoError(t, err)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestPoolBeginTx(t *testing.T) {
+	t.Parallel()
+
+	db, err := goldilocks.NewPool(os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	tx, err := db.BeginTx(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback(context.Background())
+
+	var n int32
+	_, err = tx.Query(context.Background(), "select 1", nil, []interface{}{&n}, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	require.NoError(t, tx.Commit(context.Background()))
+}
@@ -0,0 +1,102 @@
+package goldilocks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// TypeMap maps the names of PostgreSQL types to their oids in a database. It is intended for extension and user
+// defined types such as hstore or PostGIS geometry whose oids are assigned when they are created. A TypeMap is safe
+// for concurrent use and can be shared by all connections to the same database.
+//
+// Builtin types are not in a TypeMap. Their encoding and decoding is determined by Go types.
+type TypeMap struct {
+	mux   sync.RWMutex
+	oids  map[string]uint32
+	names map[uint32]string
+}
+
+// NewTypeMap returns an empty TypeMap.
+func NewTypeMap() *TypeMap {
+	return &TypeMap{
+		oids:  make(map[string]uint32),
+		names: make(map[uint32]string),
+	}
+}
+
+// LoadTypes loads the oids of the types names from db. names may be schema qualified. Types in the string category,
+// such as citext, are also registered with RegisterTextType.
+func (m *TypeMap) LoadTypes(ctx context.Context, db StdDB, names ...string) error {
+	for _, name := range names {
+		var oid int64
+		var category string
+		_, err := db.Query(
+			ctx,
+			"select oid::int8, typcategory::text from pg_type where oid = $1::regtype",
+			[]interface{}{name},
+			[]interface{}{&oid, &category},
+			func() error { return nil },
+		)
+		if err != nil {
+			return fmt.Errorf("cannot load type %s: %w", name, err)
+		}
+
+		if category == "S" {
+			RegisterTextType(uint32(oid))
+		}
+
+		m.mux.Lock()
+		m.oids[name] = uint32(oid)
+		m.names[uint32(oid)] = name
+		m.mux.Unlock()
+	}
+
+	return nil
+}
+
+// OID returns the oid of the loaded type name.
+func (m *TypeMap) OID(name string) (uint32, bool) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	oid, ok := m.oids[name]
+	return oid, ok
+}
+
+// Name returns the name of the loaded type with oid.
+func (m *TypeMap) Name(oid uint32) (string, bool) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	name, ok := m.names[oid]
+	return name, ok
+}
+
+// Param returns a query param that encodes value as usual but sends it as the loaded type name. This allows sending
+// the text format of an extension type from a string such as a hstore or a PostGIS geometry in WKT. Encoding fails if
+// name has not been loaded.
+func (m *TypeMap) Param(name string, value interface{}) ParamEncoder {
+	return typeMapParam{m: m, name: name, value: value}
+}
+
+type typeMapParam struct {
+	m     *TypeMap
+	name  string
+	value interface{}
+}
+
+func (p typeMapParam) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	oid, ok := p.m.OID(p.name)
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("type %s is not loaded", p.name)
+	}
+
+	value, _, format, err := encodeParam(buf, p.value)
+	if err == errUnsupportedType {
+		return nil, 0, 0, fmt.Errorf("%s param is unsupported type %T", p.name, p.value)
+	}
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return value, oid, format, nil
+}
@@ -0,0 +1,62 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeMap(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	_, err = db.Exec(context.Background(), "create domain pg_temp.short_text as text check (length(value) < 10)")
+	require.NoError(t, err)
+
+	typeMap := goldilocks.NewTypeMap()
+	err = typeMap.LoadTypes(context.Background(), db, "pg_temp.short_text", "int4")
+	require.NoError(t, err)
+
+	oid, ok := typeMap.OID("int4")
+	require.True(t, ok)
+	require.EqualValues(t, 23, oid)
+
+	oid, ok = typeMap.OID("pg_temp.short_text")
+	require.True(t, ok)
+	name, ok := typeMap.Name(oid)
+	require.True(t, ok)
+	require.Equal(t, "pg_temp.short_text", name)
+
+	_, ok = typeMap.OID("missing")
+	require.False(t, ok)
+
+	var typeName string
+	_, err = db.Query(
+		context.Background(),
+		"select pg_typeof($1)::text",
+		[]interface{}{typeMap.Param("pg_temp.short_text", "abc")},
+		[]interface{}{&typeName},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, "short_text", typeName)
+
+	_, err = db.Exec(context.Background(), "select $1", typeMap.Param("pg_temp.short_text", "much too long"))
+	require.Error(t, err)
+
+	_, err = db.Exec(context.Background(), "select $1", typeMap.Param("missing", "abc"))
+	require.EqualError(t, err, "cannot encode args[0] (goldilocks.typeMapParam): type missing is not loaded")
+
+	err = typeMap.LoadTypes(context.Background(), db, "missing")
+	require.Error(t, err)
+
+	ensurePgConnValid(t, pgConn)
+}
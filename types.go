@@ -1,10 +1,19 @@
 package goldilocks
 
 import (
+	"context"
+	"database/sql"
 	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgio"
@@ -17,15 +26,37 @@ const (
 
 // PostgreSQL oids for builtin types
 const (
-	boolOID        = 16
-	int8OID        = 20
-	int2OID        = 21
-	int4OID        = 23
-	textOID        = 25
-	float4OID      = 700
-	float8OID      = 701
-	dateOID        = 1082
-	timestamptzOID = 1184
+	boolOID             = 16
+	byteaOID            = 17
+	nameOID             = 19
+	int8OID             = 20
+	int2OID             = 21
+	int4OID             = 23
+	textOID             = 25
+	oidOID              = 26
+	jsonOID             = 114
+	float4OID           = 700
+	float8OID           = 701
+	macaddr8OID         = 774
+	moneyOID            = 790
+	macaddrOID          = 829
+	inetOID             = 869
+	boolArrayOID        = 1000
+	int2ArrayOID        = 1005
+	int4ArrayOID        = 1007
+	textArrayOID        = 1009
+	int8ArrayOID        = 1016
+	float4ArrayOID      = 1021
+	float8ArrayOID      = 1022
+	bpcharOID           = 1042
+	varcharOID          = 1043
+	dateOID             = 1082
+	dateArrayOID        = 1182
+	timestamptzOID      = 1184
+	timestamptzArrayOID = 1185
+	intervalOID         = 1186
+	numericOID          = 1700
+	jsonbOID            = 3802
 )
 
 type nilSkip struct{}
@@ -38,16 +69,19 @@ func (nilSkip) DecodeResult(buf []byte) error {
 	return nil
 }
 
+// NullString is a string that may be NULL. As a result, NullString and *string receive the text format of a column of
+// any type, including types goldilocks has no decoder for. This makes them usable for ad hoc queries against exotic
+// types.
 type NullString struct {
 	Value string
 	Valid bool
 }
 
-func (n NullString) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (n NullString) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	if n.Valid {
 		return writeString(buf, n.Value)
 	}
-	return nil, 0, textFormat
+	return nil, 0, textFormat, nil
 }
 
 func (*NullString) ResultFormat() int16 {
@@ -91,9 +125,9 @@ func readNotNullString(buf []byte, dst *string) error {
 	return nil
 }
 
-func writeString(buf []byte, src string) ([]byte, uint32, int16) {
+func writeString(buf []byte, src string) ([]byte, uint32, int16, error) {
 	buf = append(buf, src...)
-	return buf, 0, textFormat
+	return buf, 0, textFormat, nil
 }
 
 type NullInt16 struct {
@@ -101,11 +135,11 @@ type NullInt16 struct {
 	Valid bool
 }
 
-func (n NullInt16) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (n NullInt16) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	if n.Valid {
 		return writeInt16(buf, n.Value)
 	}
-	return nil, 0, binaryFormat
+	return nil, 0, binaryFormat, nil
 }
 
 func (*NullInt16) ResultFormat() int16 {
@@ -122,6 +156,16 @@ func (n *NullInt16) DecodeResult(buf []byte) error {
 	return readNotNullInt16(buf, &n.Value)
 }
 
+func (n *NullInt16) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		*n = NullInt16{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullInt16Text(buf, &n.Value)
+}
+
 type notNullInt16 int16
 
 func (*notNullInt16) ResultFormat() int16 {
@@ -135,6 +179,13 @@ func (nn *notNullInt16) DecodeResult(buf []byte) error {
 	return readNotNullInt16(buf, (*int16)(nn))
 }
 
+func (nn *notNullInt16) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to int16")
+	}
+	return readNotNullInt16Text(buf, (*int16)(nn))
+}
+
 func readInt16(dst *int16) (int16, valueReaderFunc) {
 	return binaryFormat, func(buf []byte) error {
 		if buf == nil {
@@ -152,8 +203,17 @@ func readNotNullInt16(buf []byte, dst *int16) error {
 	return nil
 }
 
-func writeInt16(buf []byte, src int16) ([]byte, uint32, int16) {
-	return pgio.AppendInt16(buf, src), int2OID, binaryFormat
+func readNotNullInt16Text(buf []byte, dst *int16) error {
+	n, err := strconv.ParseInt(string(buf), 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid int16: %w", err)
+	}
+	*dst = int16(n)
+	return nil
+}
+
+func writeInt16(buf []byte, src int16) ([]byte, uint32, int16, error) {
+	return pgio.AppendInt16(buf, src), int2OID, binaryFormat, nil
 }
 
 type NullInt32 struct {
@@ -161,11 +221,11 @@ type NullInt32 struct {
 	Valid bool
 }
 
-func (n NullInt32) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (n NullInt32) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	if n.Valid {
 		return writeInt32(buf, n.Value)
 	}
-	return nil, 0, binaryFormat
+	return nil, 0, binaryFormat, nil
 }
 
 func (*NullInt32) ResultFormat() int16 {
@@ -182,6 +242,16 @@ func (n *NullInt32) DecodeResult(buf []byte) error {
 	return readNotNullInt32(buf, &n.Value)
 }
 
+func (n *NullInt32) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		*n = NullInt32{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullInt32Text(buf, &n.Value)
+}
+
 type notNullInt32 int32
 
 func (*notNullInt32) ResultFormat() int16 {
@@ -195,6 +265,13 @@ func (nn *notNullInt32) DecodeResult(buf []byte) error {
 	return readNotNullInt32(buf, (*int32)(nn))
 }
 
+func (nn *notNullInt32) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to int32")
+	}
+	return readNotNullInt32Text(buf, (*int32)(nn))
+}
+
 func readInt32(dst *int32) (int16, valueReaderFunc) {
 	return binaryFormat, func(buf []byte) error {
 		if buf == nil {
@@ -212,8 +289,17 @@ func readNotNullInt32(buf []byte, dst *int32) error {
 	return nil
 }
 
-func writeInt32(buf []byte, src int32) ([]byte, uint32, int16) {
-	return pgio.AppendInt32(buf, src), int4OID, binaryFormat
+func readNotNullInt32Text(buf []byte, dst *int32) error {
+	n, err := strconv.ParseInt(string(buf), 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid int32: %w", err)
+	}
+	*dst = int32(n)
+	return nil
+}
+
+func writeInt32(buf []byte, src int32) ([]byte, uint32, int16, error) {
+	return pgio.AppendInt32(buf, src), int4OID, binaryFormat, nil
 }
 
 type NullInt64 struct {
@@ -221,11 +307,11 @@ type NullInt64 struct {
 	Valid bool
 }
 
-func (n NullInt64) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (n NullInt64) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	if n.Valid {
 		return writeInt64(buf, n.Value)
 	}
-	return nil, 0, binaryFormat
+	return nil, 0, binaryFormat, nil
 }
 
 func (*NullInt64) ResultFormat() int16 {
@@ -242,6 +328,16 @@ func (n *NullInt64) DecodeResult(buf []byte) error {
 	return readNotNullInt64(buf, &n.Value)
 }
 
+func (n *NullInt64) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		*n = NullInt64{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullInt64Text(buf, &n.Value)
+}
+
 type notNullInt64 int64
 
 func (*notNullInt64) ResultFormat() int16 {
@@ -255,6 +351,13 @@ func (nn *notNullInt64) DecodeResult(buf []byte) error {
 	return readNotNullInt64(buf, (*int64)(nn))
 }
 
+func (nn *notNullInt64) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to int64")
+	}
+	return readNotNullInt64Text(buf, (*int64)(nn))
+}
+
 func readInt64(dst *int64) (int16, valueReaderFunc) {
 	return binaryFormat, func(buf []byte) error {
 		if buf == nil {
@@ -272,8 +375,150 @@ func readNotNullInt64(buf []byte, dst *int64) error {
 	return nil
 }
 
-func writeInt64(buf []byte, src int64) ([]byte, uint32, int16) {
-	return pgio.AppendInt64(buf, src), int8OID, binaryFormat
+func readNotNullInt64Text(buf []byte, dst *int64) error {
+	n, err := strconv.ParseInt(string(buf), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid int64: %w", err)
+	}
+	*dst = n
+	return nil
+}
+
+func writeInt64(buf []byte, src int64) ([]byte, uint32, int16, error) {
+	return pgio.AppendInt64(buf, src), int8OID, binaryFormat, nil
+}
+
+// notNullInt decodes an int2, int4, or int8 into an int. The size of the integer is determined by the data length.
+type notNullInt int
+
+func (*notNullInt) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *notNullInt) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to int")
+	}
+	n, err := readNotNullAnyInt(buf, "int")
+	if err != nil {
+		return err
+	}
+	if int64(int(n)) != n {
+		return fmt.Errorf("%d is out of range for int", n)
+	}
+	*nn = notNullInt(n)
+	return nil
+}
+
+func (nn *notNullInt) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to int")
+	}
+	n, err := strconv.ParseInt(string(buf), 10, strconv.IntSize)
+	if err != nil {
+		return fmt.Errorf("invalid int: %w", err)
+	}
+	*nn = notNullInt(n)
+	return nil
+}
+
+// notNullUint decodes an int2, int4, or int8 into a uint. The size of the integer is determined by the data length.
+type notNullUint uint
+
+func (*notNullUint) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *notNullUint) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to uint")
+	}
+	n, err := readNotNullAnyInt(buf, "uint")
+	if err != nil {
+		return err
+	}
+	if n < 0 || uint64(uint(n)) != uint64(n) {
+		return fmt.Errorf("%d is out of range for uint", n)
+	}
+	*nn = notNullUint(n)
+	return nil
+}
+
+func (nn *notNullUint) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to uint")
+	}
+	n, err := strconv.ParseUint(string(buf), 10, strconv.IntSize)
+	if err != nil {
+		return fmt.Errorf("invalid uint: %w", err)
+	}
+	*nn = notNullUint(n)
+	return nil
+}
+
+// notNullUint64 decodes an int2, int4, or int8 into a uint64. The size of the integer is determined by the data
+// length. Negative values are an error.
+type notNullUint64 uint64
+
+func (*notNullUint64) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *notNullUint64) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to uint64")
+	}
+	n, err := readNotNullAnyInt(buf, "uint64")
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return fmt.Errorf("%d is out of range for uint64", n)
+	}
+	*nn = notNullUint64(n)
+	return nil
+}
+
+func (nn *notNullUint64) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to uint64")
+	}
+	n, err := strconv.ParseUint(string(buf), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid uint64: %w", err)
+	}
+	*nn = notNullUint64(n)
+	return nil
+}
+
+// readNotNullAnyInt reads an int2, int4, or int8 according to the length of buf. typeName is used in error messages.
+func readNotNullAnyInt(buf []byte, typeName string) (int64, error) {
+	switch len(buf) {
+	case 2:
+		return int64(int16(binary.BigEndian.Uint16(buf))), nil
+	case 4:
+		return int64(int32(binary.BigEndian.Uint32(buf))), nil
+	case 8:
+		return int64(binary.BigEndian.Uint64(buf)), nil
+	default:
+		return 0, fmt.Errorf("%s requires data length of 2, 4, or 8, got %d", typeName, len(buf))
+	}
+}
+
+func writeInt(buf []byte, src int) ([]byte, uint32, int16, error) {
+	return writeInt64(buf, int64(src))
+}
+
+func writeUint(buf []byte, src uint) ([]byte, uint32, int16, error) {
+	return writeUint64(buf, uint64(src))
+}
+
+// writeUint64 encodes src as an int8. It is an error if src is greater than the maximum value of an int8.
+func writeUint64(buf []byte, src uint64) ([]byte, uint32, int16, error) {
+	if src > math.MaxInt64 {
+		return nil, int8OID, 0, fmt.Errorf("%d is greater than maximum value for int8", src)
+	}
+	return writeInt64(buf, int64(src))
 }
 
 type NullFloat32 struct {
@@ -281,11 +526,11 @@ type NullFloat32 struct {
 	Valid bool
 }
 
-func (n NullFloat32) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (n NullFloat32) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	if n.Valid {
 		return writeFloat32(buf, n.Value)
 	}
-	return nil, 0, binaryFormat
+	return nil, 0, binaryFormat, nil
 }
 
 func (*NullFloat32) ResultFormat() int16 {
@@ -302,6 +547,16 @@ func (n *NullFloat32) DecodeResult(buf []byte) error {
 	return readNotNullFloat32(buf, &n.Value)
 }
 
+func (n *NullFloat32) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		*n = NullFloat32{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullFloat32Text(buf, &n.Value)
+}
+
 type notNullFloat32 float32
 
 func (*notNullFloat32) ResultFormat() int16 {
@@ -315,6 +570,13 @@ func (nn *notNullFloat32) DecodeResult(buf []byte) error {
 	return readNotNullFloat32(buf, (*float32)(nn))
 }
 
+func (nn *notNullFloat32) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to float32")
+	}
+	return readNotNullFloat32Text(buf, (*float32)(nn))
+}
+
 func readFloat32(dst *float32) (int16, valueReaderFunc) {
 	return binaryFormat, func(buf []byte) error {
 		if buf == nil {
@@ -332,8 +594,17 @@ func readNotNullFloat32(buf []byte, dst *float32) error {
 	return nil
 }
 
-func writeFloat32(buf []byte, src float32) ([]byte, uint32, int16) {
-	return pgio.AppendUint32(buf, math.Float32bits(src)), float4OID, binaryFormat
+func readNotNullFloat32Text(buf []byte, dst *float32) error {
+	n, err := strconv.ParseFloat(string(buf), 32)
+	if err != nil {
+		return fmt.Errorf("invalid float32: %w", err)
+	}
+	*dst = float32(n)
+	return nil
+}
+
+func writeFloat32(buf []byte, src float32) ([]byte, uint32, int16, error) {
+	return pgio.AppendUint32(buf, math.Float32bits(src)), float4OID, binaryFormat, nil
 }
 
 type NullFloat64 struct {
@@ -341,11 +612,11 @@ type NullFloat64 struct {
 	Valid bool
 }
 
-func (n NullFloat64) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (n NullFloat64) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	if n.Valid {
 		return writeFloat64(buf, n.Value)
 	}
-	return nil, 0, binaryFormat
+	return nil, 0, binaryFormat, nil
 }
 
 func (*NullFloat64) ResultFormat() int16 {
@@ -362,6 +633,16 @@ func (n *NullFloat64) DecodeResult(buf []byte) error {
 	return readNotNullFloat64(buf, &n.Value)
 }
 
+func (n *NullFloat64) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		*n = NullFloat64{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullFloat64Text(buf, &n.Value)
+}
+
 type notNullFloat64 float64
 
 func (*notNullFloat64) ResultFormat() int16 {
@@ -375,6 +656,13 @@ func (nn *notNullFloat64) DecodeResult(buf []byte) error {
 	return readNotNullFloat64(buf, (*float64)(nn))
 }
 
+func (nn *notNullFloat64) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to float64")
+	}
+	return readNotNullFloat64Text(buf, (*float64)(nn))
+}
+
 func readFloat64(dst *float64) (int16, valueReaderFunc) {
 	return binaryFormat, func(buf []byte) error {
 		if buf == nil {
@@ -392,8 +680,17 @@ func readNotNullFloat64(buf []byte, dst *float64) error {
 	return nil
 }
 
-func writeFloat64(buf []byte, src float64) ([]byte, uint32, int16) {
-	return pgio.AppendUint64(buf, math.Float64bits(src)), float8OID, binaryFormat
+func readNotNullFloat64Text(buf []byte, dst *float64) error {
+	n, err := strconv.ParseFloat(string(buf), 64)
+	if err != nil {
+		return fmt.Errorf("invalid float64: %w", err)
+	}
+	*dst = n
+	return nil
+}
+
+func writeFloat64(buf []byte, src float64) ([]byte, uint32, int16, error) {
+	return pgio.AppendUint64(buf, math.Float64bits(src)), float8OID, binaryFormat, nil
 }
 
 type NullBool struct {
@@ -401,11 +698,11 @@ type NullBool struct {
 	Valid bool
 }
 
-func (n NullBool) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (n NullBool) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	if n.Valid {
 		return writeBool(buf, n.Value)
 	}
-	return nil, 0, binaryFormat
+	return nil, 0, binaryFormat, nil
 }
 
 func (*NullBool) ResultFormat() int16 {
@@ -422,6 +719,16 @@ func (n *NullBool) DecodeResult(buf []byte) error {
 	return readNotNullBool(buf, &n.Value)
 }
 
+func (n *NullBool) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		*n = NullBool{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullBoolText(buf, &n.Value)
+}
+
 type notNullBool bool
 
 func (*notNullBool) ResultFormat() int16 {
@@ -435,6 +742,13 @@ func (nn *notNullBool) DecodeResult(buf []byte) error {
 	return readNotNullBool(buf, (*bool)(nn))
 }
 
+func (nn *notNullBool) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to bool")
+	}
+	return readNotNullBoolText(buf, (*bool)(nn))
+}
+
 func readBool(dst *bool) (int16, valueReaderFunc) {
 	return binaryFormat, func(buf []byte) error {
 		if buf == nil {
@@ -452,24 +766,98 @@ func readNotNullBool(buf []byte, dst *bool) error {
 	return nil
 }
 
-func writeBool(buf []byte, src bool) ([]byte, uint32, int16) {
+func readNotNullBoolText(buf []byte, dst *bool) error {
+	switch string(buf) {
+	case "t":
+		*dst = true
+	case "f":
+		*dst = false
+	default:
+		return fmt.Errorf("invalid bool: %q", buf)
+	}
+	return nil
+}
+
+func writeBool(buf []byte, src bool) ([]byte, uint32, int16, error) {
 	var b byte
 	if src {
 		b = 1
 	}
-	return append(buf, b), boolOID, binaryFormat
+	return append(buf, b), boolOID, binaryFormat, nil
+}
+
+// notNullChar decodes the internal single byte "char" type used by catalog columns such as pg_class.relkind.
+type notNullChar byte
+
+func (*notNullChar) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *notNullChar) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to byte")
+	}
+	if len(buf) != 1 {
+		return fmt.Errorf("\"char\" requires data length of 1, got %d", len(buf))
+	}
+	*nn = notNullChar(buf[0])
+	return nil
+}
+
+// InfinityModifier distinguishes the PostgreSQL date and time values infinity and -infinity from finite values.
+type InfinityModifier int8
+
+const (
+	Finite           InfinityModifier = 0
+	Infinity         InfinityModifier = 1
+	NegativeInfinity InfinityModifier = -1
+)
+
+func (im InfinityModifier) String() string {
+	switch im {
+	case Finite:
+		return "finite"
+	case Infinity:
+		return "infinity"
+	case NegativeInfinity:
+		return "-infinity"
+	default:
+		return "invalid"
+	}
 }
 
+// infinityModifierOf returns the InfinityModifier of t where infinity and negativeInfinity are the values that
+// represent infinity and -infinity.
+func infinityModifierOf(t, infinity, negativeInfinity time.Time) InfinityModifier {
+	switch t {
+	case infinity:
+		return Infinity
+	case negativeInfinity:
+		return NegativeInfinity
+	default:
+		return Finite
+	}
+}
+
+// NullDate is a date that may be NULL. If InfinityModifier is not Finite, Value is ignored when encoding. When
+// decoding infinity or -infinity, InfinityModifier is set and Value is set to DateInfinity or DateNegativeInfinity.
 type NullDate struct {
-	Value time.Time
-	Valid bool
+	Value            time.Time
+	InfinityModifier InfinityModifier
+	Valid            bool
 }
 
-func (n NullDate) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (n NullDate) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	if n.Valid {
+		switch n.InfinityModifier {
+		case Infinity:
+			return writeDate(buf, time.Time(DateInfinity))
+		case NegativeInfinity:
+			return writeDate(buf, time.Time(DateNegativeInfinity))
+		}
 		return writeDate(buf, n.Value)
 	}
-	return nil, 0, binaryFormat
+	return nil, 0, binaryFormat, nil
 }
 
 func (*NullDate) ResultFormat() int16 {
@@ -483,7 +871,9 @@ func (n *NullDate) DecodeResult(buf []byte) error {
 	}
 
 	n.Valid = true
-	return readNotNullDate(buf, &n.Value)
+	err := readNotNullDate(buf, &n.Value)
+	n.InfinityModifier = infinityModifierOf(n.Value, time.Time(DateInfinity), time.Time(DateNegativeInfinity))
+	return err
 }
 
 // DateNegativeInfinity represents the PostgreSQL date value -Infinity. It is less than all dates the PostgreSQL date
@@ -501,7 +891,7 @@ const (
 
 type Date time.Time
 
-func (nn Date) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (nn Date) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	return writeDate(buf, time.Time(nn))
 }
 
@@ -543,10 +933,7 @@ func readNotNullDate(buf []byte, dst *time.Time) error {
 	return nil
 }
 
-func writeDate(buf []byte, src time.Time) ([]byte, uint32, int16) {
-	tUnix := time.Date(src.Year(), src.Month(), src.Day(), 0, 0, 0, 0, time.UTC).Unix()
-	dateEpoch := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
-
+func writeDate(buf []byte, src time.Time) ([]byte, uint32, int16, error) {
 	var daysSinceDateEpoch int32
 	switch Date(src) {
 	case DateInfinity:
@@ -554,23 +941,63 @@ func writeDate(buf []byte, src time.Time) ([]byte, uint32, int16) {
 	case DateNegativeInfinity:
 		daysSinceDateEpoch = negativeInfinityDayOffset
 	default:
-		secSinceDateEpoch := tUnix - dateEpoch
-		daysSinceDateEpoch = int32(secSinceDateEpoch / 86400)
+		days := daysSinceY2K(int64(src.Year()), src.Month(), int64(src.Day()))
+		if days < minDateDayOffset || days > maxDateDayOffset {
+			return nil, dateOID, 0, fmt.Errorf("date %s is out of range", src.Format("2006-01-02"))
+		}
+		daysSinceDateEpoch = int32(days)
 	}
 
-	return pgio.AppendInt32(buf, daysSinceDateEpoch), dateOID, binaryFormat
+	return pgio.AppendInt32(buf, daysSinceDateEpoch), dateOID, binaryFormat, nil
 }
 
+// The range of the PostgreSQL date type in days since 2000-01-01.
+const (
+	minDateDayOffset = -2451545   // 4714-11-24 BC
+	maxDateDayOffset = 2145031948 // 5874897-12-31
+)
+
+// daysSinceY2K returns the number of days from 2000-01-01 to the date in the proleptic Gregorian calendar that
+// PostgreSQL uses for all dates. Year 0 is 1 BC.
+func daysSinceY2K(year int64, month time.Month, day int64) int64 {
+	// Count years from March so the leap day is the last day of the year.
+	if month <= time.February {
+		year--
+	}
+
+	era := year / 400
+	if year < 0 && year%400 != 0 {
+		era--
+	}
+	yearOfEra := year - era*400
+
+	monthFromMarch := (int64(month) + 9) % 12
+	dayOfYear := (153*monthFromMarch+2)/5 + day - 1
+	dayOfEra := yearOfEra*365 + yearOfEra/4 - yearOfEra/100 + dayOfYear
+
+	// 0000-03-01 is 730425 days before 2000-01-01.
+	return era*146097 + dayOfEra - 730425
+}
+
+// NullTime is a timestamptz that may be NULL. If InfinityModifier is not Finite, Value is ignored when encoding. When
+// decoding infinity or -infinity, InfinityModifier is set and Value is set to TimeInfinity or TimeNegativeInfinity.
 type NullTime struct {
-	Value time.Time
-	Valid bool
+	Value            time.Time
+	InfinityModifier InfinityModifier
+	Valid            bool
 }
 
-func (n NullTime) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (n NullTime) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	if n.Valid {
+		switch n.InfinityModifier {
+		case Infinity:
+			return writeTime(buf, TimeInfinity)
+		case NegativeInfinity:
+			return writeTime(buf, TimeNegativeInfinity)
+		}
 		return writeTime(buf, n.Value)
 	}
-	return nil, 0, binaryFormat
+	return nil, 0, binaryFormat, nil
 }
 
 func (*NullTime) ResultFormat() int16 {
@@ -584,7 +1011,9 @@ func (n *NullTime) DecodeResult(buf []byte) error {
 	}
 
 	n.Valid = true
-	return readNotNullTime(buf, &n.Value)
+	err := readNotNullTime(buf, &n.Value)
+	n.InfinityModifier = infinityModifierOf(n.Value, TimeInfinity, TimeNegativeInfinity)
+	return err
 }
 
 // TimeNegativeInfinity represents the PostgreSQL timestamptz value -Infinity. It is less than all times the PostgreSQL
@@ -603,7 +1032,7 @@ const (
 
 type notNullTime time.Time
 
-func (nn notNullTime) EncodeParam(buf []byte) ([]byte, uint32, int16) {
+func (nn notNullTime) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
 	return writeDate(buf, time.Time(nn))
 }
 
@@ -647,7 +1076,7 @@ func readNotNullTime(buf []byte, dst *time.Time) error {
 	return nil
 }
 
-func writeTime(buf []byte, src time.Time) ([]byte, uint32, int16) {
+func writeTime(buf []byte, src time.Time) ([]byte, uint32, int16, error) {
 	var microsecSinceY2K int64
 	switch src {
 	case TimeInfinity:
@@ -659,5 +1088,1023 @@ func writeTime(buf []byte, src time.Time) ([]byte, uint32, int16) {
 		microsecSinceY2K = microsecSinceUnixEpoch - microsecFromUnixEpochToY2K
 	}
 
-	return pgio.AppendInt64(buf, microsecSinceY2K), timestamptzOID, binaryFormat
+	return pgio.AppendInt64(buf, microsecSinceY2K), timestamptzOID, binaryFormat, nil
+}
+
+type NullInterval struct {
+	Value Interval
+	Valid bool
+}
+
+func (n NullInterval) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeInterval(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullInterval) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullInterval) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullInterval{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullInterval(buf, &n.Value)
+}
+
+// Interval represents a PostgreSQL interval. Unlike time.Duration, it preserves the month and day components of an
+// interval separately from the time component so values such as '1 mon' round-trip exactly.
+type Interval struct {
+	Months       int32
+	Days         int32
+	Microseconds int64
+}
+
+func (nn Interval) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writeInterval(buf, nn)
+}
+
+func (*Interval) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *Interval) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to Interval")
+	}
+	return readNotNullInterval(buf, nn)
+}
+
+func readNotNullInterval(buf []byte, dst *Interval) error {
+	if len(buf) != 16 {
+		return fmt.Errorf("interval requires data length of 16, got %d", len(buf))
+	}
+
+	*dst = Interval{
+		Microseconds: int64(binary.BigEndian.Uint64(buf)),
+		Days:         int32(binary.BigEndian.Uint32(buf[8:])),
+		Months:       int32(binary.BigEndian.Uint32(buf[12:])),
+	}
+	return nil
+}
+
+func writeInterval(buf []byte, src Interval) ([]byte, uint32, int16, error) {
+	buf = pgio.AppendInt64(buf, src.Microseconds)
+	buf = pgio.AppendInt32(buf, src.Days)
+	buf = pgio.AppendInt32(buf, src.Months)
+	return buf, intervalOID, binaryFormat, nil
+}
+
+type NullInet struct {
+	Value net.IPNet
+	Valid bool
+}
+
+func (n NullInet) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeInet(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullInet) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullInet) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullInet{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullInet(buf, &n.Value)
+}
+
+type notNullInet net.IPNet
+
+func (*notNullInet) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *notNullInet) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to net.IPNet")
+	}
+	return readNotNullInet(buf, (*net.IPNet)(nn))
+}
+
+// Address families used by the inet and cidr binary formats.
+const (
+	inetFamilyIPv4 = 2
+	inetFamilyIPv6 = 3
+)
+
+// readNotNullInet decodes an inet or cidr. The IP is not masked so the host bits of an inet are preserved.
+func readNotNullInet(buf []byte, dst *net.IPNet) error {
+	// The binary format is the address family, the number of mask bits, a cidr flag, the address length, and the address.
+	if len(buf) != 8 && len(buf) != 20 {
+		return fmt.Errorf("inet requires data length of 8 or 20, got %d", len(buf))
+	}
+
+	family, bits, addrLen := buf[0], int(buf[1]), int(buf[3])
+	switch {
+	case family == inetFamilyIPv4 && addrLen == net.IPv4len && len(buf) == 8:
+	case family == inetFamilyIPv6 && addrLen == net.IPv6len && len(buf) == 20:
+	default:
+		return fmt.Errorf("inet has invalid address family %d and length %d", family, addrLen)
+	}
+	if bits > addrLen*8 {
+		return fmt.Errorf("inet has invalid mask bits %d for address length %d", bits, addrLen)
+	}
+
+	ip := make(net.IP, addrLen)
+	copy(ip, buf[4:])
+	*dst = net.IPNet{IP: ip, Mask: net.CIDRMask(bits, addrLen*8)}
+	return nil
+}
+
+// writeInet encodes src as an inet. A nil Mask is a single host.
+func writeInet(buf []byte, src net.IPNet) ([]byte, uint32, int16, error) {
+	ip := src.IP.To4()
+	family := byte(inetFamilyIPv4)
+	if ip == nil {
+		ip = src.IP.To16()
+		family = inetFamilyIPv6
+	}
+	if ip == nil {
+		return nil, 0, 0, fmt.Errorf("cannot encode invalid IP address %v", src.IP)
+	}
+
+	ones := len(ip) * 8
+	if src.Mask != nil {
+		var bits int
+		ones, bits = src.Mask.Size()
+		// An IPv4 address may be paired with a 16 byte mask.
+		if len(ip) == net.IPv4len && bits == net.IPv6len*8 {
+			ones, bits = ones-96, bits-96
+		}
+		if bits != len(ip)*8 || ones < 0 {
+			return nil, 0, 0, fmt.Errorf("cannot encode invalid mask %v for IP address %v", src.Mask, src.IP)
+		}
+	}
+
+	buf = append(buf, family, byte(ones), 0, byte(len(ip)))
+	buf = append(buf, ip...)
+	return buf, inetOID, binaryFormat, nil
+}
+
+type NullHardwareAddr struct {
+	Value net.HardwareAddr
+	Valid bool
+}
+
+func (n NullHardwareAddr) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeHardwareAddr(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullHardwareAddr) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullHardwareAddr) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullHardwareAddr{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullHardwareAddr(buf, &n.Value)
+}
+
+type notNullHardwareAddr net.HardwareAddr
+
+func (*notNullHardwareAddr) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *notNullHardwareAddr) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to net.HardwareAddr")
+	}
+	return readNotNullHardwareAddr(buf, (*net.HardwareAddr)(nn))
+}
+
+// readNotNullHardwareAddr decodes a macaddr or macaddr8.
+func readNotNullHardwareAddr(buf []byte, dst *net.HardwareAddr) error {
+	if len(buf) != 6 && len(buf) != 8 {
+		return fmt.Errorf("net.HardwareAddr requires data length of 6 or 8, got %d", len(buf))
+	}
+
+	*dst = make(net.HardwareAddr, len(buf))
+	copy(*dst, buf)
+	return nil
+}
+
+// writeHardwareAddr encodes a 6 byte address as a macaddr and an 8 byte address as a macaddr8.
+func writeHardwareAddr(buf []byte, src net.HardwareAddr) ([]byte, uint32, int16, error) {
+	var oid uint32
+	switch len(src) {
+	case 6:
+		oid = macaddrOID
+	case 8:
+		oid = macaddr8OID
+	default:
+		return nil, 0, 0, fmt.Errorf("net.HardwareAddr requires length of 6 or 8, got %d", len(src))
+	}
+
+	buf = append(buf, src...)
+	return buf, oid, binaryFormat, nil
+}
+
+type NullNumeric struct {
+	Value string
+	Valid bool
+}
+
+func (n NullNumeric) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeNumeric(buf, n.Value)
+	}
+	return nil, 0, textFormat, nil
+}
+
+func (*NullNumeric) ResultFormat() int16 {
+	return textFormat
+}
+
+func (n *NullNumeric) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullNumeric{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullString(buf, &n.Value)
+}
+
+// Numeric represents a PostgreSQL numeric as its exact decimal text representation (e.g. "123.45"). No conversion to
+// or from a floating point type occurs so no precision is lost.
+type Numeric string
+
+func (nn Numeric) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writeNumeric(buf, string(nn))
+}
+
+func (*Numeric) ResultFormat() int16 {
+	return textFormat
+}
+
+func (nn *Numeric) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to Numeric")
+	}
+	return readNotNullString(buf, (*string)(nn))
+}
+
+func writeNumeric(buf []byte, src string) ([]byte, uint32, int16, error) {
+	buf = append(buf, src...)
+	return buf, numericOID, textFormat, nil
+}
+
+type NullBigNumeric struct {
+	Value BigNumeric
+	Valid bool
+}
+
+func (n NullBigNumeric) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeBigNumeric(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullBigNumeric) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullBigNumeric) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullBigNumeric{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullBigNumeric(buf, &n.Value)
+}
+
+// BigNumeric represents a PostgreSQL numeric as an arbitrary precision integer and a base 10 exponent. Its value is
+// Int * 10^Exp. A decoded BigNumeric has an Exp of the negated display scale of the numeric so trailing fractional
+// zeros are preserved (e.g. 1.50 is decoded as Int 150 and Exp -2). A nil Int is treated as zero. NaN and infinity
+// cannot be represented.
+type BigNumeric struct {
+	Int *big.Int
+	Exp int32
+}
+
+// BigInt returns n as a big.Int. It returns an error if n has a nonzero fractional part.
+func (n BigNumeric) BigInt() (*big.Int, error) {
+	i := new(big.Int)
+	if n.Int != nil {
+		i.Set(n.Int)
+	}
+
+	if n.Exp >= 0 {
+		return i.Mul(i, bigPow10(n.Exp)), nil
+	}
+
+	remainder := new(big.Int)
+	i.QuoRem(i, bigPow10(-n.Exp), remainder)
+	if remainder.Sign() != 0 {
+		return nil, fmt.Errorf("%v cannot be converted to big.Int without loss of precision", n)
+	}
+	return i, nil
+}
+
+// BigFloat returns n as a big.Float. The precision is chosen so that the integer part of n is always exact and at least
+// 64 bits of precision are available for the fractional part.
+func (n BigNumeric) BigFloat() *big.Float {
+	i := new(big.Int)
+	if n.Int != nil {
+		i.Set(n.Int)
+	}
+
+	exp := n.Exp
+	if exp < 0 {
+		exp = -exp
+	}
+	prec := uint(i.BitLen()) + uint(math.Ceil(float64(exp)*math.Log2(10))) + 64
+
+	f := new(big.Float).SetPrec(prec).SetInt(i)
+	if n.Exp >= 0 {
+		return f.Mul(f, new(big.Float).SetPrec(prec).SetInt(bigPow10(n.Exp)))
+	}
+	return f.Quo(f, new(big.Float).SetPrec(prec).SetInt(bigPow10(-n.Exp)))
+}
+
+// String returns n in decimal notation.
+func (n BigNumeric) String() string {
+	i := new(big.Int)
+	if n.Int != nil {
+		i.Abs(n.Int)
+	}
+
+	var sign string
+	if n.Int != nil && n.Int.Sign() < 0 {
+		sign = "-"
+	}
+
+	if n.Exp >= 0 {
+		return sign + i.Mul(i, bigPow10(n.Exp)).String()
+	}
+
+	digits := i.String()
+	scale := int(-n.Exp)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+func (nn BigNumeric) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writeBigNumeric(buf, nn)
+}
+
+func (*BigNumeric) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *BigNumeric) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to BigNumeric")
+	}
+	return readNotNullBigNumeric(buf, nn)
+}
+
+// bigInt decodes an integer or a numeric with no fractional part into a big.Int. The text format is used because it is
+// the same for all integer types and numeric.
+type bigInt big.Int
+
+func (*bigInt) ResultFormat() int16 {
+	return textFormat
+}
+
+func (bi *bigInt) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to big.Int")
+	}
+
+	s := string(buf)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		if strings.Trim(s[i+1:], "0") != "" {
+			return fmt.Errorf("%s cannot be converted to big.Int without loss of precision", s)
+		}
+		s = s[:i]
+	}
+
+	if _, ok := (*big.Int)(bi).SetString(s, 10); !ok {
+		return fmt.Errorf("invalid big.Int: %q", s)
+	}
+	return nil
+}
+
+// writeBigInt encodes src as a numeric.
+func writeBigInt(buf []byte, src *big.Int) ([]byte, uint32, int16, error) {
+	return writeBigNumeric(buf, BigNumeric{Int: src})
+}
+
+// Constants for the numeric binary format. Numerics are transmitted as base 10000 digits.
+const (
+	numericPositiveSign         = 0x0000
+	numericNegativeSign         = 0x4000
+	numericNaNSign              = 0xc000
+	numericInfinitySign         = 0xd000
+	numericNegativeInfinitySign = 0xf000
+	numericDigitsBase           = 10000
+)
+
+var big10 = big.NewInt(10)
+var bigNumericDigitsBase = big.NewInt(numericDigitsBase)
+
+func bigPow10(n int32) *big.Int {
+	return new(big.Int).Exp(big10, big.NewInt(int64(n)), nil)
+}
+
+func readNotNullBigNumeric(buf []byte, dst *BigNumeric) error {
+	if len(buf) < 8 {
+		return fmt.Errorf("numeric requires data length of at least 8, got %d", len(buf))
+	}
+
+	ndigits := int(int16(binary.BigEndian.Uint16(buf)))
+	weight := int32(int16(binary.BigEndian.Uint16(buf[2:])))
+	sign := binary.BigEndian.Uint16(buf[4:])
+	dscale := int32(int16(binary.BigEndian.Uint16(buf[6:])))
+
+	switch sign {
+	case numericPositiveSign, numericNegativeSign:
+	case numericNaNSign:
+		return errors.New("NaN cannot be converted to BigNumeric")
+	case numericInfinitySign, numericNegativeInfinitySign:
+		return errors.New("infinity cannot be converted to BigNumeric")
+	default:
+		return fmt.Errorf("numeric has invalid sign: %#x", sign)
+	}
+
+	if len(buf) != 8+ndigits*2 {
+		return fmt.Errorf("numeric with %d digits requires data length of %d, got %d", ndigits, 8+ndigits*2, len(buf))
+	}
+
+	n := new(big.Int)
+	digit := new(big.Int)
+	for i := 0; i < ndigits; i++ {
+		n.Mul(n, bigNumericDigitsBase)
+		n.Add(n, digit.SetInt64(int64(binary.BigEndian.Uint16(buf[8+i*2:]))))
+	}
+
+	// n is now the value scaled by 10000^(weight-ndigits+1). Rescale it to the display scale. Any digits removed when
+	// dividing are zeros that only pad out the last base 10000 digit.
+	exp := (weight - int32(ndigits) + 1) * 4
+	if exp > -dscale {
+		n.Mul(n, bigPow10(exp+dscale))
+	} else if exp < -dscale {
+		n.Quo(n, bigPow10(-dscale-exp))
+	}
+
+	if sign == numericNegativeSign {
+		n.Neg(n)
+	}
+
+	*dst = BigNumeric{Int: n, Exp: -dscale}
+	return nil
+}
+
+func writeBigNumeric(buf []byte, src BigNumeric) ([]byte, uint32, int16, error) {
+	abs := new(big.Int)
+	if src.Int != nil {
+		abs.Abs(src.Int)
+	}
+
+	exp := src.Exp
+	if exp > 0 {
+		abs.Mul(abs, bigPow10(exp))
+		exp = 0
+	}
+	dscale := -exp
+
+	// Pad the fractional part out to a whole number of base 10000 digits.
+	if pad := (4 - dscale%4) % 4; pad != 0 {
+		abs.Mul(abs, bigPow10(pad))
+	}
+	fracDigits := (dscale + 3) / 4
+
+	// digits are least significant first.
+	var digits []int16
+	remainder := new(big.Int)
+	for abs.Sign() != 0 {
+		abs.QuoRem(abs, bigNumericDigitsBase, remainder)
+		digits = append(digits, int16(remainder.Int64()))
+	}
+
+	weight := int16(len(digits) - 1 - int(fracDigits))
+
+	// Trailing zero digits are implied by the weight.
+	for len(digits) > 0 && digits[0] == 0 {
+		digits = digits[1:]
+	}
+	if len(digits) == 0 {
+		weight = 0
+	}
+
+	var sign uint16 = numericPositiveSign
+	if src.Int != nil && src.Int.Sign() < 0 {
+		sign = numericNegativeSign
+	}
+
+	buf = pgio.AppendInt16(buf, int16(len(digits)))
+	buf = pgio.AppendInt16(buf, weight)
+	buf = pgio.AppendUint16(buf, sign)
+	buf = pgio.AppendInt16(buf, int16(dscale))
+	for i := len(digits) - 1; i >= 0; i-- {
+		buf = pgio.AppendInt16(buf, digits[i])
+	}
+
+	return buf, numericOID, binaryFormat, nil
+}
+
+// DecimalDecomposer is implemented by decimal types that can be encoded as a numeric param. It matches the decompose
+// interface proposed for database/sql so third-party decimal packages can be used without goldilocks depending on them.
+//
+// Decompose returns the form of the value (0 for finite, 1 for infinite, 2 for NaN), its sign, its coefficient as a
+// big-endian unsigned integer, and its base 10 exponent. A finite value is coefficient * 10^exponent.
+type DecimalDecomposer interface {
+	Decompose(buf []byte) (form byte, negative bool, coefficient []byte, exponent int32)
+}
+
+// DecimalComposer is implemented by decimal types that can decode a numeric result. It is the inverse of
+// DecimalDecomposer.
+type DecimalComposer interface {
+	Compose(form byte, negative bool, coefficient []byte, exponent int32) error
+}
+
+const (
+	decimalFormFinite   = 0
+	decimalFormInfinite = 1
+	decimalFormNaN      = 2
+)
+
+type decimalComposer struct {
+	dst DecimalComposer
+}
+
+func (decimalComposer) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (dc decimalComposer) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return fmt.Errorf("NULL cannot be converted to %T", dc.dst)
+	}
+
+	if len(buf) >= 8 {
+		switch binary.BigEndian.Uint16(buf[4:]) {
+		case numericNaNSign:
+			return dc.dst.Compose(decimalFormNaN, false, nil, 0)
+		case numericInfinitySign:
+			return dc.dst.Compose(decimalFormInfinite, false, nil, 0)
+		case numericNegativeInfinitySign:
+			return dc.dst.Compose(decimalFormInfinite, true, nil, 0)
+		}
+	}
+
+	var n BigNumeric
+	err := readNotNullBigNumeric(buf, &n)
+	if err != nil {
+		return err
+	}
+
+	return dc.dst.Compose(decimalFormFinite, n.Int.Sign() < 0, new(big.Int).Abs(n.Int).Bytes(), n.Exp)
+}
+
+func writeDecimal(buf []byte, src DecimalDecomposer) ([]byte, uint32, int16, error) {
+	form, negative, coefficient, exponent := src.Decompose(nil)
+	switch form {
+	case decimalFormInfinite:
+		if negative {
+			return writeNumericSpecialValue(buf, numericNegativeInfinitySign)
+		}
+		return writeNumericSpecialValue(buf, numericInfinitySign)
+	case decimalFormNaN:
+		return writeNumericSpecialValue(buf, numericNaNSign)
+	}
+
+	n := BigNumeric{Int: new(big.Int).SetBytes(coefficient), Exp: exponent}
+	if negative {
+		n.Int.Neg(n.Int)
+	}
+	return writeBigNumeric(buf, n)
+}
+
+// writeNumericSpecialValue writes a numeric NaN or infinity identified by its sign.
+func writeNumericSpecialValue(buf []byte, sign uint16) ([]byte, uint32, int16, error) {
+	buf = pgio.AppendInt16(buf, 0)
+	buf = pgio.AppendInt16(buf, 0)
+	buf = pgio.AppendUint16(buf, sign)
+	buf = pgio.AppendInt16(buf, 0)
+	return buf, numericOID, binaryFormat, nil
+}
+
+type NullMoney struct {
+	Value Money
+	Valid bool
+}
+
+func (n NullMoney) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeMoney(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullMoney) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullMoney) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullMoney{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullMoney(buf, &n.Value)
+}
+
+// Money represents a PostgreSQL money as an integer number of the smallest currency unit (e.g. cents). The number of
+// fractional digits depends on the lc_monetary setting of the server and is not included in the binary format. Use
+// LoadMoneyScale to get it.
+type Money int64
+
+// Numeric returns m as a BigNumeric with scale fractional digits.
+func (m Money) Numeric(scale int32) BigNumeric {
+	return BigNumeric{Int: big.NewInt(int64(m)), Exp: -scale}
+}
+
+func (nn Money) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writeMoney(buf, nn)
+}
+
+func (*Money) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *Money) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to Money")
+	}
+	return readNotNullMoney(buf, nn)
+}
+
+func readNotNullMoney(buf []byte, dst *Money) error {
+	if len(buf) != 8 {
+		return fmt.Errorf("money requires data length of 8, got %d", len(buf))
+	}
+	*dst = Money(binary.BigEndian.Uint64(buf))
+	return nil
+}
+
+func writeMoney(buf []byte, src Money) ([]byte, uint32, int16, error) {
+	return pgio.AppendInt64(buf, int64(src)), moneyOID, binaryFormat, nil
+}
+
+// LoadMoneyScale returns the number of fractional digits of money values for the lc_monetary setting of db.
+func LoadMoneyScale(ctx context.Context, db StdDB) (int32, error) {
+	var scale int32
+	_, err := db.Query(ctx, "select scale(1::money::numeric)::int4", nil, []interface{}{&scale}, func() error { return nil })
+	if err != nil {
+		return 0, err
+	}
+	return scale, nil
+}
+
+type NullOID struct {
+	Value OID
+	Valid bool
+}
+
+func (n NullOID) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if n.Valid {
+		return writeOID(buf, n.Value)
+	}
+	return nil, 0, binaryFormat, nil
+}
+
+func (*NullOID) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (n *NullOID) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*n = NullOID{Valid: false}
+		return nil
+	}
+
+	n.Valid = true
+	return readNotNullOID(buf, &n.Value)
+}
+
+// OID represents a PostgreSQL object identifier. It can be decoded from an oid or any of the oid alias types such as
+// regclass, regtype, and regproc. It is encoded as an oid. Read an alias type into a string to get the name of the
+// object.
+type OID uint32
+
+func (nn OID) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	return writeOID(buf, nn)
+}
+
+func (*OID) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (nn *OID) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return errors.New("NULL cannot be converted to OID")
+	}
+	return readNotNullOID(buf, nn)
+}
+
+func readNotNullOID(buf []byte, dst *OID) error {
+	if len(buf) != 4 {
+		return fmt.Errorf("oid requires data length of 4, got %d", len(buf))
+	}
+	*dst = OID(binary.BigEndian.Uint32(buf))
+	return nil
+}
+
+func writeOID(buf []byte, src OID) ([]byte, uint32, int16, error) {
+	return pgio.AppendUint32(buf, uint32(src)), oidOID, binaryFormat, nil
+}
+
+// sqlScanner decodes a result in the text format with the Scan method of a database/sql.Scanner. This allows types
+// written for database/sql to be used as results. The value passed to Scan is a string or nil for NULL.
+type sqlScanner struct {
+	dst sql.Scanner
+}
+
+func (sqlScanner) ResultFormat() int16 {
+	return textFormat
+}
+
+func (ss sqlScanner) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return ss.dst.Scan(nil)
+	}
+	return ss.dst.Scan(string(buf))
+}
+
+// ptrResult decodes a result into a pointer such as a *string through a pointer to it. NULL sets the pointer to nil.
+// Otherwise a new value is allocated and decoded into as if a pointer to it were the result. resolve returns the
+// ResultDecoder for the new value.
+type ptrResult struct {
+	ptr     reflect.Value
+	format  int16
+	resolve func(dst interface{}) (ResultDecoder, bool)
+}
+
+// textPtrResult is a ptrResult for a type whose ResultDecoder is a TextResultDecoder.
+type textPtrResult struct {
+	ptrResult
+}
+
+// newPtrResult returns a ptrResult for dst if dst is a non-nil pointer to a pointer to a type resolve supports.
+func newPtrResult(dst interface{}, resolve func(dst interface{}) (ResultDecoder, bool)) (ResultDecoder, bool) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Ptr {
+		return nil, false
+	}
+
+	rd, ok := resolve(reflect.New(v.Elem().Type().Elem()).Interface())
+	if !ok {
+		return nil, false
+	}
+
+	pr := ptrResult{ptr: v.Elem(), format: rd.ResultFormat(), resolve: resolve}
+	if _, ok := rd.(TextResultDecoder); ok {
+		return textPtrResult{pr}, true
+	}
+	return pr, true
+}
+
+func (pr ptrResult) ResultFormat() int16 {
+	return pr.format
+}
+
+func (pr ptrResult) DecodeResult(buf []byte) error {
+	return pr.decode(buf, ResultDecoder.DecodeResult)
+}
+
+func (pr textPtrResult) DecodeTextResult(buf []byte) error {
+	return pr.decode(buf, func(rd ResultDecoder, buf []byte) error {
+		return rd.(TextResultDecoder).DecodeTextResult(buf)
+	})
+}
+
+// decode sets the pointer to nil if buf is nil. Otherwise it allocates a new value, decodes buf into it with decode,
+// and sets the pointer to it.
+func (pr ptrResult) decode(buf []byte, decode func(rd ResultDecoder, buf []byte) error) error {
+	if buf == nil {
+		pr.ptr.Set(reflect.Zero(pr.ptr.Type()))
+		return nil
+	}
+
+	v := reflect.New(pr.ptr.Type().Elem())
+	err := decode(pr.inner(v), buf)
+	if err != nil {
+		return err
+	}
+
+	pr.ptr.Set(v)
+	return nil
+}
+
+// inner returns the ResultDecoder for v, which must be a pointer to the type pr.ptr points to.
+func (pr ptrResult) inner(v reflect.Value) ResultDecoder {
+	rd, _ := pr.resolve(v.Interface())
+	return rd
+}
+
+// bytea decodes a bytea. A NULL is decoded as a nil []byte.
+type bytea []byte
+
+func (*bytea) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (dst *bytea) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*dst = nil
+		return nil
+	}
+
+	*dst = make([]byte, len(buf))
+	copy(*dst, buf)
+	return nil
+}
+
+// DecodeTextResult decodes the hex text format of bytea.
+func (dst *bytea) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		*dst = nil
+		return nil
+	}
+
+	if len(buf) < 2 || buf[0] != '\\' || buf[1] != 'x' {
+		return fmt.Errorf("bytea text format must be hex")
+	}
+	b := make([]byte, hex.DecodedLen(len(buf)-2))
+	_, err := hex.Decode(b, buf[2:])
+	if err != nil {
+		return fmt.Errorf("invalid bytea: %w", err)
+	}
+	*dst = b
+	return nil
+}
+
+func writeBytea(buf []byte, src []byte) ([]byte, uint32, int16, error) {
+	if src == nil {
+		return nil, byteaOID, binaryFormat, nil
+	}
+
+	buf = append(buf, src...)
+	return buf, byteaOID, binaryFormat, nil
+}
+
+// jsonbVersion is the version of the jsonb binary format. It prefixes the JSON text.
+const jsonbVersion = 1
+
+// jsonRawMessage decodes json or jsonb. A NULL is decoded as a nil json.RawMessage.
+type jsonRawMessage json.RawMessage
+
+func (*jsonRawMessage) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (dst *jsonRawMessage) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*dst = nil
+		return nil
+	}
+
+	// The binary format of json is the JSON text. The binary format of jsonb is a version byte followed by the JSON
+	// text. JSON text can never begin with the version byte so both can be decoded without knowing the column type.
+	if len(buf) > 0 && buf[0] == jsonbVersion {
+		buf = buf[1:]
+	}
+
+	*dst = make(jsonRawMessage, len(buf))
+	copy(*dst, buf)
+	return nil
+}
+
+// DecodeTextResult decodes the text format of json or jsonb, which is the JSON text.
+func (dst *jsonRawMessage) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		*dst = nil
+		return nil
+	}
+
+	*dst = make(jsonRawMessage, len(buf))
+	copy(*dst, buf)
+	return nil
+}
+
+func writeJSONB(buf []byte, src []byte) ([]byte, uint32, int16, error) {
+	if src == nil {
+		return nil, jsonbOID, binaryFormat, nil
+	}
+
+	buf = append(buf, jsonbVersion)
+	buf = append(buf, src...)
+	return buf, jsonbOID, binaryFormat, nil
+}
+
+// JSONValue is a query param or result that is marshaled to or unmarshaled from JSON with encoding/json. It is created
+// with JSON.
+type JSONValue struct {
+	v interface{}
+}
+
+// JSON wraps v so it is encoded as a jsonb param with json.Marshal or decoded from a json or jsonb result with
+// json.Unmarshal. When used as a result v must be a pointer. A nil v is encoded as NULL. A NULL result is unmarshaled
+// as the JSON null.
+func JSON(v interface{}) JSONValue {
+	return JSONValue{v: v}
+}
+
+func (j JSONValue) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if j.v == nil {
+		return nil, jsonbOID, binaryFormat, nil
+	}
+
+	src, err := json.Marshal(j.v)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return writeJSONB(buf, src)
+}
+
+func (JSONValue) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (j JSONValue) DecodeResult(buf []byte) error {
+	if buf == nil {
+		buf = []byte("null")
+	} else if len(buf) > 0 && buf[0] == jsonbVersion {
+		buf = buf[1:]
+	}
+
+	return json.Unmarshal(buf, j.v)
+}
+
+// JSONRow returns a result decoder that unmarshals a json or jsonb result into dst with json.Unmarshal. It is intended
+// for scanning rows into structs with select row_to_json(t) from t. dst must be a pointer. Unlike JSON, the value dst
+// points to is reset to its zero value before each result is unmarshaled so fields that are null in one row do not keep
+// the value from the previous row. NULL cannot be decoded.
+func JSONRow(dst interface{}) ResultDecoder {
+	return jsonRow{dst: dst}
+}
+
+type jsonRow struct {
+	dst interface{}
+}
+
+func (jsonRow) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (jr jsonRow) DecodeResult(buf []byte) error {
+	if buf == nil {
+		return fmt.Errorf("NULL cannot be converted to %T", jr.dst)
+	}
+
+	v := reflect.ValueOf(jr.dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("JSONRow requires a non-nil pointer, got %T", jr.dst)
+	}
+	v.Elem().Set(reflect.Zero(v.Elem().Type()))
+
+	if len(buf) > 0 && buf[0] == jsonbVersion {
+		buf = buf[1:]
+	}
+
+	return json.Unmarshal(buf, jr.dst)
 }
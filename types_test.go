@@ -2,6 +2,11 @@ package goldilocks_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
 	"os"
 	"testing"
 	"time"
@@ -46,6 +51,52 @@ func TestDate(t *testing.T) {
 	ensurePgConnValid(t, pgConn)
 }
 
+func TestDateExtremes(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	for _, tt := range []struct {
+		date time.Time
+		text string
+	}{
+		{time.Date(-4713, 11, 24, 0, 0, 0, 0, time.UTC), "4714-11-24 BC"},
+		{time.Date(-1, 12, 31, 0, 0, 0, 0, time.UTC), "0002-12-31 BC"},
+		{time.Date(0, 2, 29, 0, 0, 0, 0, time.UTC), "0001-02-29 BC"},
+		{time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), "0001-01-01"},
+		{time.Date(1582, 10, 4, 0, 0, 0, 0, time.UTC), "1582-10-04"},
+		{time.Date(1582, 10, 15, 0, 0, 0, 0, time.UTC), "1582-10-15"},
+		{time.Date(1600, 2, 29, 0, 0, 0, 0, time.UTC), "1600-02-29"},
+		{time.Date(5874897, 12, 31, 0, 0, 0, 0, time.UTC), "5874897-12-31"},
+	} {
+		var date time.Time
+		var text string
+		_, err := db.Query(
+			context.Background(),
+			"select $1::date, $1::date::text, $2::text::date",
+			[]interface{}{goldilocks.Date(tt.date), tt.text},
+			[]interface{}{nil, &text, (*goldilocks.Date)(&date)},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equal(t, tt.text, text)
+		require.Truef(t, tt.date.Equal(date), "%s: got %v", tt.text, date)
+	}
+
+	for _, date := range []time.Time{
+		time.Date(-4713, 11, 23, 0, 0, 0, 0, time.UTC),
+		time.Date(5874898, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		_, err := db.Exec(context.Background(), "select $1", goldilocks.Date(date))
+		require.EqualError(t, err, fmt.Sprintf("cannot encode args[0] (goldilocks.Date as type oid 1082): date %s is out of range", date.Format("2006-01-02")))
+	}
+
+	ensurePgConnValid(t, pgConn)
+}
+
 func TestDateInfinity(t *testing.T) {
 	t.Parallel()
 
@@ -85,6 +136,53 @@ func TestDateInfinity(t *testing.T) {
 	ensurePgConnValid(t, pgConn)
 }
 
+func TestNullDateAndTimeInfinityModifier(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var dateInf, dateNinf, dateFinite goldilocks.NullDate
+	var timeInf, timeNinf, timeFinite goldilocks.NullTime
+	_, err = db.Query(
+		context.Background(),
+		"select 'infinity'::date, '-infinity'::date, '2020-01-02'::date, 'infinity'::timestamptz, '-infinity'::timestamptz, '2020-01-02 03:04:05Z'::timestamptz",
+		nil,
+		[]interface{}{&dateInf, &dateNinf, &dateFinite, &timeInf, &timeNinf, &timeFinite},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.Infinity, dateInf.InfinityModifier)
+	require.Equal(t, goldilocks.NegativeInfinity, dateNinf.InfinityModifier)
+	require.Equal(t, goldilocks.Finite, dateFinite.InfinityModifier)
+	require.Equal(t, goldilocks.Infinity, timeInf.InfinityModifier)
+	require.Equal(t, goldilocks.NegativeInfinity, timeNinf.InfinityModifier)
+	require.Equal(t, goldilocks.Finite, timeFinite.InfinityModifier)
+	require.True(t, dateInf.Value.Equal(time.Time(goldilocks.DateInfinity)))
+	require.True(t, timeFinite.Value.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	// Value is ignored when InfinityModifier is not Finite.
+	var results [4]bool
+	_, err = db.Query(
+		context.Background(),
+		"select $1::date = 'infinity', $2::date = '-infinity', $3::timestamptz = 'infinity', $4::timestamptz = '-infinity'",
+		[]interface{}{
+			goldilocks.NullDate{Value: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), InfinityModifier: goldilocks.Infinity, Valid: true},
+			goldilocks.NullDate{InfinityModifier: goldilocks.NegativeInfinity, Valid: true},
+			goldilocks.NullTime{InfinityModifier: goldilocks.Infinity, Valid: true},
+			goldilocks.NullTime{Value: time.Now(), InfinityModifier: goldilocks.NegativeInfinity, Valid: true},
+		},
+		[]interface{}{&results[0], &results[1], &results[2], &results[3]},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, [4]bool{true, true, true, true}, results)
+
+	ensurePgConnValid(t, pgConn)
+}
+
 func TestTime(t *testing.T) {
 	t.Parallel()
 
@@ -157,3 +255,978 @@ func TestTimeInfinity(t *testing.T) {
 
 	ensurePgConnValid(t, pgConn)
 }
+
+func TestInterval(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	for _, tt := range []struct {
+		sql      string
+		interval goldilocks.Interval
+	}{
+		{"0 seconds", goldilocks.Interval{}},
+		{"1 microsecond", goldilocks.Interval{Microseconds: 1}},
+		{"-1 microsecond", goldilocks.Interval{Microseconds: -1}},
+		{"1 day", goldilocks.Interval{Days: 1}},
+		{"1 month", goldilocks.Interval{Months: 1}},
+		{"1 year 2 months 3 days 04:05:06.789", goldilocks.Interval{Months: 14, Days: 3, Microseconds: 14706789000}},
+		{"-2 years -3 days -00:00:01", goldilocks.Interval{Months: -24, Days: -3, Microseconds: -1000000}},
+	} {
+		var interval goldilocks.Interval
+		var match bool
+
+		_, err := db.Query(
+			context.Background(),
+			"select $1::interval, $2 = $1::interval",
+			[]interface{}{tt.sql, tt.interval},
+			[]interface{}{&interval, &match},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equalf(t, tt.interval, interval, "%s", tt.sql)
+		require.Truef(t, match, "%s", tt.sql)
+	}
+
+	var null goldilocks.NullInterval
+	var notNull goldilocks.NullInterval
+	_, err = db.Query(
+		context.Background(),
+		"select $1::interval, $2::interval",
+		[]interface{}{goldilocks.NullInterval{}, goldilocks.NullInterval{Value: goldilocks.Interval{Months: 1, Days: 2, Microseconds: 3}, Valid: true}},
+		[]interface{}{&null, &notNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.NullInterval{}, null)
+	require.Equal(t, goldilocks.NullInterval{Value: goldilocks.Interval{Months: 1, Days: 2, Microseconds: 3}, Valid: true}, notNull)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestInet(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	for i, tt := range []struct {
+		sql      string
+		expected net.IPNet
+		text     string
+	}{
+		{sql: "select '127.0.0.1'::inet", expected: net.IPNet{IP: net.IP{127, 0, 0, 1}, Mask: net.CIDRMask(32, 32)}, text: "127.0.0.1/32"},
+		{sql: "select '192.168.0.1/24'::inet", expected: net.IPNet{IP: net.IP{192, 168, 0, 1}, Mask: net.CIDRMask(24, 32)}, text: "192.168.0.1/24"},
+		{sql: "select '10.0.0.0/8'::cidr", expected: net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}, text: "10.0.0.0/8"},
+		{sql: "select '::1'::inet", expected: net.IPNet{IP: net.ParseIP("::1"), Mask: net.CIDRMask(128, 128)}, text: "::1/128"},
+		{sql: "select '2001:db8::/32'::cidr", expected: net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(32, 128)}, text: "2001:db8::/32"},
+	} {
+		var ipNet net.IPNet
+		_, err := db.Query(context.Background(), tt.sql, nil, []interface{}{&ipNet}, func() error { return nil })
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, tt.expected, ipNet, "%d", i)
+
+		var text string
+		_, err = db.Query(context.Background(), "select $1::inet::text", []interface{}{ipNet}, []interface{}{&text}, func() error { return nil })
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, tt.text, text, "%d", i)
+	}
+
+	_, ipNet, err := net.ParseCIDR("192.168.100.0/22")
+	require.NoError(t, err)
+	var contains bool
+	_, err = db.Query(context.Background(), "select $1::inet >> '192.168.101.7'", []interface{}{*ipNet}, []interface{}{&contains}, func() error { return nil })
+	require.NoError(t, err)
+	require.True(t, contains)
+
+	var text string
+	_, err = db.Query(context.Background(), "select $1::inet::text", []interface{}{net.IPNet{IP: net.ParseIP("10.1.2.3")}}, []interface{}{&text}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, "10.1.2.3/32", text)
+
+	var n goldilocks.NullInet
+	var isNull bool
+	_, err = db.Query(
+		context.Background(),
+		"select null::inet, $1::inet is null",
+		[]interface{}{goldilocks.NullInet{}},
+		[]interface{}{&n, &isNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.False(t, n.Valid)
+	require.True(t, isNull)
+
+	var ipNetResult net.IPNet
+	_, err = db.Query(context.Background(), "select null::inet", nil, []interface{}{&ipNetResult}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "inet"): NULL cannot be converted to net.IPNet`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestHardwareAddr(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	mac := net.HardwareAddr{0x08, 0x00, 0x2b, 0x01, 0x02, 0x03}
+	mac8 := net.HardwareAddr{0x08, 0x00, 0x2b, 0x01, 0x02, 0x03, 0x04, 0x05}
+
+	var macOut, mac8Out net.HardwareAddr
+	var macText, mac8Text string
+	var null goldilocks.NullHardwareAddr
+	var isNull bool
+	_, err = db.Query(
+		context.Background(),
+		"select $1::macaddr, $1::macaddr::text, $2::macaddr8, $2::macaddr8::text, null::macaddr, $3::macaddr is null",
+		[]interface{}{mac, mac8, goldilocks.NullHardwareAddr{}},
+		[]interface{}{&macOut, &macText, &mac8Out, &mac8Text, &null, &isNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, mac, macOut)
+	require.Equal(t, "08:00:2b:01:02:03", macText)
+	require.Equal(t, mac8, mac8Out)
+	require.Equal(t, "08:00:2b:01:02:03:04:05", mac8Text)
+	require.False(t, null.Valid)
+	require.True(t, isNull)
+
+	_, err = db.Exec(context.Background(), "select $1::macaddr", net.HardwareAddr{1, 2, 3})
+	require.Error(t, err)
+
+	_, err = db.Query(context.Background(), "select null::macaddr", nil, []interface{}{&macOut}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "macaddr"): NULL cannot be converted to net.HardwareAddr`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestNumeric(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	for _, s := range []string{
+		"0",
+		"1",
+		"-1",
+		"123.45",
+		"0.000000000000000000000000000001",
+		"123456789012345678901234567890.123456789012345678901234567890",
+		"-0.50",
+		"NaN",
+	} {
+		var n goldilocks.Numeric
+		var match bool
+
+		_, err := db.Query(
+			context.Background(),
+			"select $1::numeric, $1::numeric = $2::text::numeric",
+			[]interface{}{goldilocks.Numeric(s), s},
+			[]interface{}{&n, &match},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equal(t, goldilocks.Numeric(s), n)
+		require.Truef(t, match, "%s", s)
+	}
+
+	var null goldilocks.NullNumeric
+	var notNull goldilocks.NullNumeric
+	_, err = db.Query(
+		context.Background(),
+		"select $1::numeric, $2::numeric",
+		[]interface{}{goldilocks.NullNumeric{}, goldilocks.NullNumeric{Value: "1.10", Valid: true}},
+		[]interface{}{&null, &notNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.NullNumeric{}, null)
+	require.Equal(t, goldilocks.NullNumeric{Value: "1.10", Valid: true}, notNull)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestNumericAsString(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var n goldilocks.Numeric
+	var s string
+	var ns goldilocks.NullString
+	_, err = db.Query(
+		context.Background(),
+		"select x, x, x from (values (12345678901234567890.10::numeric(30,2))) t(x)",
+		nil,
+		[]interface{}{&n, &s, &ns},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.Numeric("12345678901234567890.10"), n)
+	require.Equal(t, "12345678901234567890.10", s)
+	require.Equal(t, goldilocks.NullString{Value: "12345678901234567890.10", Valid: true}, ns)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestBigNumericBinaryFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		n   goldilocks.BigNumeric
+		s   string
+		buf []byte
+	}{
+		{goldilocks.BigNumeric{Int: big.NewInt(0), Exp: 0}, "0", []byte{0, 0, 0, 0, 0, 0, 0, 0}},
+		{goldilocks.BigNumeric{Int: big.NewInt(0), Exp: -2}, "0.00", []byte{0, 0, 0, 0, 0, 0, 0, 2}},
+		{goldilocks.BigNumeric{Int: big.NewInt(150), Exp: -2}, "1.50", []byte{0, 2, 0, 0, 0, 0, 0, 2, 0, 1, 0x13, 0x88}},
+		{goldilocks.BigNumeric{Int: big.NewInt(-12345678), Exp: -3}, "-12345.678", []byte{0, 3, 0, 1, 0x40, 0, 0, 3, 0, 1, 0x09, 0x29, 0x1a, 0x7c}},
+		{goldilocks.BigNumeric{Int: big.NewInt(1), Exp: -4}, "0.0001", []byte{0, 1, 0xff, 0xff, 0, 0, 0, 4, 0, 1}},
+		{goldilocks.BigNumeric{Int: big.NewInt(100000000), Exp: 0}, "100000000", []byte{0, 1, 0, 2, 0, 0, 0, 0, 0, 1}},
+	} {
+		buf, oid, format, err := tt.n.EncodeParam(nil)
+		require.NoError(t, err)
+		require.Equalf(t, tt.buf, buf, "%s", tt.s)
+		require.EqualValues(t, 1700, oid)
+		require.EqualValues(t, 1, format)
+
+		var n goldilocks.BigNumeric
+		err = n.DecodeResult(tt.buf)
+		require.NoError(t, err)
+		require.Equalf(t, tt.n.Exp, n.Exp, "%s", tt.s)
+		require.Equalf(t, 0, tt.n.Int.Cmp(n.Int), "%s", tt.s)
+		require.Equal(t, tt.s, n.String())
+	}
+}
+
+func TestBigNumeric(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	for _, s := range []string{
+		"0",
+		"0.000",
+		"1",
+		"-1",
+		"1.5",
+		"10000",
+		"123.45",
+		"0.00000000000000000000000000001",
+		"-123456789012345678901234567890.123456789012345678901234567890",
+		"100000000000000000000000000000000000000000",
+	} {
+		var n goldilocks.BigNumeric
+		var text string
+
+		_, err := db.Query(
+			context.Background(),
+			"select $1::text::numeric",
+			[]interface{}{s},
+			[]interface{}{&n},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equal(t, s, n.String())
+
+		_, err = db.Query(
+			context.Background(),
+			"select $1::numeric::text",
+			[]interface{}{n},
+			[]interface{}{&text},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equal(t, s, text)
+	}
+
+	var null goldilocks.NullBigNumeric
+	_, err = db.Query(
+		context.Background(),
+		"select $1::numeric",
+		[]interface{}{goldilocks.NullBigNumeric{}},
+		[]interface{}{&null},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.False(t, null.Valid)
+
+	var n goldilocks.BigNumeric
+	_, err = db.Query(context.Background(), "select 'NaN'::numeric", nil, []interface{}{&n}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "numeric"): NaN cannot be converted to BigNumeric`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestBigNumericConversions(t *testing.T) {
+	t.Parallel()
+
+	i, err := goldilocks.BigNumeric{Int: big.NewInt(1200), Exp: -2}.BigInt()
+	require.NoError(t, err)
+	require.Equal(t, "12", i.String())
+
+	i, err = goldilocks.BigNumeric{Int: big.NewInt(12), Exp: 3}.BigInt()
+	require.NoError(t, err)
+	require.Equal(t, "12000", i.String())
+
+	_, err = goldilocks.BigNumeric{Int: big.NewInt(1201), Exp: -2}.BigInt()
+	require.Error(t, err)
+
+	f := goldilocks.BigNumeric{Int: big.NewInt(125), Exp: -2}.BigFloat()
+	require.Equal(t, "1.25", f.Text('f', 2))
+}
+
+// testDecimal is a minimal decimal type in the style of third-party decimal packages.
+type testDecimal struct {
+	form        byte
+	negative    bool
+	coefficient *big.Int
+	exponent    int32
+}
+
+func (d *testDecimal) Decompose(buf []byte) (byte, bool, []byte, int32) {
+	return d.form, d.negative, d.coefficient.Bytes(), d.exponent
+}
+
+func (d *testDecimal) Compose(form byte, negative bool, coefficient []byte, exponent int32) error {
+	*d = testDecimal{form: form, negative: negative, coefficient: new(big.Int).SetBytes(coefficient), exponent: exponent}
+	return nil
+}
+
+func TestDecimalComposerAndDecomposer(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	for _, tt := range []struct {
+		s       string
+		decimal testDecimal
+	}{
+		{"0", testDecimal{coefficient: big.NewInt(0)}},
+		{"1.50", testDecimal{coefficient: big.NewInt(150), exponent: -2}},
+		{"-12345.678", testDecimal{negative: true, coefficient: big.NewInt(12345678), exponent: -3}},
+		{"NaN", testDecimal{form: 2, coefficient: big.NewInt(0)}},
+	} {
+		var text string
+		_, err := db.Query(
+			context.Background(),
+			"select $1::numeric::text",
+			[]interface{}{&tt.decimal},
+			[]interface{}{&text},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equal(t, tt.s, text)
+
+		var decimal testDecimal
+		_, err = db.Query(
+			context.Background(),
+			"select $1::text::numeric",
+			[]interface{}{tt.s},
+			[]interface{}{&decimal},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equalf(t, tt.decimal.form, decimal.form, "%s", tt.s)
+		require.Equalf(t, tt.decimal.negative, decimal.negative, "%s", tt.s)
+		require.Equalf(t, 0, tt.decimal.coefficient.Cmp(decimal.coefficient), "%s", tt.s)
+		require.Equalf(t, tt.decimal.exponent, decimal.exponent, "%s", tt.s)
+	}
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestBytea(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var b, empty, null []byte
+	var isNull bool
+
+	// An empty param is not NULL even when it is the first one encoded by the connection.
+	_, err = db.Query(context.Background(), "select $1::bytea, $1 is null", []interface{}{[]byte{}}, []interface{}{&empty, &isNull}, func() error { return nil })
+	require.NoError(t, err)
+	require.NotNil(t, empty)
+	require.Len(t, empty, 0)
+	require.False(t, isNull)
+
+	_, err = db.Query(
+		context.Background(),
+		"select $1::bytea, $2::bytea, $3::bytea, $3 is null",
+		[]interface{}{[]byte{0, 1, 2, 255}, []byte{}, []byte(nil)},
+		[]interface{}{&b, &empty, &null, &isNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 1, 2, 255}, b)
+	require.NotNil(t, empty)
+	require.Len(t, empty, 0)
+	require.Nil(t, null)
+	require.True(t, isNull)
+
+	var rows [][]byte
+	_, err = db.Query(
+		context.Background(),
+		"select decode(n::text, 'escape') from generate_series(1, 3) n",
+		nil,
+		[]interface{}{&b},
+		func() error {
+			rows = append(rows, b)
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("1"), []byte("2"), []byte("3")}, rows)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestJSONRawMessage(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var jsonb, jsonText, null json.RawMessage
+	var isNull bool
+	_, err = db.Query(
+		context.Background(),
+		"select $1::jsonb, '{\"b\":[1,2]}'::json, $2::jsonb, $2 is null",
+		[]interface{}{json.RawMessage(`{"a":"foo"}`), json.RawMessage(nil)},
+		[]interface{}{&jsonb, &jsonText, &null, &isNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, `{"a": "foo"}`, string(jsonb))
+	require.Equal(t, `{"b":[1,2]}`, string(jsonText))
+	require.Nil(t, null)
+	require.True(t, isNull)
+
+	rowsAffected, err := db.Exec(context.Background(), "create temporary table goldilocks (j json, jb jsonb)")
+	require.NoError(t, err)
+	require.EqualValues(t, 0, rowsAffected)
+
+	rowsAffected, err = db.Exec(context.Background(), "insert into goldilocks (j, jb) values($1, $1)", json.RawMessage(`[1, true, "x"]`))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, rowsAffected)
+
+	var j, jb json.RawMessage
+	_, err = db.Query(context.Background(), "select j, jb from goldilocks", nil, []interface{}{&j, &jb}, func() error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, `[1, true, "x"]`, string(j))
+	require.Equal(t, `[1, true, "x"]`, string(jb))
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestJSONRow(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	type widget struct {
+		ID    int32  `json:"id"`
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	}
+
+	var w widget
+	var widgets []widget
+	_, err = db.Query(
+		context.Background(),
+		"select row_to_json(t) from (values (1, 'a', 'red'), (2, 'b', null)) t(id, name, color)",
+		nil,
+		[]interface{}{goldilocks.JSONRow(&w)},
+		func() error {
+			widgets = append(widgets, w)
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []widget{{ID: 1, Name: "a", Color: "red"}, {ID: 2, Name: "b"}}, widgets)
+
+	_, err = db.Query(
+		context.Background(),
+		"select null::json",
+		nil,
+		[]interface{}{goldilocks.JSONRow(&w)},
+		func() error { return nil },
+	)
+	require.EqualError(t, err, `cannot decode results[0] (column "json"): NULL cannot be converted to *goldilocks_test.widget`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	type document struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	in := document{Name: "foo", Tags: []string{"a", "b"}}
+	var out, fromText document
+	var null *document
+	var isNull bool
+	_, err = db.Query(
+		context.Background(),
+		"select $1::jsonb, $1::jsonb->>'name', '{\"name\":\"bar\"}'::json, $2::jsonb, $2 is null",
+		[]interface{}{goldilocks.JSON(in), goldilocks.JSON(nil)},
+		[]interface{}{goldilocks.JSON(&out), new(string), goldilocks.JSON(&fromText), goldilocks.JSON(&null), &isNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, in, out)
+	require.Equal(t, document{Name: "bar"}, fromText)
+	require.Nil(t, null)
+	require.True(t, isNull)
+
+	_, err = db.Exec(context.Background(), "select $1::jsonb", goldilocks.JSON(make(chan int)))
+	require.Error(t, err)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestMoney(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	scale, err := goldilocks.LoadMoneyScale(context.Background(), db)
+	require.NoError(t, err)
+
+	for _, m := range []goldilocks.Money{0, 1, -1, 1234, math.MaxInt64, math.MinInt64} {
+		var result goldilocks.Money
+		var numeric goldilocks.BigNumeric
+		_, err := db.Query(
+			context.Background(),
+			"select $1, $1::numeric",
+			[]interface{}{m},
+			[]interface{}{&result, &numeric},
+			func() error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equal(t, m, result)
+		require.Equal(t, m.Numeric(scale).String(), numeric.String())
+	}
+
+	var null goldilocks.NullMoney
+	var notNull goldilocks.NullMoney
+	_, err = db.Query(
+		context.Background(),
+		"select $1::money, $2::money",
+		[]interface{}{goldilocks.NullMoney{}, goldilocks.NullMoney{Value: 42, Valid: true}},
+		[]interface{}{&null, &notNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.NullMoney{}, null)
+	require.Equal(t, goldilocks.NullMoney{Value: 42, Valid: true}, notNull)
+
+	var m goldilocks.Money
+	_, err = db.Query(context.Background(), "select null::money", nil, []interface{}{&m}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "money"): NULL cannot be converted to Money`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestOID(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var classOID, typeOID goldilocks.OID
+	var typeName string
+	_, err = db.Query(
+		context.Background(),
+		"select 'pg_class'::regclass, 'int4'::regtype, 'int4'::regtype",
+		nil,
+		[]interface{}{&classOID, &typeOID, &typeName},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 1259, classOID)
+	require.EqualValues(t, 23, typeOID)
+	require.Equal(t, "integer", typeName)
+
+	var relname string
+	var roundTrip goldilocks.OID
+	_, err = db.Query(
+		context.Background(),
+		"select relname::text, oid from pg_class where oid = $1",
+		[]interface{}{classOID},
+		[]interface{}{&relname, &roundTrip},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, "pg_class", relname)
+	require.Equal(t, classOID, roundTrip)
+
+	var null goldilocks.NullOID
+	var notNull goldilocks.NullOID
+	_, err = db.Query(
+		context.Background(),
+		"select $1::regtype, $2::regtype",
+		[]interface{}{goldilocks.NullOID{}, goldilocks.NullOID{Value: 25, Valid: true}},
+		[]interface{}{&null, &notNull},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.NullOID{}, null)
+	require.Equal(t, goldilocks.NullOID{Value: 25, Valid: true}, notNull)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestChar(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var relkind byte
+	_, err = db.Query(
+		context.Background(),
+		"select relkind from pg_class where oid = 'pg_class'::regclass",
+		nil,
+		[]interface{}{&relkind},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, byte('r'), relkind)
+
+	_, err = db.Query(context.Background(), `select null::"char"`, nil, []interface{}{&relkind}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "char"): NULL cannot be converted to byte`)
+
+	_, err = db.Query(context.Background(), "select 1::int2", nil, []interface{}{&relkind}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "int2"): "char" requires data length of 1, got 2`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestPointerParams(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	s := "foo"
+	i16 := int16(-1)
+	i32 := int32(42)
+	i64 := int64(1) << 40
+	f32 := float32(1.5)
+	f64 := float64(-2.25)
+	b := true
+	tm := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	sql := "select $1::text, $2::int2, $3::int4, $4::int8, $5::float4, $6::float8, $7::bool, $8::timestamptz"
+
+	var results [8]goldilocks.NullString
+	resultPtrs := make([]interface{}, len(results))
+	for i := range results {
+		resultPtrs[i] = &results[i]
+	}
+
+	// The text of a timestamptz depends on the session time zone so it is compared on the server.
+	args := []interface{}{&s, &i16, &i32, &i64, &f32, &f64, &b, &tm}
+	_, err = db.Query(context.Background(), "select $1::text, $2::int2::text, $3::int4::text, $4::int8::text, $5::float4::text, $6::float8::text, $7::bool::text, ($8::timestamptz = '2020-01-02 03:04:05Z')::text", args, resultPtrs, func() error { return nil })
+	require.NoError(t, err)
+	for i, expected := range []string{"foo", "-1", "42", "1099511627776", "1.5", "-2.25", "true", "true"} {
+		require.Equal(t, goldilocks.NullString{Value: expected, Valid: true}, results[i])
+	}
+
+	nilArgs := []interface{}{(*string)(nil), (*int16)(nil), (*int32)(nil), (*int64)(nil), (*float32)(nil), (*float64)(nil), (*bool)(nil), (*time.Time)(nil)}
+	_, err = db.Query(context.Background(), sql, nilArgs, resultPtrs, func() error { return nil })
+	require.NoError(t, err)
+	for i := range results {
+		require.Equal(t, goldilocks.NullString{}, results[i])
+	}
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestPointerResults(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var s *string
+	var i32 *int32
+	var f64 *float64
+	var b *bool
+	var tm *time.Time
+	var interval *goldilocks.Interval
+	results := []interface{}{&s, &i32, &f64, &b, &tm, &interval}
+
+	_, err = db.Query(
+		context.Background(),
+		"select 'foo'::text, 42::int4, 1.5::float8, true, '2020-01-02 03:04:05Z'::timestamptz, '1 day'::interval",
+		nil,
+		results,
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, "foo", *s)
+	require.EqualValues(t, 42, *i32)
+	require.Equal(t, 1.5, *f64)
+	require.True(t, *b)
+	require.True(t, tm.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+	require.Equal(t, goldilocks.Interval{Days: 1}, *interval)
+
+	_, err = db.Query(
+		context.Background(),
+		"select null::text, null::int4, null::float8, null::bool, null::timestamptz, null::interval",
+		nil,
+		results,
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Nil(t, s)
+	require.Nil(t, i32)
+	require.Nil(t, f64)
+	require.Nil(t, b)
+	require.Nil(t, tm)
+	require.Nil(t, interval)
+
+	var unsupported *struct{}
+	_, err = db.Query(context.Background(), "select 1", nil, []interface{}{&unsupported}, func() error { return nil })
+	require.Error(t, err)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestPointerResultsTextAndResolvers(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+
+	for i, config := range []goldilocks.ConnConfig{{TextResults: true}, {SimpleProtocol: true}} {
+		db := goldilocks.NewConnConfig(pgConn, config)
+
+		var i32, null *int32
+		var f64 *float64
+		_, err = db.Query(
+			context.Background(),
+			"select 42::int4, 1.5::float8, null::int4",
+			nil,
+			[]interface{}{&i32, &f64, &null},
+			func() error { return nil },
+		)
+		require.NoErrorf(t, err, "%d", i)
+		require.EqualValuesf(t, 42, *i32, "%d", i)
+		require.Equalf(t, 1.5, *f64, "%d", i)
+		require.Nilf(t, null, "%d", i)
+	}
+
+	var resolved bool
+	db := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{
+		ResultResolvers: []goldilocks.ResultResolver{
+			func(dst interface{}) (goldilocks.ResultDecoder, bool) {
+				if n, ok := dst.(*int); ok {
+					resolved = true
+					return &intResult{dst: n}, true
+				}
+				return nil, false
+			},
+		},
+	})
+
+	var n *int
+	_, err = db.Query(context.Background(), "select 42::int8", nil, []interface{}{&n}, func() error { return nil })
+	require.NoError(t, err)
+	require.True(t, resolved)
+	require.Equal(t, 42, *n)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestIntAndUint(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var i16, i32, i64 int
+	var u16, u32, u64 uint
+	var typeName string
+	_, err = db.Query(
+		context.Background(),
+		"select -1::int2, $1::int4, $1, 1::int2, $2::int4, $2, pg_typeof($1)::text",
+		[]interface{}{int(-42), uint(42)},
+		[]interface{}{&i16, &i32, &i64, &u16, &u32, &u64, &typeName},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, -1, i16)
+	require.Equal(t, -42, i32)
+	require.Equal(t, -42, i64)
+	require.EqualValues(t, 1, u16)
+	require.EqualValues(t, 42, u32)
+	require.EqualValues(t, 42, u64)
+	require.Equal(t, "bigint", typeName)
+
+	_, err = db.Exec(context.Background(), "select $1", uint(math.MaxInt64)+1)
+	require.EqualError(t, err, "cannot encode args[0] (uint as type oid 20): 9223372036854775808 is greater than maximum value for int8")
+
+	_, err = db.Query(context.Background(), "select -1::int4", nil, []interface{}{&u32}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "?column?"): -1 is out of range for uint`)
+
+	_, err = db.Query(context.Background(), "select null::int4", nil, []interface{}{&i32}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "int4"): NULL cannot be converted to int`)
+
+	_, err = db.Query(context.Background(), "select 'abc'::text", nil, []interface{}{&i32}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "text"): int requires data length of 2, 4, or 8, got 3`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestUint64(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var n, small uint64
+	_, err = db.Query(
+		context.Background(),
+		"select $1, 7::int4",
+		[]interface{}{uint64(math.MaxInt64)},
+		[]interface{}{&n, &small},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, uint64(math.MaxInt64), n)
+	require.EqualValues(t, 7, small)
+
+	_, err = db.Exec(context.Background(), "select $1", uint64(math.MaxUint64))
+	require.EqualError(t, err, "cannot encode args[0] (uint64 as type oid 20): 18446744073709551615 is greater than maximum value for int8")
+
+	_, err = db.Query(context.Background(), "select -1::int8", nil, []interface{}{&n}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "?column?"): -1 is out of range for uint64`)
+
+	// The text format can decode values greater than the maximum value of an int8.
+	textDB := goldilocks.NewConnConfig(pgConn, goldilocks.ConnConfig{TextResults: true})
+	_, err = textDB.Query(context.Background(), "select 18446744073709551615::numeric", nil, []interface{}{&n}, func() error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, uint64(math.MaxUint64), n)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestBigInt(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	huge, ok := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	require.True(t, ok)
+
+	var fromInt8, fromNumeric, fromScaledNumeric, roundTrip big.Int
+	var null *big.Int
+	var typeName string
+	_, err = db.Query(
+		context.Background(),
+		"select 9223372036854775807::int8, 123456789012345678901234567890::numeric, 42.000::numeric, $1::numeric, $2::numeric, pg_typeof($1)::text",
+		[]interface{}{huge, (*big.Int)(nil)},
+		[]interface{}{&fromInt8, &fromNumeric, &fromScaledNumeric, &roundTrip, &null, &typeName},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, "9223372036854775807", fromInt8.String())
+	require.Equal(t, "123456789012345678901234567890", fromNumeric.String())
+	require.Equal(t, "42", fromScaledNumeric.String())
+	require.Equal(t, huge.String(), roundTrip.String())
+	require.Nil(t, null)
+	require.Equal(t, "numeric", typeName)
+
+	_, err = db.Query(context.Background(), "select 1.5::numeric", nil, []interface{}{&roundTrip}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "numeric"): 1.5 cannot be converted to big.Int without loss of precision`)
+
+	_, err = db.Query(context.Background(), "select 'NaN'::numeric", nil, []interface{}{&roundTrip}, func() error { return nil })
+	require.EqualError(t, err, `cannot decode results[0] (column "numeric"): invalid big.Int: "NaN"`)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestStringResultOfAnyType(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var tsvector, pgLsn string
+	var txidSnapshot, null goldilocks.NullString
+	_, err = db.Query(
+		context.Background(),
+		"select 'a fat cat'::tsvector, '16/B374D848'::pg_lsn, '10:20:10,14,15'::txid_snapshot, null::tsquery",
+		nil,
+		[]interface{}{&tsvector, &pgLsn, &txidSnapshot, &null},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, "'a' 'cat' 'fat'", tsvector)
+	require.Equal(t, "16/B374D848", pgLsn)
+	require.Equal(t, goldilocks.NullString{Value: "10:20:10,14,15", Valid: true}, txidSnapshot)
+	require.Equal(t, goldilocks.NullString{}, null)
+
+	ensurePgConnValid(t, pgConn)
+}
@@ -0,0 +1,96 @@
+package goldilocks
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgio"
+)
+
+// maxVectorDimensions is the maximum number of dimensions of a pgvector vector.
+const maxVectorDimensions = 16000
+
+// Vector is a vector of the pgvector extension. A nil Vector is NULL.
+//
+// The oid of the vector type is assigned when the extension is created so a Vector is sent without a type. The server
+// infers it from the query as it does for a $1::vector param or a param inserted into a vector column. Where the type
+// cannot be inferred, load it into a TypeMap and send the Vector with TypeMap.Param("vector", v).
+type Vector []float32
+
+func (v Vector) EncodeParam(buf []byte) ([]byte, uint32, int16, error) {
+	if v == nil {
+		return nil, 0, binaryFormat, nil
+	}
+	if len(v) > maxVectorDimensions {
+		return nil, 0, 0, fmt.Errorf("Vector cannot have more than %d dimensions, got %d", maxVectorDimensions, len(v))
+	}
+
+	buf = pgio.AppendUint16(buf, uint16(len(v)))
+	buf = pgio.AppendUint16(buf, 0)
+	for _, f := range v {
+		buf = pgio.AppendUint32(buf, math.Float32bits(f))
+	}
+
+	return buf, 0, binaryFormat, nil
+}
+
+func (*Vector) ResultFormat() int16 {
+	return binaryFormat
+}
+
+func (v *Vector) DecodeResult(buf []byte) error {
+	if buf == nil {
+		*v = nil
+		return nil
+	}
+
+	if len(buf) < 4 {
+		return fmt.Errorf("Vector requires data length of at least 4, got %d", len(buf))
+	}
+	n := int(binary.BigEndian.Uint16(buf))
+	buf = buf[4:]
+	if len(buf) != n*4 {
+		return fmt.Errorf("Vector of %d dimensions requires data length of %d, got %d", n, n*4, len(buf))
+	}
+
+	dst := make(Vector, n)
+	for i := range dst {
+		dst[i] = math.Float32frombits(binary.BigEndian.Uint32(buf[i*4:]))
+	}
+
+	*v = dst
+	return nil
+}
+
+// DecodeTextResult decodes the text format of a vector such as [1,2.5,3].
+func (v *Vector) DecodeTextResult(buf []byte) error {
+	if buf == nil {
+		*v = nil
+		return nil
+	}
+
+	s := string(buf)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return fmt.Errorf("invalid vector: %q", s)
+	}
+	s = s[1 : len(s)-1]
+
+	dst := Vector{}
+	if s != "" {
+		parts := strings.Split(s, ",")
+		dst = make(Vector, len(parts))
+		for i, p := range parts {
+			f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+			if err != nil {
+				return fmt.Errorf("invalid vector: %q", buf)
+			}
+			dst[i] = float32(f)
+		}
+	}
+
+	*v = dst
+	return nil
+}
@@ -0,0 +1,96 @@
+package goldilocks_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/goldilocks"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVector(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("GOLDILOCKS_TEST_CONN_STRING"))
+	require.NoError(t, err)
+	defer closePgConn(t, pgConn)
+	db := goldilocks.NewConn(pgConn)
+
+	var installed bool
+	_, err = db.Query(
+		context.Background(),
+		"select exists(select 1 from pg_extension where extname = 'vector')",
+		nil,
+		[]interface{}{&installed},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	if !installed {
+		t.Skip("vector extension is not installed")
+	}
+
+	typeMap := goldilocks.NewTypeMap()
+	err = typeMap.LoadTypes(context.Background(), db, "vector")
+	require.NoError(t, err)
+
+	v := goldilocks.Vector{1, -2.5, 3}
+	var inferred, typed, null goldilocks.Vector
+	var text string
+	var distance float64
+	_, err = db.Query(
+		context.Background(),
+		"select $1::vector, $2, $3::vector, $1::vector::text, ($1::vector <-> '[1,-2.5,7]')::float8",
+		[]interface{}{v, typeMap.Param("vector", v), goldilocks.Vector(nil)},
+		[]interface{}{&inferred, &typed, &null, &text, &distance},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, v, inferred)
+	require.Equal(t, v, typed)
+	require.Nil(t, null)
+	require.Equal(t, "[1,-2.5,3]", text)
+	require.Equal(t, 4.0, distance)
+
+	var fromText goldilocks.Vector
+	_, err = db.Query(
+		context.Background(),
+		"select '[0.5,2]'::vector",
+		nil,
+		[]interface{}{goldilocks.TextResult(&fromText)},
+		func() error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, goldilocks.Vector{0.5, 2}, fromText)
+
+	ensurePgConnValid(t, pgConn)
+}
+
+func TestVectorDecodeTextResult(t *testing.T) {
+	t.Parallel()
+
+	var v goldilocks.Vector
+	require.NoError(t, v.DecodeTextResult([]byte("[1, 2.5,-3]")))
+	require.Equal(t, goldilocks.Vector{1, 2.5, -3}, v)
+
+	require.NoError(t, v.DecodeTextResult([]byte("[]")))
+	require.Equal(t, goldilocks.Vector{}, v)
+
+	require.NoError(t, v.DecodeTextResult(nil))
+	require.Nil(t, v)
+
+	require.EqualError(t, v.DecodeTextResult([]byte("1,2")), `invalid vector: "1,2"`)
+	require.EqualError(t, v.DecodeTextResult([]byte("[1,x]")), `invalid vector: "[1,x]"`)
+}
+
+func TestVectorDecodeResultInvalid(t *testing.T) {
+	t.Parallel()
+
+	var v goldilocks.Vector
+	require.EqualError(t, v.DecodeResult([]byte{0, 1}), "Vector requires data length of at least 4, got 2")
+	require.EqualError(t, v.DecodeResult([]byte{0, 2, 0, 0, 0, 0, 0, 0}), "Vector of 2 dimensions requires data length of 8, got 4")
+
+	_, _, _, err := make(goldilocks.Vector, 16001).EncodeParam(nil)
+	require.EqualError(t, err, "Vector cannot have more than 16000 dimensions, got 16001")
+}